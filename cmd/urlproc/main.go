@@ -0,0 +1,183 @@
+// Command urlproc runs URL lists through the URL processing operations of the
+// API, for crawler output and other pipelines.
+//
+//	urlproc [flags] [file ...]
+//
+// URLs are read from the files, or from stdin when there are none or a file
+// is "-", as one URL per line or from a CSV column. Results are written to
+// stdout as TSV, CSV or NDJSON in input order. Invalid URLs are reported in
+// the error column; with -strict they also make urlproc exit with status 1.
+// Configuration such as URL_RULES_FILE and URL_ALLOWED_HOSTS is read from the
+// environment and, like the server does, from .env in the working directory
+// when there is one.
+package main
+
+import (
+	"bufio"
+	"byfood-test-backend/config"
+	"byfood-test-backend/services"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	exitInvalidURLs = 1
+	exitFailure     = 2
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "urlproc: loading .env:", err)
+		os.Exit(exitFailure)
+	}
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("urlproc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	operations := flags.String("operations", "canonical", "comma separated operations or rule sets to apply in order")
+	rulesFile := flags.String("rules", config.URLRulesFile(), "JSON or YAML rules file, the rule sets built in from config/url_rules.yaml when empty")
+	input := flags.String("input", "auto", "input format: text, csv, or auto (csv for .csv files)")
+	column := flags.String("column", "url", "CSV column holding the URLs")
+	format := flags.String("format", "tsv", "output format: tsv, csv or ndjson")
+	noHeader := flags.Bool("no-header", false, "leave out the header line of tsv and csv output")
+	workers := flags.Int("workers", runtime.NumCPU(), "URLs processed at the same time")
+	strict := flags.Bool("strict", false, "exit with status 1 when any URL is invalid")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: urlproc [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitFailure
+	}
+
+	if *input != "auto" && *input != "text" && *input != "csv" {
+		fmt.Fprintf(stderr, "urlproc: unknown input format %q\n", *input)
+		return exitFailure
+	}
+	output := bufio.NewWriter(stdout)
+	writer, err := newResultWriter(*format, output, !*noHeader)
+	if err != nil {
+		fmt.Fprintln(stderr, "urlproc:", err)
+		return exitFailure
+	}
+
+	services.URLRules.SetPath(*rulesFile)
+	if err := services.URLRules.Reload(); err != nil {
+		fmt.Fprintln(stderr, "urlproc: loading rules:", err)
+		return exitFailure
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	source := &fileSource{files: files, stdin: stdin, input: *input, column: *column}
+	defer source.Close()
+
+	invalid := 0
+	err = services.ProcessURLStream(source.Next, splitList(*operations), *workers, func(result services.URLBatchResult) error {
+		if result.Error != "" {
+			invalid++
+		}
+		return writer.Write(result)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = output.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "urlproc:", err)
+		return exitFailure
+	}
+
+	if *strict && invalid > 0 {
+		fmt.Fprintf(stderr, "urlproc: %d invalid URLs\n", invalid)
+		return exitInvalidURLs
+	}
+	return 0
+}
+
+// fileSource reads the URLs of several files one after the other.
+type fileSource struct {
+	files  []string
+	stdin  io.Reader
+	input  string
+	column string
+
+	current services.URLSource
+	closer  io.Closer
+}
+
+func (s *fileSource) Next() (string, error) {
+	for {
+		if s.current == nil {
+			if len(s.files) == 0 {
+				return "", io.EOF
+			}
+			if err := s.open(s.files[0]); err != nil {
+				return "", err
+			}
+			s.files = s.files[1:]
+		}
+
+		rawURL, err := s.current()
+		if err != io.EOF {
+			return rawURL, err
+		}
+		s.Close()
+	}
+}
+
+func (s *fileSource) open(name string) error {
+	var r io.Reader = s.stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		r, s.closer = file, file
+	}
+
+	csvFormat := s.input == "csv" || s.input == "auto" && strings.HasSuffix(strings.ToLower(name), ".csv")
+	source, err := services.NewURLSource(r, csvFormat, s.column)
+	if err != nil {
+		s.Close()
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	s.current = source
+	return nil
+}
+
+// Close closes the file being read.
+func (s *fileSource) Close() error {
+	s.current = nil
+	if s.closer == nil {
+		return nil
+	}
+	err := s.closer.Close()
+	s.closer = nil
+	return err
+}
+
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
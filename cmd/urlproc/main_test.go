@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runURLProc(t *testing.T, stdin string, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"-rules", ""}, args...), strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), code
+}
+
+func TestRunKeepsInputOrder(t *testing.T) {
+	var input, expected strings.Builder
+	expected.WriteString("url\tprocessed_url\terror\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&input, "https://www.byfood.com/books/%d/?utm_source=x\n", i)
+		fmt.Fprintf(&expected, "https://www.byfood.com/books/%d/?utm_source=x\thttps://www.byfood.com/books/%d\t\n", i, i)
+	}
+
+	output, code := runURLProc(t, input.String(), "-workers", "8")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, expected.String(), output)
+}
+
+func TestRunStrict(t *testing.T) {
+	input := "https://www.byfood.com/a/\nftp://www.byfood.com/b\n"
+
+	output, code := runURLProc(t, input)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, output, "ftp://www.byfood.com/b\t\t")
+
+	_, code = runURLProc(t, input, "-strict")
+	assert.Equal(t, exitInvalidURLs, code)
+
+	_, code = runURLProc(t, "https://www.byfood.com/a/\n", "-strict")
+	assert.Equal(t, 0, code)
+}
+
+func TestRunOutputFormats(t *testing.T) {
+	input := "https://www.byfood.com/a/?ref=home\n"
+
+	output, code := runURLProc(t, input, "-format", "tsv", "-no-header")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "https://www.byfood.com/a/?ref=home\thttps://www.byfood.com/a\t\n", output)
+
+	output, code = runURLProc(t, input, "-format", "csv")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "url,processed_url,error\nhttps://www.byfood.com/a/?ref=home,https://www.byfood.com/a,\n", output)
+
+	output, code = runURLProc(t, input, "-format", "ndjson")
+	assert.Equal(t, 0, code)
+	var result map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(output), &result))
+	assert.Equal(t, "https://www.byfood.com/a", result["processed_url"])
+	assert.Equal(t, float64(0), result["index"])
+
+	_, code = runURLProc(t, input, "-format", "xml")
+	assert.Equal(t, exitFailure, code)
+}
+
+func TestRunBuiltInRulesMatchRulesFile(t *testing.T) {
+	input := "https://www.byfood.com/books?ref=home&page=2&utm_source=x\nhttps://blog.byfood.com/post?p=1&page=2\n"
+
+	output, code := runURLProc(t, input, "-operations", "clean", "-no-header")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "https://www.byfood.com/books?ref=home&page=2&utm_source=x\thttps://www.byfood.com/books?page=2\t\n"+
+		"https://blog.byfood.com/post?p=1&page=2\thttps://blog.byfood.com/post?p=1\t\n", output)
+
+	output, code = runURLProc(t, "https://byfood.com/Experiences/tokyo/?page=2&utm_source=x\n", "-operations", "seo", "-no-header")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "https://byfood.com/Experiences/tokyo/?page=2&utm_source=x\thttps://www.byfood.com/food-experiences/tokyo?page=2\t\n", output)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"byfood-test-backend/services"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+var errUnknownFormat = errors.New("output format must be tsv, csv or ndjson")
+
+// resultWriter writes processing results in one output format.
+type resultWriter interface {
+	Write(result services.URLBatchResult) error
+	Flush() error
+}
+
+var outputHeader = []string{"url", "processed_url", "error"}
+
+// newResultWriter returns a writer for format. TSV and CSV output starts with
+// a header line when header is set.
+func newResultWriter(format string, w io.Writer, header bool) (resultWriter, error) {
+	switch format {
+	case "tsv":
+		writer := &tsvWriter{w: w}
+		if header {
+			return writer, writer.writeRow(outputHeader)
+		}
+		return writer, nil
+	case "csv":
+		writer := &csvWriter{w: csv.NewWriter(w)}
+		if header {
+			return writer, writer.w.Write(outputHeader)
+		}
+		return writer, nil
+	case "ndjson":
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, errUnknownFormat
+}
+
+func resultRow(result services.URLBatchResult) []string {
+	return []string{result.URL, result.ProcessedURL, result.Error}
+}
+
+// tsvWriter writes tab separated lines. Tabs and line breaks inside a field
+// are replaced by spaces.
+type tsvWriter struct {
+	w io.Writer
+}
+
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+func (t *tsvWriter) Write(result services.URLBatchResult) error {
+	return t.writeRow(resultRow(result))
+}
+
+func (t *tsvWriter) writeRow(row []string) error {
+	fields := make([]string, len(row))
+	for i, field := range row {
+		fields[i] = tsvEscaper.Replace(field)
+	}
+	_, err := io.WriteString(t.w, strings.Join(fields, "\t")+"\n")
+	return err
+}
+
+func (t *tsvWriter) Flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(result services.URLBatchResult) error {
+	return c.w.Write(resultRow(result))
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter writes every result as a JSON object on its own line.
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(result services.URLBatchResult) error {
+	return n.encoder.Encode(result)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
@@ -11,6 +11,23 @@ import (
 
 var DB *gorm.DB
 
+var migratedModels = []interface{}{
+	&models.Book{},
+	&models.BookSlug{},
+	&models.Branch{},
+	&models.Copy{},
+	&models.Transfer{},
+	&models.User{},
+	&models.Loan{},
+	&models.Hold{},
+	&models.FineEntry{},
+	&models.RedirectRule{},
+	&models.LinkCheckJob{},
+	&models.LinkCheckResult{},
+	&models.ShortLink{},
+	&models.URLRecord{},
+}
+
 func ConnectToDB() {
 	var err error
 	dsn := os.Getenv("DB_URL")
@@ -23,8 +40,33 @@ func ConnectToDB() {
 
 func MigrateDatabase() {
 
-	DB.AutoMigrate(&models.Book{})
+	DB.AutoMigrate(migratedModels...)
+	migrateBookTotalCopies(DB)
+
+}
+
+// migrateBookTotalCopies replaces the old books.total_copies column, which
+// copies now derive from, with that many copies for books that have none yet
+// and then drops it.
+func migrateBookTotalCopies(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&models.Book{}, "total_copies") {
+		return
+	}
 
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO copies (created_at, updated_at, book_id, barcode, condition, status)
+			SELECT now(), now(), b.id, 'LEGACY-' || b.id || '-' || n, ?, ?
+			FROM books b CROSS JOIN LATERAL generate_series(1, b.total_copies) AS n
+			WHERE NOT EXISTS (SELECT 1 FROM copies c WHERE c.book_id = b.id)`,
+			models.CopyConditionGood, models.CopyStatusAvailable).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&models.Book{}, "total_copies")
+	})
+	if err != nil {
+		log.Fatal("Failed to migrate books.total_copies", err)
+	}
 }
 
 func SetupTestDB() {
@@ -39,5 +81,6 @@ func SetupTestDB() {
 		log.Fatal("Failed to connect to database", err)
 	}
 
-	DB.AutoMigrate(&models.Book{})
+	DB.AutoMigrate(migratedModels...)
+	migrateBookTotalCopies(DB)
 }
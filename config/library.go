@@ -0,0 +1,112 @@
+package config
+
+import (
+	"byfood-test-backend/models"
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultLoanPeriodDays    = 14
+	defaultMaxRenewals       = 2
+	defaultHoldPickupDays    = 3
+	defaultHoldExpiryMinutes = 15
+	defaultFineDailyRate     = 25
+	defaultFineGraceDays     = 1
+	defaultFineMaxAmount     = 1000
+	defaultFineBlockBalance  = 500
+)
+
+// FineRules describes how overdue loans are charged. Amounts are in minor
+// currency units; a MaxAmount of 0 means the fine is not capped.
+type FineRules struct {
+	DailyRate int64 `json:"daily_rate"`
+	GraceDays int   `json:"grace_days"`
+	MaxAmount int64 `json:"max_amount"`
+}
+
+// LoanPeriod returns how long a checkout lasts, configured through LOAN_PERIOD_DAYS.
+func LoanPeriod() time.Duration {
+	return time.Duration(getEnvInt("LOAN_PERIOD_DAYS", defaultLoanPeriodDays)) * 24 * time.Hour
+}
+
+// MaxLoanRenewals returns how many times a loan may be renewed, configured through LOAN_MAX_RENEWALS.
+func MaxLoanRenewals() int {
+	return getEnvInt("LOAN_MAX_RENEWALS", defaultMaxRenewals)
+}
+
+// HoldPickupPeriod returns how long a copy set aside for a hold waits for its
+// borrower, configured through HOLD_PICKUP_DAYS.
+func HoldPickupPeriod() time.Duration {
+	return time.Duration(getEnvInt("HOLD_PICKUP_DAYS", defaultHoldPickupDays)) * 24 * time.Hour
+}
+
+// HoldExpiryInterval returns how often expired holds are swept, configured
+// through HOLD_EXPIRY_INTERVAL_MINUTES.
+func HoldExpiryInterval() time.Duration {
+	return time.Duration(getEnvInt("HOLD_EXPIRY_INTERVAL_MINUTES", defaultHoldExpiryMinutes)) * time.Minute
+}
+
+// FineRulesFor returns the fine rules for a membership tier. The defaults come
+// from FINE_DAILY_RATE, FINE_GRACE_DAYS and FINE_MAX_AMOUNT, and
+// FINE_TIER_OVERRIDES may replace any of them per tier, e.g.
+// {"premium": {"daily_rate": 10, "grace_days": 3}}.
+func FineRulesFor(tier string) FineRules {
+	rules := FineRules{
+		DailyRate: int64(getEnvInt("FINE_DAILY_RATE", defaultFineDailyRate)),
+		GraceDays: getEnvInt("FINE_GRACE_DAYS", defaultFineGraceDays),
+		MaxAmount: int64(getEnvInt("FINE_MAX_AMOUNT", defaultFineMaxAmount)),
+	}
+
+	override, ok := fineTierOverrides()[tier]
+	if !ok {
+		return rules
+	}
+
+	// Decoding into the defaults keeps every field the override leaves out.
+	if err := json.Unmarshal(override, &rules); err != nil {
+		Log.WithError(err).Error("Invalid FINE_TIER_OVERRIDES")
+	}
+	return rules
+}
+
+// ValidMembershipTier reports whether tier is the standard tier or one
+// configured in FINE_TIER_OVERRIDES.
+func ValidMembershipTier(tier string) bool {
+	if tier == models.MembershipTierStandard {
+		return true
+	}
+	_, ok := fineTierOverrides()[tier]
+	return ok
+}
+
+// fineTierOverrides decodes FINE_TIER_OVERRIDES into the raw rules per tier.
+func fineTierOverrides() map[string]json.RawMessage {
+	raw := os.Getenv("FINE_TIER_OVERRIDES")
+	if raw == "" {
+		return nil
+	}
+
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		Log.WithError(err).Error("Invalid FINE_TIER_OVERRIDES")
+		return nil
+	}
+	return overrides
+}
+
+// FineBlockThreshold returns the outstanding fine balance above which users
+// cannot check out books, configured through FINE_BLOCK_THRESHOLD.
+func FineBlockThreshold() int64 {
+	return int64(getEnvInt("FINE_BLOCK_THRESHOLD", defaultFineBlockBalance))
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
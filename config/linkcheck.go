@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// LinkCheckConcurrency returns how many URLs the link checker fetches at the
+// same time, configured through LINKCHECK_CONCURRENCY.
+func LinkCheckConcurrency() int {
+	return getEnvInt("LINKCHECK_CONCURRENCY", 8)
+}
+
+// LinkCheckHostDelay returns the pause between two requests to the same
+// host, configured in milliseconds through LINKCHECK_HOST_DELAY_MS. Requests
+// to one host are never made in parallel.
+func LinkCheckHostDelay() time.Duration {
+	return time.Duration(getEnvInt("LINKCHECK_HOST_DELAY_MS", 500)) * time.Millisecond
+}
+
+// LinkCheckTimeout returns how long one request may take, configured through
+// LINKCHECK_TIMEOUT_SECONDS.
+func LinkCheckTimeout() time.Duration {
+	return time.Duration(getEnvInt("LINKCHECK_TIMEOUT_SECONDS", 10)) * time.Second
+}
+
+// LinkCheckMaxRedirects returns how many redirects are followed from a URL,
+// configured through LINKCHECK_MAX_REDIRECTS.
+func LinkCheckMaxRedirects() int {
+	return getEnvInt("LINKCHECK_MAX_REDIRECTS", 5)
+}
+
+// LinkCheckMaxURLs returns how many URLs one job may check, including the
+// links found on crawled pages, configured through LINKCHECK_MAX_URLS.
+func LinkCheckMaxURLs() int {
+	return getEnvInt("LINKCHECK_MAX_URLS", 1000)
+}
+
+// LinkCheckJobTimeout returns how long one job may run before it is stopped,
+// configured through LINKCHECK_JOB_TIMEOUT_MINUTES.
+func LinkCheckJobTimeout() time.Duration {
+	return time.Duration(getEnvInt("LINKCHECK_JOB_TIMEOUT_MINUTES", 30)) * time.Minute
+}
+
+// LinkCheckUserAgent returns the User-Agent the link checker sends,
+// configured through LINKCHECK_USER_AGENT.
+func LinkCheckUserAgent() string {
+	if agent := os.Getenv("LINKCHECK_USER_AGENT"); agent != "" {
+		return agent
+	}
+	return "byfood-linkcheck/1.0"
+}
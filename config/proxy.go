@@ -0,0 +1,8 @@
+package config
+
+// TrustedProxies returns the addresses or CIDR ranges of the reverse proxies
+// whose X-Forwarded-* headers are trusted, configured as a comma separated
+// list through TRUSTED_PROXIES. Empty trusts no proxy.
+func TrustedProxies() []string {
+	return getEnvList("TRUSTED_PROXIES", "")
+}
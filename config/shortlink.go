@@ -0,0 +1,42 @@
+package config
+
+import (
+	"net/url"
+	"os"
+)
+
+// ShortLinkURLOperations returns the URL operations that canonicalize the
+// target of a short link, configured through SHORTLINK_URL_OPERATIONS.
+func ShortLinkURLOperations() []string {
+	return getEnvList("SHORTLINK_URL_OPERATIONS", "normalize,canonical")
+}
+
+// ShortLinkCodeLength returns the length of generated short codes,
+// configured through SHORTLINK_CODE_LENGTH.
+func ShortLinkCodeLength() int {
+	if length := getEnvInt("SHORTLINK_CODE_LENGTH", 7); length >= 4 && length <= 32 {
+		return length
+	}
+	return 7
+}
+
+// ShortLinkBaseURL returns the public URL short links are served from,
+// configured through SHORTLINK_BASE_URL. When empty the URL of the request is
+// used.
+func ShortLinkBaseURL() string {
+	return os.Getenv("SHORTLINK_BASE_URL")
+}
+
+// ShortLinkAllowedHosts returns the hosts short links may point at,
+// configured as a comma separated list through SHORTLINK_ALLOWED_HOSTS. It
+// defaults to the host of SHORTLINK_BASE_URL, or URL_FORCE_HOST when that is
+// empty, so short links cannot send visitors to other sites.
+func ShortLinkAllowedHosts() []string {
+	if hosts := getEnvList("SHORTLINK_ALLOWED_HOSTS", ""); len(hosts) > 0 {
+		return hosts
+	}
+	if base, err := url.Parse(ShortLinkBaseURL()); err == nil && base.Hostname() != "" {
+		return []string{base.Hostname()}
+	}
+	return []string{URLForceHost()}
+}
@@ -0,0 +1,46 @@
+package config
+
+import "os"
+
+// SitemapBookURLTemplate returns the public URL of a book page, with {slug}
+// replaced by the book slug and {id} by the book ID, configured through
+// SITEMAP_BOOK_URL_TEMPLATE.
+func SitemapBookURLTemplate() string {
+	if template := os.Getenv("SITEMAP_BOOK_URL_TEMPLATE"); template != "" {
+		return template
+	}
+	return "https://www.byfood.com/books/{slug}"
+}
+
+// SitemapURLOperations returns the URL operations that canonicalize the book
+// URLs listed in the sitemap, configured through SITEMAP_URL_OPERATIONS.
+func SitemapURLOperations() []string {
+	return getEnvList("SITEMAP_URL_OPERATIONS", "normalize,canonical")
+}
+
+// SitemapMaxURLs returns how many URLs one sitemap file lists before the
+// sitemap is split behind a sitemap index, configured through
+// SITEMAP_MAX_URLS. The sitemaps.org protocol allows at most 50000.
+func SitemapMaxURLs() int {
+	if max := getEnvInt("SITEMAP_MAX_URLS", 50000); max > 0 && max <= 50000 {
+		return max
+	}
+	return 50000
+}
+
+// SitemapBaseURL returns the public URL this API is served from, used for the
+// sitemap files listed in a sitemap index, configured through
+// SITEMAP_BASE_URL. When empty the URL of the request is used.
+func SitemapBaseURL() string {
+	return os.Getenv("SITEMAP_BASE_URL")
+}
+
+// SitemapAuditMaxBytes returns the largest sitemap, once decompressed, the
+// sitemap audit accepts, configured through SITEMAP_AUDIT_MAX_BYTES. The
+// sitemaps.org protocol allows at most 50MB.
+func SitemapAuditMaxBytes() int64 {
+	if max := getEnvInt("SITEMAP_AUDIT_MAX_BYTES", 50<<20); max > 0 {
+		return int64(max)
+	}
+	return 50 << 20
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	_ "embed"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultURLRules holds the rule sets of url_rules.yaml, used when no rules
+// file is configured.
+//
+//go:embed url_rules.yaml
+var DefaultURLRules []byte
+
+// URLRulesFile returns the JSON or YAML file holding the URL rewriting rule
+// sets, configured through URL_RULES_FILE. When empty the rule sets built in
+// from url_rules.yaml are used.
+func URLRulesFile() string {
+	return os.Getenv("URL_RULES_FILE")
+}
+
+// URLRulesReloadInterval returns how often the rules file is checked for
+// changes, configured through URL_RULES_RELOAD_SECONDS. Zero disables the
+// automatic reload.
+func URLRulesReloadInterval() time.Duration {
+	return time.Duration(getEnvInt("URL_RULES_RELOAD_SECONDS", 0)) * time.Second
+}
+
+// URLRulesReloadToken returns the bearer token callers of the rules reload
+// endpoint must send, configured through URL_RULES_RELOAD_TOKEN. Empty
+// disables the endpoint.
+func URLRulesReloadToken() string {
+	return os.Getenv("URL_RULES_RELOAD_TOKEN")
+}
+
+// URLForceHost returns the host the force_host URL operation rewrites every
+// URL to, configured through URL_FORCE_HOST.
+func URLForceHost() string {
+	if host := os.Getenv("URL_FORCE_HOST"); host != "" {
+		return host
+	}
+	return "www.byfood.com"
+}
+
+// URLBatchMaxURLs returns how many URLs one batch request may hold,
+// configured through URL_BATCH_MAX_URLS.
+func URLBatchMaxURLs() int {
+	return getEnvInt("URL_BATCH_MAX_URLS", 1000)
+}
+
+// URLBatchWorkers returns how many URLs of a batch are processed at the same
+// time, configured through URL_BATCH_WORKERS.
+func URLBatchWorkers() int {
+	return getEnvInt("URL_BATCH_WORKERS", 8)
+}
+
+// RedirectMaxHops returns how many redirect rules are followed when resolving
+// a URL, configured through REDIRECT_MAX_HOPS.
+func RedirectMaxHops() int {
+	return getEnvInt("REDIRECT_MAX_HOPS", 10)
+}
+
+// RedirectSiteHosts returns the hosts of this site, configured as a comma
+// separated list through REDIRECT_SITE_HOSTS. Redirect targets on these hosts
+// stay on the site, so chains and loops through them are followed.
+func RedirectSiteHosts() []string {
+	return getEnvList("REDIRECT_SITE_HOSTS", "www.byfood.com,byfood.com")
+}
+
+// RedirectsCatchAll reports whether requests matching no route are answered
+// by the stored redirect rules, configured through REDIRECTS_CATCH_ALL.
+func RedirectsCatchAll() bool {
+	return os.Getenv("REDIRECTS_CATCH_ALL") == "true"
+}
+
+// RedirectMaxChainLength returns how many hops a redirect chain may have
+// before it is reported, configured through REDIRECT_MAX_CHAIN_LENGTH.
+func RedirectMaxChainLength() int {
+	return getEnvInt("REDIRECT_MAX_CHAIN_LENGTH", 1)
+}
+
+// RedirectAutoFlatten reports whether saving a redirect rule re-points exact
+// rules of too long chains straight at the final target, configured through
+// REDIRECT_AUTO_FLATTEN. Requests can also ask for it with flatten=true.
+func RedirectAutoFlatten() bool {
+	return os.Getenv("REDIRECT_AUTO_FLATTEN") == "true"
+}
+
+// URLLocales returns the locales served under a path prefix such as /ja/,
+// configured as a comma separated list through URL_LOCALES.
+func URLLocales() []string {
+	return getEnvList("URL_LOCALES", "en,ja,zh-tw")
+}
+
+// URLDefaultLocale returns the locale of paths without a locale prefix,
+// configured through URL_DEFAULT_LOCALE. Empty means unprefixed paths have no
+// locale.
+func URLDefaultLocale() string {
+	return os.Getenv("URL_DEFAULT_LOCALE")
+}
+
+// URLLocaleQueryParameter returns the query parameter that selects a locale,
+// such as ?lang=ja, configured through URL_LOCALE_QUERY_PARAMETER.
+func URLLocaleQueryParameter() string {
+	if name := os.Getenv("URL_LOCALE_QUERY_PARAMETER"); name != "" {
+		return name
+	}
+	return "lang"
+}
+
+// URLAllowedSchemes returns the schemes URLs given to the API may use,
+// configured as a comma separated list through URL_ALLOWED_SCHEMES.
+func URLAllowedSchemes() []string {
+	return getEnvList("URL_ALLOWED_SCHEMES", "http,https")
+}
+
+// URLAllowedHosts returns the only hosts URLs given to the API may point at,
+// configured through URL_ALLOWED_HOSTS. A "*." prefix also matches every
+// subdomain. Empty allows any host that is not denied.
+func URLAllowedHosts() []string {
+	return getEnvList("URL_ALLOWED_HOSTS", "")
+}
+
+// URLDeniedHosts returns the hosts URLs given to the API may not point at,
+// configured through URL_DENIED_HOSTS, with the same patterns as
+// URLAllowedHosts.
+func URLDeniedHosts() []string {
+	return getEnvList("URL_DENIED_HOSTS", "metadata.google.internal")
+}
+
+// URLAllowPrivateHosts reports whether URLs may point at loopback, private
+// and link-local addresses, configured through URL_ALLOW_PRIVATE_HOSTS.
+func URLAllowPrivateHosts() bool {
+	return os.Getenv("URL_ALLOW_PRIVATE_HOSTS") == "true"
+}
+
+// URLMaxLength returns the longest URL the API accepts, configured through
+// URL_MAX_LENGTH.
+func URLMaxLength() int {
+	return getEnvInt("URL_MAX_LENGTH", 2048)
+}
+
+// URLStatsEnabled reports whether requests to /api/process_url are recorded
+// for the URL statistics, configured through URL_STATS_ENABLED. They are not
+// by default.
+func URLStatsEnabled() bool {
+	return os.Getenv("URL_STATS_ENABLED") == "true"
+}
+
+// URLStatsRetention returns how long recorded URL processing requests are
+// kept, configured through URL_STATS_RETENTION_DAYS. Zero keeps them forever.
+func URLStatsRetention() time.Duration {
+	return time.Duration(getEnvInt("URL_STATS_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// URLStatsPruneInterval returns how often recorded requests older than the
+// retention are deleted, configured through URL_STATS_PRUNE_INTERVAL_MINUTES.
+func URLStatsPruneInterval() time.Duration {
+	return time.Duration(getEnvInt("URL_STATS_PRUNE_INTERVAL_MINUTES", 60)) * time.Minute
+}
+
+// getEnvList splits a comma separated environment variable, using fallback
+// when it is unset.
+func getEnvList(key, fallback string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		value = fallback
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
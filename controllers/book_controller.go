@@ -5,6 +5,7 @@ import (
 	"byfood-test-backend/models"
 	"byfood-test-backend/services"
 	"net/http"
+	"path"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -18,24 +19,31 @@ import (
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param pageSize query int false "Number of items per page" default(10)
+// @Param term query string false "Search term matched against title, author and year"
+// @Param available query bool false "Only return books with at least one copy available"
+// @Param branch query int false "Only consider copies held by this branch"
 // @Success 200 {object} services.BookListResponse
 // @Failure 400 {object} services.ErrorResponse
 // @Failure 500 {object} services.ErrorResponse
 // @Router /api/books [get]
 func GetBooks(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("pageSize", "10")
 	term := c.DefaultQuery("term", "")
+	availableOnly := c.DefaultQuery("available", "false") == "true"
+	branchStr := c.DefaultQuery("branch", "")
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid page parameter. Page must be a positive integer"})
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
 		return
 	}
-	pageSize, err := strconv.Atoi(pageSizeStr)
-	if err != nil || pageSize < 1 {
-		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid pageSize parameter. Page size must be a positive integer"})
-		return
+
+	var branchID uint
+	if branchStr != "" {
+		id, err := strconv.Atoi(branchStr)
+		if err != nil || id < 1 {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid branch parameter. Branch must be a positive integer"})
+			return
+		}
+		branchID = uint(id)
 	}
 
 	offset := (page - 1) * pageSize
@@ -46,6 +54,9 @@ func GetBooks(c *gin.Context) {
 	if term != "" {
 		query = query.Where("title ILIKE ? OR author ILIKE ? OR CAST(year AS TEXT) ILIKE ?", "%"+term+"%", "%"+term+"%", "%"+term+"%")
 	}
+	if availableOnly || branchID != 0 {
+		query = query.Where("id IN (?)", services.CopiesQuery(config.DB, availableOnly, branchID))
+	}
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -60,6 +71,13 @@ func GetBooks(c *gin.Context) {
 		return
 	}
 
+	if err := services.AttachCopyCounts(config.DB, books, branchID); err != nil {
+		config.Log.WithError(err).Error("Error counting copies")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting copies"})
+		return
+	}
+	services.DefaultBookSitemap().AttachURLs(books)
+
 	paginationInfo := services.Pagination{
 		Limit:      pageSize,
 		Page:       page,
@@ -103,11 +121,19 @@ func AddBook(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Create(&book).Error; err != nil {
+	book.Slug = ""
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.AssignBookSlug(tx, &book); err != nil {
+			return err
+		}
+		return tx.Create(&book).Error
+	})
+	if err != nil {
 		config.Log.WithError(err).Error("Error adding book")
 		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error adding book"})
 		return
 	}
+	attachBookURL(&book)
 	c.JSON(http.StatusCreated, services.BookResponse{Message: "Book created successfully", Data: book})
 }
 
@@ -140,6 +166,48 @@ func GetBookByID(c *gin.Context) {
 		}
 		return
 	}
+
+	if err := services.AttachCopyCount(config.DB, &book); err != nil {
+		config.Log.WithError(err).Error("Error counting copies")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting copies"})
+		return
+	}
+	attachBookURL(&book)
+	c.JSON(http.StatusOK, book)
+}
+
+// GetBookBySlug handles retrieving a book by its slug
+// @Summary Get a book by slug
+// @Description Get details of a specific book by its slug. An old slug of a book redirects to its current slug
+// @Tags Books
+// @Produce json
+// @Param slug path string true "Book slug"
+// @Success 200 {object} models.Book
+// @Success 301 "Old slug, Location is the current slug"
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/by-slug/{slug} [get]
+func GetBookBySlug(c *gin.Context) {
+	book, moved, err := services.FindBookBySlug(config.DB, c.Param("slug"))
+	if err != nil {
+		respondServiceError(c, err, "Error fetching book", bookErrorResponses)
+		return
+	}
+
+	if moved {
+		location := *c.Request.URL
+		location.Path = path.Join(path.Dir(location.Path), book.Slug)
+		location.RawPath = ""
+		c.Redirect(http.StatusMovedPermanently, location.RequestURI())
+		return
+	}
+
+	if err := services.AttachCopyCount(config.DB, &book); err != nil {
+		config.Log.WithError(err).Error("Error counting copies")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting copies"})
+		return
+	}
+	attachBookURL(&book)
 	c.JSON(http.StatusOK, book)
 }
 
@@ -193,23 +261,32 @@ func UpdateBookByID(c *gin.Context) {
 		existingBook.Year = book.Year
 	}
 
-	if err := config.DB.Save(&existingBook).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.AssignBookSlug(tx, &existingBook); err != nil {
+			return err
+		}
+		return tx.Save(&existingBook).Error
+	})
+	if err != nil {
 		config.Log.WithError(err).Error("Error updating book")
 		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error updating book"})
 		return
 	}
-	c.JSON(http.StatusOK, services.BookResponse{Message: "Book successfully updated", Data: book})
+	attachBookURL(&existingBook)
+	c.JSON(http.StatusOK, services.BookResponse{Message: "Book successfully updated", Data: existingBook})
 }
 
 // DeleteBookByID handles deleting a book by its ID
 // @Summary Delete a book by ID
-// @Description Delete a specific book by its ID
+// @Description Delete a specific book by its ID together with its copies. Books with open loans, active holds or copies in transit cannot be deleted.
 // @Tags Books
 // @Produce json
 // @Param id path int true "Book ID"
 // @Success 200 {object} services.SuccessMessage
 // @Failure 400 {object} services.ErrorResponse
 // @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
 // @Router /api/books/{id} [delete]
 func DeleteBookByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -219,23 +296,22 @@ func DeleteBookByID(c *gin.Context) {
 		return
 	}
 
-	var book models.Book
-	if err := config.DB.First(&book, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			config.Log.WithError(err).Error("Book not found")
-			c.JSON(http.StatusNotFound, services.ErrorResponse{Error: "Book not found"})
-		} else {
-			config.Log.WithError(err).Error("Error fetching book")
-			c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching book"})
-		}
-		return
-	}
-
-	if err := config.DB.Delete(&book).Error; err != nil {
-		config.Log.WithError(err).Error("Error deleting book")
-		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error deleting book"})
+	if err := services.DeleteBook(config.DB, uint(id)); err != nil {
+		respondServiceError(c, err, "Error deleting book", bookErrorResponses)
 		return
 	}
 
 	c.JSON(http.StatusOK, services.SuccessMessage{Message: "Book successfully deleted"})
 }
+
+var bookErrorResponses = []errorMapping{
+	{services.ErrBookNotFound, http.StatusNotFound, "Book not found"},
+	{services.ErrBookInCirculation, http.StatusConflict, "Book has open loans, active holds or copies in transit"},
+}
+
+// attachBookURL sets the canonical page URL of book.
+func attachBookURL(book *models.Book) {
+	books := []models.Book{*book}
+	services.DefaultBookSitemap().AttachURLs(books)
+	book.URL = books[0].URL
+}
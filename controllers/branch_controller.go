@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddBranch handles adding a library branch
+// @Summary Add a new branch
+// @Description Add a library branch that holds copies
+// @Tags Branches
+// @Accept json
+// @Produce json
+// @Param branch body models.Branch true "Branch to add"
+// @Success 201 {object} services.BranchResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/branches [post]
+func AddBranch(c *gin.Context) {
+	var branch models.Branch
+	if err := c.ShouldBindJSON(&branch); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	if branch.Code == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Code cannot be empty"})
+		return
+	}
+
+	if branch.Name == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Name cannot be empty"})
+		return
+	}
+
+	if err := config.DB.Create(&branch).Error; err != nil {
+		config.Log.WithError(err).Error("Error adding branch")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error adding branch"})
+		return
+	}
+	c.JSON(http.StatusCreated, services.BranchResponse{Message: "Branch created successfully", Data: branch})
+}
+
+// GetBranches handles listing library branches
+// @Summary Get all branches
+// @Description Get every library branch
+// @Tags Branches
+// @Produce json
+// @Success 200 {object} services.BranchListResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/branches [get]
+func GetBranches(c *gin.Context) {
+	var branches []models.Branch
+	if err := config.DB.Order("name ASC").Find(&branches).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching branches")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching branches"})
+		return
+	}
+	c.JSON(http.StatusOK, services.BranchListResponse{Data: branches})
+}
+
+// GetBranchByID handles retrieving a library branch by ID
+// @Summary Get a branch by ID
+// @Description Get details of a specific library branch
+// @Tags Branches
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} models.Branch
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/branches/{id} [get]
+func GetBranchByID(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var branch models.Branch
+	if err := config.DB.First(&branch, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			config.Log.WithError(err).Error("Branch not found")
+			c.JSON(http.StatusNotFound, services.ErrorResponse{Error: "Branch not found"})
+		} else {
+			config.Log.WithError(err).Error("Error fetching branch")
+			c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching branch"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, branch)
+}
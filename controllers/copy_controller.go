@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetBookCopies handles listing the physical copies of a book
+// @Summary Get the copies of a book
+// @Description Get every physical copy of a specific book
+// @Tags Copies
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param branch query int false "Only list copies held by this branch"
+// @Success 200 {object} services.CopyListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/{id}/copies [get]
+func GetBookCopies(c *gin.Context) {
+	book, ok := findBookParam(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Where("book_id = ?", book.ID)
+	if branchStr := c.Query("branch"); branchStr != "" {
+		branchID, err := strconv.Atoi(branchStr)
+		if err != nil || branchID < 1 {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid branch parameter. Branch must be a positive integer"})
+			return
+		}
+		query = query.Where("branch_id = ?", branchID)
+	}
+
+	var copies []models.Copy
+	if err := query.Order("id ASC").Find(&copies).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching copies")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching copies"})
+		return
+	}
+	c.JSON(http.StatusOK, services.CopyListResponse{Data: copies})
+}
+
+// AddBookCopy handles adding a physical copy of a book
+// @Summary Add a copy of a book
+// @Description Register a new physical copy of a specific book
+// @Tags Copies
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copy body services.CopyChanges true "Copy to add"
+// @Success 201 {object} services.CopyResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/{id}/copies [post]
+func AddBookCopy(c *gin.Context) {
+	book, ok := findBookParam(c)
+	if !ok {
+		return
+	}
+
+	var request services.CopyChanges
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	if request.Barcode == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Barcode cannot be empty"})
+		return
+	}
+	if request.Condition == "" {
+		request.Condition = models.CopyConditionGood
+	}
+	if !models.ValidCopyCondition(request.Condition) {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid condition"})
+		return
+	}
+	if request.Status == "" {
+		request.Status = models.CopyStatusAvailable
+	}
+	if models.CopyInCirculation(request.Status) || !models.ValidCopyStatus(request.Status) {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid status"})
+		return
+	}
+
+	bookCopy := models.Copy{
+		BookID:    book.ID,
+		BranchID:  request.BranchID,
+		Barcode:   request.Barcode,
+		Condition: request.Condition,
+		Location:  request.Location,
+		Status:    request.Status,
+	}
+	if err := services.AddCopy(config.DB, &bookCopy, time.Now()); err != nil {
+		config.Log.WithError(err).Error("Error adding copy")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error adding copy"})
+		return
+	}
+	c.JSON(http.StatusCreated, services.CopyResponse{Message: "Copy created successfully", Data: bookCopy})
+}
+
+// UpdateBookCopy handles updating a physical copy of a book
+// @Summary Update a copy of a book
+// @Description Update the branch, barcode, condition, location or status of a copy. Copies on loan, on hold or in transit only change status through returns, holds and transfers.
+// @Tags Copies
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Param copy body services.CopyChanges true "Copy data to update"
+// @Success 200 {object} services.CopyResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/{id}/copies/{copyId} [put]
+func UpdateBookCopy(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	copyID, ok := parseIDParam(c, "copyId")
+	if !ok {
+		return
+	}
+
+	var request services.CopyChanges
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	bookCopy, err := services.UpdateCopy(config.DB, bookID, copyID, request, time.Now())
+	if err != nil {
+		respondCopyError(c, err, "Error updating copy")
+		return
+	}
+	c.JSON(http.StatusOK, services.CopyResponse{Message: "Copy successfully updated", Data: bookCopy})
+}
+
+// DeleteBookCopy handles removing a physical copy of a book
+// @Summary Delete a copy of a book
+// @Description Remove a copy that is not on loan, set aside for a hold or in transit
+// @Tags Copies
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copyId path int true "Copy ID"
+// @Success 200 {object} services.SuccessMessage
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/{id}/copies/{copyId} [delete]
+func DeleteBookCopy(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	copyID, ok := parseIDParam(c, "copyId")
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteCopy(config.DB, bookID, copyID); err != nil {
+		respondCopyError(c, err, "Error deleting copy")
+		return
+	}
+	c.JSON(http.StatusOK, services.SuccessMessage{Message: "Copy successfully deleted"})
+}
+
+// findBookParam loads the book named by the id path parameter. On failure it
+// writes the error response and returns ok=false.
+func findBookParam(c *gin.Context) (models.Book, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return models.Book{}, false
+	}
+
+	var book models.Book
+	if err := config.DB.First(&book, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			config.Log.WithError(err).Error("Book not found")
+			c.JSON(http.StatusNotFound, services.ErrorResponse{Error: "Book not found"})
+		} else {
+			config.Log.WithError(err).Error("Error fetching book")
+			c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching book"})
+		}
+		return models.Book{}, false
+	}
+	return book, true
+}
+
+var copyErrorResponses = []errorMapping{
+	{services.ErrCopyNotFound, http.StatusNotFound, "Copy not found"},
+	{services.ErrBranchNotFound, http.StatusNotFound, "Branch not found"},
+	{services.ErrInvalidCopyStatus, http.StatusBadRequest, "Invalid status"},
+	{services.ErrInvalidCopyCondition, http.StatusBadRequest, "Invalid condition"},
+	{services.ErrCopyStatusUnavailable, http.StatusBadRequest, "Copies can only be lent, set aside or moved through checkouts, holds and transfers"},
+	{services.ErrCopyInCirculation, http.StatusConflict, "Copy is on loan, set aside for a hold or in transit"},
+}
+
+// respondCopyError maps errors from the copy service onto HTTP responses.
+func respondCopyError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, copyErrorResponses)
+}
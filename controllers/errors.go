@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorMapping ties a service error to the HTTP status and message returned
+// to the client.
+type errorMapping struct {
+	err     error
+	status  int
+	message string
+}
+
+// respondServiceError logs err and writes the response of the first mapping
+// matching it, falling back to a 500 with the fallback message.
+func respondServiceError(c *gin.Context, err error, fallback string, mappings []errorMapping) {
+	config.Log.WithError(err).Error(fallback)
+
+	for _, mapping := range mappings {
+		if errors.Is(err, mapping.err) {
+			c.JSON(mapping.status, services.ErrorResponse{Error: mapping.message})
+			return
+		}
+	}
+	c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: fallback})
+}
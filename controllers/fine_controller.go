@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FineEntryRequest struct {
+	Kind   string `json:"kind" binding:"required" enums:"payment,waiver" example:"payment"`
+	Amount int64  `json:"amount" binding:"required" example:"250"`
+	LoanID *uint  `json:"loan_id" example:"1"`
+	Note   string `json:"note" example:"Paid at the front desk"`
+}
+
+// GetUserFines handles retrieving the fine account of a user
+// @Summary Get the fines of a user
+// @Description Get the fine ledger of a user, its balance, the fines still accruing on overdue loans and whether checkouts are blocked
+// @Tags Fines
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} services.FineSummaryResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/users/{id}/fines [get]
+func GetUserFines(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := services.GetFineSummary(config.DB, userID, time.Now())
+	if err != nil {
+		respondFineError(c, err, "Error fetching fines")
+		return
+	}
+	c.JSON(http.StatusOK, services.FineSummaryResponse{Data: summary})
+}
+
+// AddUserFineEntry handles recording a fine payment or waiver
+// @Summary Record a fine payment or waiver
+// @Description Record a payment or a waiver reducing the fine balance of a user
+// @Tags Fines
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param entry body FineEntryRequest true "Payment or waiver"
+// @Success 201 {object} services.FineEntryResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/users/{id}/fines/payments [post]
+func AddUserFineEntry(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var request FineEntryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	entry, err := services.RecordFineEntry(config.DB, userID, models.FineEntry{
+		LoanID: request.LoanID,
+		Kind:   request.Kind,
+		Amount: request.Amount,
+		Note:   request.Note,
+	})
+	if err != nil {
+		respondFineError(c, err, "Error recording fine entry")
+		return
+	}
+	c.JSON(http.StatusCreated, services.FineEntryResponse{Message: "Fine entry recorded successfully", Data: entry})
+}
+
+var fineErrorResponses = []errorMapping{
+	{services.ErrUserNotFound, http.StatusNotFound, "User not found"},
+	{services.ErrInvalidFineEntry, http.StatusBadRequest, "Fine entries must be a payment or a waiver with a positive amount"},
+	{services.ErrPaymentExceedsBalance, http.StatusConflict, "Amount exceeds the outstanding fine balance"},
+	{services.ErrFineLoanNotFound, http.StatusNotFound, "Loan not found for this user"},
+}
+
+// respondFineError maps errors from the fine service onto HTTP responses.
+func respondFineError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, fineErrorResponses)
+}
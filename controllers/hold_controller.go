@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldRequest struct {
+	UserID uint `json:"user_id" binding:"required" example:"1"`
+}
+
+// PlaceHold handles joining the hold queue for a book
+// @Summary Place a hold on a book
+// @Description Join the queue for a book whose copies are all out. When a copy comes back it is set aside for the oldest hold until its pickup deadline.
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param hold body HoldRequest true "User placing the hold"
+// @Success 201 {object} services.HoldResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/{id}/holds [post]
+func PlaceHold(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var request HoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	hold, err := services.PlaceHold(config.DB, bookID, request.UserID)
+	if err != nil {
+		respondHoldError(c, err, "Error placing hold")
+		return
+	}
+	c.JSON(http.StatusCreated, services.HoldResponse{Message: "Hold placed successfully", Data: hold})
+}
+
+// GetHolds handles listing holds and their queue positions
+// @Summary Get holds
+// @Description Get holds with their position in the queue. Only waiting and ready holds are listed unless a status is given.
+// @Tags Holds
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param book_id query int false "Book ID"
+// @Param status query string false "Hold status" Enums(waiting, ready, fulfilled, expired, cancelled)
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of items per page" default(10)
+// @Success 200 {object} services.HoldListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/holds [get]
+func GetHolds(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	if _, err := services.ExpireHolds(config.DB, time.Now()); err != nil {
+		config.Log.WithError(err).Error("Error expiring holds")
+	}
+
+	query := config.DB.Model(&models.Hold{})
+
+	switch status := c.Query("status"); status {
+	case "":
+		query = query.Where("status IN ?", []string{models.HoldStatusWaiting, models.HoldStatusReady})
+	case models.HoldStatusWaiting, models.HoldStatusReady, models.HoldStatusFulfilled, models.HoldStatusExpired, models.HoldStatusCancelled:
+		query = query.Where("status = ?", status)
+	default:
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid status parameter. Status must be one of waiting, ready, fulfilled, expired, cancelled"})
+		return
+	}
+
+	for _, filter := range []string{"user_id", "book_id"} {
+		value := c.Query(filter)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id < 1 {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid " + filter + " parameter"})
+			return
+		}
+		query = query.Where(filter+" = ?", id)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		config.Log.WithError(err).Error("Error counting holds")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting holds"})
+		return
+	}
+
+	var holds []models.Hold
+	if err := query.Preload("Book").Order("id ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&holds).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching holds")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching holds"})
+		return
+	}
+
+	if err := services.AttachHoldPositions(config.DB, holds); err != nil {
+		config.Log.WithError(err).Error("Error computing hold positions")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error computing hold positions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.HoldListResponse{
+		Data:       holds,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+// CancelHold handles leaving the hold queue
+// @Summary Cancel a hold
+// @Description Cancel a waiting or ready hold. A copy set aside for it goes to the next hold in the queue.
+// @Tags Holds
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} services.HoldResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/holds/{id} [delete]
+func CancelHold(c *gin.Context) {
+	holdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	hold, err := services.CancelHold(config.DB, holdID, time.Now())
+	if err != nil {
+		respondHoldError(c, err, "Error cancelling hold")
+		return
+	}
+	c.JSON(http.StatusOK, services.HoldResponse{Message: "Hold successfully cancelled", Data: hold})
+}
+
+var holdErrorResponses = []errorMapping{
+	{services.ErrBookNotFound, http.StatusNotFound, "Book not found"},
+	{services.ErrUserNotFound, http.StatusNotFound, "User not found"},
+	{services.ErrHoldNotFound, http.StatusNotFound, "Hold not found"},
+	{services.ErrCopyAvailable, http.StatusConflict, "A copy of this book is available, check it out instead"},
+	{services.ErrDuplicateHold, http.StatusConflict, "User already has a hold on this book"},
+	{services.ErrHoldNotCancelable, http.StatusConflict, "Hold is no longer active"},
+}
+
+// respondHoldError maps errors from the hold service onto HTTP responses.
+func respondHoldError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, holdErrorResponses)
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LinkCheckRequest lists what a link check job covers. URLs are checked as
+// they are; pages, and the book pages when book_pages is set, are fetched and
+// the links on them checked too. redirect_targets adds the external targets
+// of the enabled redirect rules.
+type LinkCheckRequest struct {
+	URLs            []string `json:"urls" example:"https://example.com/partner"`
+	Pages           []string `json:"pages" example:"https://www.byfood.com/books/1"`
+	RedirectTargets bool     `json:"redirect_targets" example:"true"`
+	BookPages       bool     `json:"book_pages" example:"false"`
+}
+
+var linkCheckErrorResponses = []errorMapping{
+	{services.ErrLinkCheckJobNotFound, http.StatusNotFound, "Link check job not found"},
+	{services.ErrEmptyLinkCheck, http.StatusBadRequest, "The link check has no URLs"},
+	{services.ErrLinkCheckTooLarge, http.StatusBadRequest, "The link check has too many URLs"},
+}
+
+// StartLinkCheck handles starting a link check job
+// @Summary Start a link check
+// @Description Check URLs, redirect targets and the links on pages for broken links in the background. Poll /api/linkcheck/{job} for the results.
+// @Tags Link Check
+// @Accept json
+// @Produce json
+// @Param request body LinkCheckRequest true "What to check"
+// @Success 202 {object} services.LinkCheckJobResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/linkcheck [post]
+func StartLinkCheck(c *gin.Context) {
+	var request LinkCheckRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	var links, pages []services.LinkTarget
+	for _, link := range request.URLs {
+		links = append(links, services.LinkTarget{URL: link, Source: services.LinkSourceRequest})
+	}
+	for _, page := range request.Pages {
+		pages = append(pages, services.LinkTarget{URL: page, Source: services.LinkSourcePage})
+	}
+	if request.RedirectTargets {
+		rules, err := services.EnabledRedirectRules(config.DB)
+		if err != nil {
+			respondLinkCheckError(c, err, "Error fetching redirect rules")
+			return
+		}
+		links = append(links, services.RedirectTargetLinks(rules)...)
+	}
+	if request.BookPages {
+		bookPages, err := services.BookPageLinks(config.DB, services.DefaultBookSitemap())
+		if err != nil {
+			respondLinkCheckError(c, err, "Error listing book pages")
+			return
+		}
+		pages = append(pages, bookPages...)
+	}
+
+	job, err := services.StartLinkCheckJob(config.DB, services.DefaultLinkChecker(), links, pages, config.LinkCheckMaxURLs(), config.LinkCheckJobTimeout())
+	if err != nil {
+		respondLinkCheckError(c, err, "Error starting link check")
+		return
+	}
+	c.JSON(http.StatusAccepted, services.LinkCheckJobResponse{Message: "Link check started", Data: job})
+}
+
+// GetLinkCheckJob handles retrieving a link check job and its results
+// @Summary Get a link check job
+// @Description Get the progress of a link check job and a page of its results, optionally only the broken ones
+// @Tags Link Check
+// @Produce json
+// @Param job path int true "Job ID"
+// @Param broken query bool false "Only list broken links"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of results per page" default(10)
+// @Success 200 {object} services.LinkCheckResultsResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/linkcheck/{job} [get]
+func GetLinkCheckJob(c *gin.Context) {
+	id, ok := parseIDParam(c, "job")
+	if !ok {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	job, err := services.GetLinkCheckJob(config.DB, id)
+	if err != nil {
+		respondLinkCheckError(c, err, "Error fetching link check job")
+		return
+	}
+
+	query := config.DB.Model(&models.LinkCheckResult{}).Where("job_id = ?", job.ID)
+	if c.DefaultQuery("broken", "false") == "true" {
+		query = query.Where("broken = ?", true)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		respondLinkCheckError(c, err, "Error counting link check results")
+		return
+	}
+
+	var results []models.LinkCheckResult
+	if err := query.Order("id ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&results).Error; err != nil {
+		respondLinkCheckError(c, err, "Error fetching link check results")
+		return
+	}
+
+	c.JSON(http.StatusOK, services.LinkCheckResultsResponse{
+		Data:       job,
+		Results:    results,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+// respondLinkCheckError maps errors from the link check service onto HTTP responses.
+func respondLinkCheckError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, linkCheckErrorResponses)
+}
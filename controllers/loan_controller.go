@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckoutRequest struct {
+	UserID uint `json:"user_id" binding:"required" example:"1"`
+}
+
+// CheckoutBook handles lending a book to a user
+// @Summary Check out a book
+// @Description Lend a copy of a book to a user. Fails when no copy is available or the user's outstanding fines are above the threshold.
+// @Tags Loans
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param checkout body CheckoutRequest true "Borrower"
+// @Success 201 {object} services.LoanResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/books/{id}/checkout [post]
+func CheckoutBook(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var request CheckoutRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	loan, err := services.CheckoutBook(config.DB, bookID, request.UserID, time.Now())
+	if err != nil {
+		respondLoanError(c, err, "Error checking out book")
+		return
+	}
+	c.JSON(http.StatusCreated, services.LoanResponse{Message: "Book checked out successfully", Data: loan})
+}
+
+// ReturnLoan handles returning a borrowed book
+// @Summary Return a loan
+// @Description Mark a loan as returned, making the copy available again
+// @Tags Loans
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} services.LoanResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/loans/{id}/return [post]
+func ReturnLoan(c *gin.Context) {
+	loanID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	loan, err := services.ReturnLoan(config.DB, loanID, time.Now())
+	if err != nil {
+		respondLoanError(c, err, "Error returning book")
+		return
+	}
+	c.JSON(http.StatusOK, services.LoanResponse{Message: "Book returned successfully", Data: loan})
+}
+
+// RenewLoan handles extending the due date of a loan
+// @Summary Renew a loan
+// @Description Extend the due date of an active loan by another loan period
+// @Tags Loans
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} services.LoanResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/loans/{id}/renew [post]
+func RenewLoan(c *gin.Context) {
+	loanID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	loan, err := services.RenewLoan(config.DB, loanID, time.Now())
+	if err != nil {
+		respondLoanError(c, err, "Error renewing loan")
+		return
+	}
+	c.JSON(http.StatusOK, services.LoanResponse{Message: "Loan renewed successfully", Data: loan})
+}
+
+// GetLoans handles listing loans
+// @Summary Get loans
+// @Description Get loans with pagination, optionally filtered by status and borrower
+// @Tags Loans
+// @Produce json
+// @Param status query string false "Loan status" Enums(active, overdue, returned)
+// @Param user_id query int false "Borrower ID"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of items per page" default(10)
+// @Success 200 {object} services.LoanListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/loans [get]
+func GetLoans(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	query := config.DB.Model(&models.Loan{})
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.LoanStatusActive:
+		query = query.Where("returned_at IS NULL AND due_at >= ?", now)
+	case models.LoanStatusOverdue:
+		query = query.Where("returned_at IS NULL AND due_at < ?", now)
+	case models.LoanStatusReturned:
+		query = query.Where("returned_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid status parameter. Status must be one of active, overdue, returned"})
+		return
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil || userID < 1 {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid user_id parameter"})
+			return
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		config.Log.WithError(err).Error("Error counting loans")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting loans"})
+		return
+	}
+
+	var loans []models.Loan
+	if err := query.Preload("Book").Preload("User").Order("due_at ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&loans).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching loans")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching loans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.LoanListResponse{
+		Data:       loans,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+var loanErrorResponses = []errorMapping{
+	{services.ErrBookNotFound, http.StatusNotFound, "Book not found"},
+	{services.ErrUserNotFound, http.StatusNotFound, "User not found"},
+	{services.ErrLoanNotFound, http.StatusNotFound, "Loan not found"},
+	{services.ErrNoCopyAvailable, http.StatusConflict, "No copy of this book is available"},
+	{services.ErrLoanAlreadyReturned, http.StatusConflict, "Loan has already been returned"},
+	{services.ErrRenewalLimitReached, http.StatusConflict, "Loan has reached the maximum number of renewals"},
+	{services.ErrLoanOverdue, http.StatusConflict, "Overdue loans cannot be renewed"},
+	{services.ErrFineBalanceTooHigh, http.StatusForbidden, "Outstanding fines exceed the checkout threshold"},
+}
+
+// respondLoanError maps errors from the loan service onto HTTP responses.
+func respondLoanError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, loanErrorResponses)
+}
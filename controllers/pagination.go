@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePagination reads the page and pageSize query parameters. On invalid
+// input it writes a 400 response and returns ok=false.
+func parsePagination(c *gin.Context) (page int, pageSize int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid page parameter. Page must be a positive integer"})
+		return 0, 0, false
+	}
+	pageSize, err = strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid pageSize parameter. Page size must be a positive integer"})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// parseIDParam reads a numeric path parameter. On invalid input it writes a
+// 400 response and returns ok=false.
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id < 1 {
+		config.Log.WithError(err).Error("Invalid ID")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
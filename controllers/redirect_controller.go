@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddRedirectRule handles adding a redirect rule
+// @Summary Add a redirect rule
+// @Description Add a rule redirecting a path to a target. Rules default to an enabled 301 exact match. Rules closing a redirect loop are rejected and chains longer than REDIRECT_MAX_CHAIN_LENGTH are reported as warnings or, with flatten, shortened.
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Param rule body services.RedirectRuleChanges true "Redirect rule to add"
+// @Param flatten query bool false "Re-point exact rules of too long chains straight at the final target"
+// @Success 201 {object} services.RedirectRuleResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects [post]
+func AddRedirectRule(c *gin.Context) {
+	var request services.RedirectRuleChanges
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	rule, warnings, err := services.CreateRedirectRule(config.DB, request, redirectChainPolicy(c))
+	if err != nil {
+		respondRedirectError(c, err, "Error adding redirect rule")
+		return
+	}
+	c.JSON(http.StatusCreated, services.RedirectRuleResponse{Message: "Redirect rule created successfully", Data: rule, Warnings: warnings})
+}
+
+// GetRedirectRules handles listing redirect rules
+// @Summary Get all redirect rules
+// @Description Get the redirect rules in the order they are tried, highest priority first
+// @Tags Redirects
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of items per page" default(10)
+// @Param enabled query bool false "Only list enabled (true) or disabled (false) rules"
+// @Success 200 {object} services.RedirectRuleListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects [get]
+func GetRedirectRules(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.RedirectRule{})
+	switch enabled := c.Query("enabled"); enabled {
+	case "":
+	case "true", "false":
+		query = query.Where("enabled = ?", enabled == "true")
+	default:
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid enabled parameter. Enabled must be true or false"})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		config.Log.WithError(err).Error("Error counting redirect rules")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting redirect rules"})
+		return
+	}
+
+	var rules []models.RedirectRule
+	if err := query.Order("priority DESC, id ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&rules).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching redirect rules")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching redirect rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.RedirectRuleListResponse{
+		Data:       rules,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+// GetRedirectRuleByID handles retrieving a redirect rule by ID
+// @Summary Get a redirect rule by ID
+// @Description Get details of a specific redirect rule
+// @Tags Redirects
+// @Produce json
+// @Param id path int true "Redirect rule ID"
+// @Success 200 {object} models.RedirectRule
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/{id} [get]
+func GetRedirectRuleByID(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var rule models.RedirectRule
+	if err := config.DB.First(&rule, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			config.Log.WithError(err).Error("Redirect rule not found")
+			c.JSON(http.StatusNotFound, services.ErrorResponse{Error: "Redirect rule not found"})
+		} else {
+			config.Log.WithError(err).Error("Error fetching redirect rule")
+			c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching redirect rule"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateRedirectRule handles updating a redirect rule
+// @Summary Update a redirect rule
+// @Description Update the source, target, status code, match type, priority or enabled flag of a redirect rule. Loops and chains are checked like when adding a rule.
+// @Tags Redirects
+// @Accept json
+// @Produce json
+// @Param id path int true "Redirect rule ID"
+// @Param rule body services.RedirectRuleChanges true "Redirect rule data to update"
+// @Param flatten query bool false "Re-point exact rules of too long chains straight at the final target"
+// @Success 200 {object} services.RedirectRuleResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/{id} [put]
+func UpdateRedirectRule(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var request services.RedirectRuleChanges
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	rule, warnings, err := services.UpdateRedirectRule(config.DB, id, request, redirectChainPolicy(c))
+	if err != nil {
+		respondRedirectError(c, err, "Error updating redirect rule")
+		return
+	}
+	c.JSON(http.StatusOK, services.RedirectRuleResponse{Message: "Redirect rule successfully updated", Data: rule, Warnings: warnings})
+}
+
+// DeleteRedirectRule handles deleting a redirect rule
+// @Summary Delete a redirect rule
+// @Description Delete a specific redirect rule
+// @Tags Redirects
+// @Produce json
+// @Param id path int true "Redirect rule ID"
+// @Success 200 {object} services.SuccessMessage
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/{id} [delete]
+func DeleteRedirectRule(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteRedirectRule(config.DB, id); err != nil {
+		respondRedirectError(c, err, "Error deleting redirect rule")
+		return
+	}
+	c.JSON(http.StatusOK, services.SuccessMessage{Message: "Redirect rule successfully deleted"})
+}
+
+// ResolveRedirect handles resolving a URL against the redirect rules
+// @Summary Resolve a redirect
+// @Description Follow the enabled redirect rules from a URL or path and return the final target with every hop
+// @Tags Redirects
+// @Produce json
+// @Param url query string true "URL or path to resolve"
+// @Success 200 {object} services.RedirectResolution
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 508 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/resolve [get]
+func ResolveRedirect(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Url cannot be empty"})
+		return
+	}
+
+	resolution, err := services.ResolveRedirect(config.DB, rawURL, config.RedirectMaxHops())
+	if err != nil {
+		respondRedirectError(c, err, "Error resolving redirect")
+		return
+	}
+	c.JSON(http.StatusOK, resolution)
+}
+
+// AuditRedirectRules handles reporting redirect chains and loops
+// @Summary Audit redirect rules
+// @Description Report every chain of two or more hops and every loop among the enabled redirect rules. Chains longer than REDIRECT_MAX_CHAIN_LENGTH are flagged as too long.
+// @Tags Redirects
+// @Produce json
+// @Success 200 {object} services.RedirectAuditResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/audit [get]
+func AuditRedirectRules(c *gin.Context) {
+	audit, err := services.AuditRedirects(config.DB, config.RedirectMaxChainLength(), config.RedirectMaxHops())
+	if err != nil {
+		config.Log.WithError(err).Error("Error auditing redirect rules")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error auditing redirect rules"})
+		return
+	}
+	c.JSON(http.StatusOK, services.RedirectAuditResponse{Data: audit})
+}
+
+// ExportRedirectRules handles exporting the redirect rules as server configuration
+// @Summary Export redirect rules
+// @Description Export the enabled redirect rules as an nginx map block, Apache RewriteRules or the redirects of a vercel.json. Rules the format cannot express are left out and reported in X-Redirect-Export-Warning headers.
+// @Tags Redirects
+// @Produce plain
+// @Produce json
+// @Param format query string true "nginx, apache or vercel"
+// @Success 200 {string} string "Server configuration"
+// @Header 200 {string} X-Redirect-Export-Warning "A rule that was left out of the export"
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/export [get]
+func ExportRedirectRules(c *gin.Context) {
+	rules, err := services.EnabledRedirectRules(config.DB)
+	if err != nil {
+		config.Log.WithError(err).Error("Error fetching redirect rules")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching redirect rules"})
+		return
+	}
+
+	format := c.Query("format")
+	exported, err := services.ExportRedirectRules(rules, format)
+	if err != nil {
+		respondRedirectError(c, err, "Error exporting redirect rules")
+		return
+	}
+
+	for _, warning := range exported.Warnings {
+		c.Writer.Header().Add("X-Redirect-Export-Warning", warning)
+	}
+	contentType := "text/plain; charset=utf-8"
+	if format == services.RedirectFormatVercel {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, []byte(exported.Config))
+}
+
+// ImportRedirectRules handles importing redirect rules from server configuration
+// @Summary Import redirect rules
+// @Description Parse an nginx, Apache or vercel.json configuration sent as the request body and store the redirects it holds. Lines that are not redirects, and rules that cannot be stored, are reported under unparsed.
+// @Tags Redirects
+// @Accept plain
+// @Accept json
+// @Produce json
+// @Param format query string true "nginx, apache or vercel"
+// @Param dry_run query bool false "Only parse the configuration without storing rules"
+// @Param flatten query bool false "Re-point exact rules of too long chains straight at the final target"
+// @Param config body string true "Server configuration"
+// @Success 200 {object} services.RedirectImportResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/redirects/import [post]
+func ImportRedirectRules(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil || len(data) == 0 {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	dryRun := c.DefaultQuery("dry_run", "false") == "true"
+	result, err := services.ImportRedirectRules(config.DB, string(data), c.Query("format"), redirectChainPolicy(c), dryRun)
+	if err != nil {
+		respondRedirectError(c, err, "Error importing redirect rules")
+		return
+	}
+	c.JSON(http.StatusOK, services.RedirectImportResponse{Data: result})
+}
+
+// redirectChainPolicy builds the chain policy for saving a rule from the
+// configuration and the flatten query parameter.
+func redirectChainPolicy(c *gin.Context) services.RedirectChainPolicy {
+	return services.RedirectChainPolicy{
+		MaxLength: config.RedirectMaxChainLength(),
+		MaxHops:   config.RedirectMaxHops(),
+		Flatten:   config.RedirectAutoFlatten() || c.DefaultQuery("flatten", "false") == "true",
+	}
+}
+
+// HandleRedirect answers requests that match no route with the stored
+// redirect rules. It is installed as the NoRoute handler when
+// REDIRECTS_CATCH_ALL is enabled.
+func HandleRedirect(c *gin.Context) {
+	resolution, err := services.ResolveRedirect(config.DB, c.Request.URL.RequestURI(), config.RedirectMaxHops())
+	if errors.Is(err, services.ErrNoRedirect) {
+		c.JSON(http.StatusNotFound, services.ErrorResponse{Error: "Not found"})
+		return
+	}
+	if err != nil {
+		respondRedirectError(c, err, "Error resolving redirect")
+		return
+	}
+	c.Redirect(resolution.StatusCode, resolution.Target)
+}
+
+var redirectErrorResponses = []errorMapping{
+	{services.ErrRedirectRuleNotFound, http.StatusNotFound, "Redirect rule not found"},
+	{services.ErrInvalidRedirectSource, http.StatusBadRequest, "Source must be a path starting with a slash or a valid regular expression"},
+	{services.ErrInvalidRedirectTarget, http.StatusBadRequest, "Target must be a path starting with a slash or an absolute http(s) URL"},
+	{services.ErrInvalidRedirectStatus, http.StatusBadRequest, "Status code must be 301, 302 or 308"},
+	{services.ErrInvalidRedirectMatch, http.StatusBadRequest, "Match type must be exact, prefix or regex"},
+	{services.ErrInvalidRedirectURL, http.StatusBadRequest, "Invalid URL"},
+	{services.ErrUnknownRedirectFormat, http.StatusBadRequest, "Format must be nginx, apache or vercel"},
+	{services.ErrInvalidRedirectConfig, http.StatusBadRequest, "Invalid configuration"},
+	{services.ErrNoRedirect, http.StatusNotFound, "No redirect rule matches this URL"},
+	{services.ErrRedirectLoop, http.StatusLoopDetected, "Redirect rules form a loop"},
+	{services.ErrRedirectRuleLoop, http.StatusConflict, "Redirect rule would create a loop"},
+}
+
+// respondRedirectError maps errors from the redirect service onto HTTP responses.
+func respondRedirectError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, redirectErrorResponses)
+}
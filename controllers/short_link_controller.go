@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var shortLinkErrorResponses = []errorMapping{
+	{services.ErrShortLinkNotFound, http.StatusNotFound, "Short link not found"},
+	{services.ErrShortLinkDisabled, http.StatusNotFound, "Short link not found"},
+	{services.ErrShortLinkExpired, http.StatusGone, "Short link has expired"},
+	{services.ErrInvalidShortCode, http.StatusBadRequest, "Code must be 3 to 64 letters, digits, dashes or underscores"},
+	{services.ErrShortCodeTaken, http.StatusConflict, "Code is already used for another URL"},
+	{services.ErrShortLinkExpiryPast, http.StatusBadRequest, "Expiry date must be in the future"},
+	{services.ErrShortLinkHost, http.StatusBadRequest, "Short links may only point at the hosts of this site"},
+}
+
+// AddShortLink handles creating a short link
+// @Summary Add a short link
+// @Description Canonicalize a URL on one of SHORTLINK_ALLOWED_HOSTS with SHORTLINK_URL_OPERATIONS and return a short link to it, with a generated code unless a custom code is given. A URL that already has an active short link gets that link back.
+// @Tags Short Links
+// @Accept json
+// @Produce json
+// @Param link body services.ShortLinkRequest true "URL to shorten"
+// @Success 201 {object} services.ShortLinkResponse
+// @Success 200 {object} services.ShortLinkResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/links [post]
+func AddShortLink(c *gin.Context) {
+	var request services.ShortLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+	if request.URL == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Url cannot be empty"})
+		return
+	}
+
+	link, created, err := services.CreateShortLink(config.DB, request, config.ShortLinkURLOperations(), config.ShortLinkAllowedHosts(), config.ShortLinkCodeLength(), time.Now())
+	if err != nil {
+		respondShortLinkError(c, err, "Error adding short link")
+		return
+	}
+
+	attachShortURL(c, &link)
+	if !created {
+		c.JSON(http.StatusOK, services.ShortLinkResponse{Message: "Short link already exists", Data: link})
+		return
+	}
+	c.JSON(http.StatusCreated, services.ShortLinkResponse{Message: "Short link created successfully", Data: link})
+}
+
+// GetShortLinks handles listing short links
+// @Summary Get all short links
+// @Description Get the short links with their click counts, newest first
+// @Tags Short Links
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of items per page" default(10)
+// @Param enabled query bool false "Only list enabled (true) or disabled (false) links"
+// @Success 200 {object} services.ShortLinkListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/links [get]
+func GetShortLinks(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.ShortLink{})
+	switch enabled := c.Query("enabled"); enabled {
+	case "":
+	case "true", "false":
+		query = query.Where("enabled = ?", enabled == "true")
+	default:
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid enabled parameter. Enabled must be true or false"})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		config.Log.WithError(err).Error("Error counting short links")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting short links"})
+		return
+	}
+
+	var links []models.ShortLink
+	if err := query.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&links).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching short links")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching short links"})
+		return
+	}
+	for i := range links {
+		attachShortURL(c, &links[i])
+	}
+
+	c.JSON(http.StatusOK, services.ShortLinkListResponse{
+		Data:       links,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+// GetShortLinkByID handles retrieving a short link by ID
+// @Summary Get a short link by ID
+// @Description Get a specific short link with its click count
+// @Tags Short Links
+// @Produce json
+// @Param id path int true "Short link ID"
+// @Success 200 {object} models.ShortLink
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/links/{id} [get]
+func GetShortLinkByID(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	link, err := services.GetShortLink(config.DB, id)
+	if err != nil {
+		respondShortLinkError(c, err, "Error fetching short link")
+		return
+	}
+	attachShortURL(c, &link)
+	c.JSON(http.StatusOK, link)
+}
+
+// UpdateShortLink handles enabling, disabling and changing the expiry of a short link
+// @Summary Update a short link
+// @Description Enable or disable a short link, or change or remove (no_expiry) its expiry date
+// @Tags Short Links
+// @Accept json
+// @Produce json
+// @Param id path int true "Short link ID"
+// @Param link body services.ShortLinkChanges true "Changes to the short link"
+// @Success 200 {object} services.ShortLinkResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/links/{id} [put]
+func UpdateShortLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var request services.ShortLinkChanges
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	link, err := services.UpdateShortLink(config.DB, id, request, time.Now())
+	if err != nil {
+		respondShortLinkError(c, err, "Error updating short link")
+		return
+	}
+	attachShortURL(c, &link)
+	c.JSON(http.StatusOK, services.ShortLinkResponse{Message: "Short link successfully updated", Data: link})
+}
+
+// DeleteShortLink handles deleting a short link
+// @Summary Delete a short link
+// @Description Delete a specific short link. Its code stops redirecting and can be used again.
+// @Tags Short Links
+// @Produce json
+// @Param id path int true "Short link ID"
+// @Success 200 {object} services.SuccessMessage
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/links/{id} [delete]
+func DeleteShortLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteShortLink(config.DB, id); err != nil {
+		respondShortLinkError(c, err, "Error deleting short link")
+		return
+	}
+	c.JSON(http.StatusOK, services.SuccessMessage{Message: "Short link successfully deleted"})
+}
+
+// FollowShortLink handles redirecting a short link to its target
+// @Summary Follow a short link
+// @Description Redirect to the target of a short link and count the click. Disabled links answer 404 and expired links 410.
+// @Tags Short Links
+// @Param code path string true "Short code"
+// @Success 302 "Location is the target URL"
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 410 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /s/{code} [get]
+func FollowShortLink(c *gin.Context) {
+	link, err := services.FollowShortLink(config.DB, c.Param("code"), time.Now())
+	if err != nil {
+		respondShortLinkError(c, err, "Error following short link")
+		return
+	}
+
+	// Redirects are not cached so that every click reaches the counter.
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, link.TargetURL)
+}
+
+// attachShortURL sets the public URL of link.
+func attachShortURL(c *gin.Context, link *models.ShortLink) {
+	link.ShortURL = publicBaseURL(c, config.ShortLinkBaseURL()) + "/s/" + link.Code
+}
+
+// respondShortLinkError maps errors from the short link service, including
+// invalid target URLs, onto HTTP responses.
+func respondShortLinkError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, services.ErrInvalidURL) || errors.Is(err, services.ErrUnknownURLOperation) {
+		respondURLError(c, err)
+		return
+	}
+	respondServiceError(c, err, fallback, shortLinkErrorResponses)
+}
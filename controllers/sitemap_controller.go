@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/services"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var sitemapErrorResponses = []errorMapping{
+	{services.ErrSitemapNotFound, http.StatusNotFound, "Sitemap not found"},
+	{services.ErrInvalidSitemapTemplate, http.StatusInternalServerError, "Sitemap book URL template must contain {id} or {slug}"},
+	{services.ErrBookWithoutSlug, http.StatusInternalServerError, "Sitemap book has no slug"},
+	{services.ErrUnknownURLOperation, http.StatusInternalServerError, "Sitemap URL operations are not valid"},
+	{services.ErrSitemapURLNotCanonical, http.StatusInternalServerError, "Sitemap book URL cannot be canonicalized"},
+}
+
+// GetSitemap handles serving the sitemap of the book catalog
+// @Summary Get the sitemap
+// @Description List the canonical URL of every book with its last update. Past SITEMAP_MAX_URLS books a sitemap index pointing at /sitemaps/{file} is returned instead. /sitemap.xml.gz serves the same document gzipped.
+// @Tags Sitemap
+// @Produce xml
+// @Success 200 {object} services.SitemapURLSet
+// @Failure 500 {object} services.ErrorResponse
+// @Router /sitemap.xml [get]
+// @Router /sitemap.xml.gz [get]
+func GetSitemap(c *gin.Context) {
+	gzipped := strings.HasSuffix(c.Request.URL.Path, ".gz")
+	sitemap := services.DefaultBookSitemap()
+
+	pages, err := sitemap.Pages(config.DB)
+	if err != nil {
+		respondSitemapError(c, err)
+		return
+	}
+	if pages == 1 {
+		urlSet, err := sitemap.URLSet(config.DB, 1)
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		writeSitemap(c, urlSet, gzipped)
+		return
+	}
+
+	base := publicBaseURL(c, config.SitemapBaseURL())
+	index, err := sitemap.Index(config.DB, func(page int) string {
+		if gzipped {
+			return fmt.Sprintf("%s/sitemaps/%d.xml.gz", base, page)
+		}
+		return fmt.Sprintf("%s/sitemaps/%d.xml", base, page)
+	})
+	if err != nil {
+		respondSitemapError(c, err)
+		return
+	}
+	writeSitemap(c, index, gzipped)
+}
+
+// GetSitemapPage handles serving one file of a split sitemap
+// @Summary Get a sitemap file
+// @Description Get one file of a sitemap split behind a sitemap index, e.g. 2.xml or 2.xml.gz
+// @Tags Sitemap
+// @Produce xml
+// @Param file path string true "Page number followed by .xml or .xml.gz"
+// @Success 200 {object} services.SitemapURLSet
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /sitemaps/{file} [get]
+func GetSitemapPage(c *gin.Context) {
+	file := c.Param("file")
+	name, gzipped := strings.CutSuffix(file, ".gz")
+	name, isXML := strings.CutSuffix(name, ".xml")
+	page, err := strconv.Atoi(name)
+	if !isXML || err != nil {
+		respondSitemapError(c, services.ErrSitemapNotFound)
+		return
+	}
+
+	urlSet, err := services.DefaultBookSitemap().URLSet(config.DB, page)
+	if err != nil {
+		respondSitemapError(c, err)
+		return
+	}
+	writeSitemap(c, urlSet, gzipped)
+}
+
+// publicBaseURL returns the configured base URL, or the scheme and host the
+// request was made to when it is empty. X-Forwarded-Proto and
+// X-Forwarded-Host are only used when the request comes from one of the
+// trusted proxies; release builds refuse to start without a configured base
+// URL, so this fallback is meant for development.
+func publicBaseURL(c *gin.Context, configured string) string {
+	if base := configured; base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	host := c.Request.Host
+	if trustedProxy(c.RemoteIP()) {
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwarded, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Host"), ","); strings.TrimSpace(forwarded) != "" {
+			host = strings.TrimSpace(forwarded)
+		}
+	}
+	return scheme + "://" + host
+}
+
+// trustedProxy reports whether ip is one of the configured trusted proxies.
+func trustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range config.TrustedProxies() {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+		} else if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSitemap answers with document as XML, gzipped when asked to.
+func writeSitemap(c *gin.Context, document interface{}, gzipped bool) {
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		respondSitemapError(c, err)
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	if !gzipped {
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+		return
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		respondSitemapError(c, err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		respondSitemapError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/gzip", compressed.Bytes())
+}
+
+// respondSitemapError maps errors from the sitemap service onto HTTP responses.
+func respondSitemapError(c *gin.Context, err error) {
+	respondServiceError(c, err, "Error generating sitemap", sitemapErrorResponses)
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferRequest struct {
+	CopyID     uint `json:"copy_id" binding:"required" example:"1"`
+	ToBranchID uint `json:"to_branch_id" binding:"required" example:"2"`
+}
+
+type ReceiveTransferRequest struct {
+	Location string `json:"location" example:"Shelf C2"`
+}
+
+// RequestTransfer handles asking for a copy to move to another branch
+// @Summary Request an inter-branch transfer
+// @Description Ask for a copy to be moved to another branch. The transfer starts in the requested status.
+// @Tags Transfers
+// @Accept json
+// @Produce json
+// @Param transfer body TransferRequest true "Copy and destination branch"
+// @Success 201 {object} services.TransferResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/transfers [post]
+func RequestTransfer(c *gin.Context) {
+	var request TransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	transfer, err := services.RequestTransfer(config.DB, request.CopyID, request.ToBranchID)
+	if err != nil {
+		respondTransferError(c, err, "Error requesting transfer")
+		return
+	}
+	c.JSON(http.StatusCreated, services.TransferResponse{Message: "Transfer requested successfully", Data: transfer})
+}
+
+// GetTransfers handles listing inter-branch transfers
+// @Summary Get transfers
+// @Description Get inter-branch transfers with pagination, optionally filtered by status and branch
+// @Tags Transfers
+// @Produce json
+// @Param status query string false "Transfer status" Enums(requested, in_transit, received, cancelled)
+// @Param branch query int false "Branch the copy leaves or goes to"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of items per page" default(10)
+// @Success 200 {object} services.TransferListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/transfers [get]
+func GetTransfers(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.Transfer{})
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.TransferStatusRequested, models.TransferStatusInTransit, models.TransferStatusReceived, models.TransferStatusCancelled:
+		query = query.Where("status = ?", status)
+	default:
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid status parameter. Status must be one of requested, in_transit, received, cancelled"})
+		return
+	}
+
+	if branchStr := c.Query("branch"); branchStr != "" {
+		branchID, err := strconv.Atoi(branchStr)
+		if err != nil || branchID < 1 {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid branch parameter. Branch must be a positive integer"})
+			return
+		}
+		query = query.Where("from_branch_id = ? OR to_branch_id = ?", branchID, branchID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		config.Log.WithError(err).Error("Error counting transfers")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting transfers"})
+		return
+	}
+
+	var transfers []models.Transfer
+	if err := query.Preload("Copy").Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&transfers).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching transfers")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.TransferListResponse{
+		Data:       transfers,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+// ShipTransfer handles sending a copy to its destination branch
+// @Summary Ship a transfer
+// @Description Move a requested transfer to in transit. The copy must be on the shelf and leaves circulation until it is received.
+// @Tags Transfers
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Success 200 {object} services.TransferResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/transfers/{id}/ship [post]
+func ShipTransfer(c *gin.Context) {
+	transferID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := services.ShipTransfer(config.DB, transferID, time.Now())
+	if err != nil {
+		respondTransferError(c, err, "Error shipping transfer")
+		return
+	}
+	c.JSON(http.StatusOK, services.TransferResponse{Message: "Transfer shipped successfully", Data: transfer})
+}
+
+// ReceiveTransfer handles the arrival of a copy at its destination branch
+// @Summary Receive a transfer
+// @Description Record the arrival of a copy at its new branch and shelf location. The copy goes to the next hold or back on the shelf.
+// @Tags Transfers
+// @Accept json
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Param receive body ReceiveTransferRequest false "Shelf location at the new branch"
+// @Success 200 {object} services.TransferResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/transfers/{id}/receive [post]
+func ReceiveTransfer(c *gin.Context) {
+	transferID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var request ReceiveTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			config.Log.WithError(err).Error("Invalid input")
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+			return
+		}
+	}
+
+	transfer, err := services.ReceiveTransfer(config.DB, transferID, request.Location, time.Now())
+	if err != nil {
+		respondTransferError(c, err, "Error receiving transfer")
+		return
+	}
+	c.JSON(http.StatusOK, services.TransferResponse{Message: "Transfer received successfully", Data: transfer})
+}
+
+// CancelTransfer handles withdrawing a transfer that has not shipped
+// @Summary Cancel a transfer
+// @Description Cancel a transfer that is still in the requested status
+// @Tags Transfers
+// @Produce json
+// @Param id path int true "Transfer ID"
+// @Success 200 {object} services.TransferResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/transfers/{id}/cancel [post]
+func CancelTransfer(c *gin.Context) {
+	transferID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := services.CancelTransfer(config.DB, transferID)
+	if err != nil {
+		respondTransferError(c, err, "Error cancelling transfer")
+		return
+	}
+	c.JSON(http.StatusOK, services.TransferResponse{Message: "Transfer successfully cancelled", Data: transfer})
+}
+
+var transferErrorResponses = []errorMapping{
+	{services.ErrTransferNotFound, http.StatusNotFound, "Transfer not found"},
+	{services.ErrCopyNotFound, http.StatusNotFound, "Copy not found"},
+	{services.ErrBranchNotFound, http.StatusNotFound, "Branch not found"},
+	{services.ErrCopyWithoutBranch, http.StatusBadRequest, "Copy is not assigned to a branch"},
+	{services.ErrTransferSameBranch, http.StatusBadRequest, "Copy is already held by the destination branch"},
+	{services.ErrTransferPending, http.StatusConflict, "Copy already has a pending transfer"},
+	{services.ErrTransferStatus, http.StatusConflict, "Transfer cannot move to this status"},
+	{services.ErrCopyNotOnShelf, http.StatusConflict, "Copy must be on the shelf to be shipped"},
+}
+
+// respondTransferError maps errors from the transfer service onto HTTP responses.
+func respondTransferError(c *gin.Context, err error, fallback string) {
+	respondServiceError(c, err, fallback, transferErrorResponses)
+}
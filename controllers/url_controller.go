@@ -3,40 +3,503 @@ package controllers
 import (
 	"byfood-test-backend/config"
 	"byfood-test-backend/services"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// URLRequest names either a single operation or a chain of operations. When
+// both are given, operation runs first.
 type URLRequest struct {
-	URL       string `json:"url" binding:"required"`
-	Operation string `json:"operation" binding:"required"`
+	URL        string   `json:"url" binding:"required"`
+	Operation  string   `json:"operation" example:"canonical"`
+	Operations []string `json:"operations" example:"strip_tracking,lowercase_path,force_host"`
+}
+
+// operations returns the operations to apply in order.
+func (r URLRequest) operations() []string {
+	if r.Operation == "" {
+		return r.Operations
+	}
+	return append([]string{r.Operation}, r.Operations...)
 }
 
 // ProcessURL godoc
 // @Summary Process a URL
-// @Description Process a URL for canonicalization or redirection
+// @Description Process a URL with one operation, e.g. canonical, redirection, clean, normalize or all, or with a chain of operations applied in order
 // @Tags URL Cleanup
 // @Accept json
 // @Produce json
 // @Param url body URLRequest true "URL and Operation"
+// @Param explain query bool false "Return a services.URLExplanation listing every rule that changed the URL"
+// @Param hreflang query bool false "Add the hreflang alternates of the processed URL for every configured locale"
 // @Success 200 {object} services.SuccessProcessURL
 // @Failure 400 {object} services.ErrorResponse
 // @Router /api/process_url [post]
 func ProcessURL(c *gin.Context) {
 	var request URLRequest
+	if err := c.ShouldBindJSON(&request); err != nil || len(request.operations()) == 0 {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	operations := request.operations()
+	explanation, err := services.ExplainURL(request.URL, operations...)
+	if err == nil && c.DefaultQuery("hreflang", "false") == "true" {
+		explanation.Alternates, err = services.LocaleAlternates(explanation.ProcessedURL, operations...)
+	}
+	if err != nil {
+		respondURLError(c, err)
+		return
+	}
+	recordURL(request.URL, operations, explanation)
+
+	if c.DefaultQuery("explain", "false") == "true" {
+		c.JSON(http.StatusOK, explanation)
+		return
+	}
+	c.JSON(http.StatusOK, services.SuccessProcessURL{ProcessedUrl: explanation.ProcessedURL, Alternates: explanation.Alternates})
+}
+
+// recordURL queues a processed URL for the statistics when they are enabled.
+// It is stored in the background, so failing to do so does not fail the
+// request.
+func recordURL(inputURL string, operations []string, explanation services.URLExplanation) {
+	if services.URLRecords == nil {
+		return
+	}
+	services.URLRecords.Record(services.NewURLRecord(inputURL, operations, explanation))
+}
+
+// GetURLStats godoc
+// @Summary URL processing statistics
+// @Description Summarize the requests made to /api/process_url: the top hosts, operations and transformations (the rules that changed the URL), the percentage of URLs that were already canonical and the number of requests per hour, day or week. Requests are kept for URL_STATS_RETENTION_DAYS days.
+// @Tags URL Cleanup
+// @Produce json
+// @Param from query string false "Start of the range, RFC 3339 or YYYY-MM-DD (default 7 days before to)"
+// @Param to query string false "End of the range, excluded, RFC 3339 or YYYY-MM-DD (default now)"
+// @Param bucket query string false "Bucket size: hour, day or week" default(day)
+// @Param limit query int false "Entries in every top list" default(10)
+// @Success 200 {object} services.URLStats
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/process_url/stats [get]
+func GetURLStats(c *gin.Context) {
+	to, ok := parseTimeQuery(c, "to", time.Now())
+	if !ok {
+		return
+	}
+	from, ok := parseTimeQuery(c, "from", to.AddDate(0, 0, -7))
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid limit parameter. Limit must be between 1 and 100"})
+		return
+	}
+
+	stats, err := services.GetURLStats(config.DB, services.URLStatsQuery{
+		From:   from,
+		To:     to,
+		Bucket: c.DefaultQuery("bucket", services.URLStatsBucketDay),
+		Limit:  limit,
+	})
+	if err != nil {
+		respondServiceError(c, err, "Error computing url statistics", urlStatsErrorResponses)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+var urlStatsErrorResponses = []errorMapping{
+	{services.ErrInvalidURLStatsBucket, http.StatusBadRequest, "Invalid bucket parameter. Bucket must be hour, day or week"},
+	{services.ErrInvalidURLStatsRange, http.StatusBadRequest, "Invalid range. To must be after from"},
+}
+
+// parseTimeQuery reads an RFC 3339 time or a YYYY-MM-DD date (midnight UTC)
+// from the query, using fallback when it is absent. On invalid input it
+// writes a 400 response and returns ok=false.
+func parseTimeQuery(c *gin.Context, name string, fallback time.Time) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true
+	}
+	c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: fmt.Sprintf("Invalid %s parameter. Use RFC 3339 or YYYY-MM-DD", name)})
+	return time.Time{}, false
+}
+
+// URLBatchRequest runs the same operations over many URLs.
+type URLBatchRequest struct {
+	URLs       []string `json:"urls" binding:"required"`
+	Operation  string   `json:"operation" example:"canonical"`
+	Operations []string `json:"operations" example:"strip_tracking,lowercase_path"`
+}
+
+// ProcessURLBatch godoc
+// @Summary Process a batch of URLs
+// @Description Process up to URL_BATCH_MAX_URLS URLs concurrently. Send JSON, or a multipart form with a CSV or text "file" and "operation" or comma separated "operations" fields. Results keep the input order and carry either the processed URL or an error.
+// @Tags URL Cleanup
+// @Accept json
+// @Accept multipart/form-data
+// @Produce json
+// @Param batch body URLBatchRequest false "URLs and operations"
+// @Param file formData file false "CSV file with a url column, or one URL per line"
+// @Param operation formData string false "Operation"
+// @Param operations formData string false "Comma separated operations"
+// @Success 200 {object} services.URLBatchResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Router /api/process_url/batch [post]
+func ProcessURLBatch(c *gin.Context) {
+	var request URLBatchRequest
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		urls, err := readBatchFile(c)
+		if errors.Is(err, services.ErrURLBatchTooLarge) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: fmt.Sprintf("A batch can hold at most %d URLs", config.URLBatchMaxURLs())})
+			return
+		}
+		if err != nil {
+			config.Log.WithError(err).Error("Invalid input")
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+			return
+		}
+		request = URLBatchRequest{URLs: urls, Operation: c.PostForm("operation"), Operations: splitOperations(c.PostFormArray("operations"))}
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	operations := URLRequest{Operation: request.Operation, Operations: request.Operations}.operations()
+	if len(operations) == 0 {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	results, err := services.ProcessURLBatch(request.URLs, operations, config.URLBatchMaxURLs(), config.URLBatchWorkers())
+	if err != nil {
+		if errors.Is(err, services.ErrURLBatchTooLarge) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: fmt.Sprintf("A batch can hold at most %d URLs", config.URLBatchMaxURLs())})
+			return
+		}
+		if errors.Is(err, services.ErrEmptyURLBatch) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "The batch holds no URLs"})
+			return
+		}
+		respondURLError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.URLBatchResponse{Data: results})
+}
+
+// URLCompareRequest lists the URLs to compare and the operations that
+// canonicalize them.
+type URLCompareRequest struct {
+	URLs       []string `json:"urls" binding:"required" example:"https://byfood.com/food-experiences/,https://www.byfood.com/food-experiences?utm_source=x"`
+	Operation  string   `json:"operation" example:"all"`
+	Operations []string `json:"operations" example:"normalize,strip_tracking"`
+}
+
+// CompareURLs godoc
+// @Summary Compare URLs
+// @Description Process two or more URLs with the same operations and report whether they are equivalent, grouping them into clusters that share a processed URL
+// @Tags URL Cleanup
+// @Accept json
+// @Produce json
+// @Param compare body URLCompareRequest true "URLs and operations"
+// @Success 200 {object} services.URLCompareResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Router /api/process_url/compare [post]
+func CompareURLs(c *gin.Context) {
+	var request URLCompareRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	operations := URLRequest{Operation: request.Operation, Operations: request.Operations}.operations()
+	if len(operations) == 0 {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	comparison, err := services.CompareURLs(request.URLs, operations, config.URLBatchMaxURLs(), config.URLBatchWorkers())
+	if err != nil {
+		if errors.Is(err, services.ErrTooFewURLsToCompare) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "At least two URLs are needed to compare"})
+			return
+		}
+		if errors.Is(err, services.ErrURLBatchTooLarge) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: fmt.Sprintf("At most %d URLs can be compared at once", config.URLBatchMaxURLs())})
+			return
+		}
+		respondURLError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.URLCompareResponse{Data: comparison})
+}
+
+// readBatchFile reads the URLs of the uploaded "file" form field, stopping
+// once it holds more than URL_BATCH_MAX_URLS.
+func readBatchFile(c *gin.Context) ([]string, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	csvFormat := strings.EqualFold(filepath.Ext(header.Filename), ".csv") ||
+		strings.HasPrefix(header.Header.Get("Content-Type"), "text/csv")
+	return services.ReadURLList(file, csvFormat, config.URLBatchMaxURLs())
+}
+
+// CanonicalCheckRequest holds either one document in URL and HTML or a batch
+// in Documents.
+type CanonicalCheckRequest struct {
+	URL        string                  `json:"url" example:"https://www.byfood.com/food-experiences?page=2"`
+	HTML       string                  `json:"html" example:"<link rel=\"canonical\" href=\"/food-experiences\">"`
+	Documents  []services.HTMLDocument `json:"documents"`
+	Operation  string                  `json:"operation" example:"seo"`
+	Operations []string                `json:"operations" example:"normalize,canonical"`
+}
+
+// CheckCanonicalTags godoc
+// @Summary Check canonical tags of HTML documents
+// @Description Extract the rel="canonical", hreflang alternate and og:url tags of one or more HTML documents and report where they disagree with the canonical URL computed from the document URL. The operations default to SITEMAP_URL_OPERATIONS.
+// @Tags URL Cleanup
+// @Accept json
+// @Produce json
+// @Param documents body CanonicalCheckRequest true "Document URL and HTML, or a batch of documents"
+// @Success 200 {object} services.CanonicalCheckResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Router /api/process_url/canonical-check [post]
+func CheckCanonicalTags(c *gin.Context) {
+	var request CanonicalCheckRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		config.Log.WithError(err).Error("Invalid input")
 		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
 		return
 	}
 
-	processedURL, err := services.ProcessURL(request.URL, request.Operation)
+	documents := request.Documents
+	if request.URL != "" {
+		documents = append([]services.HTMLDocument{{URL: request.URL, HTML: request.HTML}}, documents...)
+	}
+	operations := URLRequest{Operation: request.Operation, Operations: request.Operations}.operations()
+	if len(operations) == 0 {
+		operations = config.SitemapURLOperations()
+	}
+
+	checks, err := services.CheckCanonicalTags(documents, operations, config.URLBatchMaxURLs())
+	if err != nil {
+		if errors.Is(err, services.ErrNoHTMLDocuments) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "No documents to check"})
+			return
+		}
+		if errors.Is(err, services.ErrTooManyHTMLDocuments) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: fmt.Sprintf("At most %d documents can be checked at once", config.URLBatchMaxURLs())})
+			return
+		}
+		respondURLError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.CanonicalCheckResponse{Data: checks})
+}
+
+// splitOperations flattens comma separated operation lists.
+func splitOperations(values []string) []string {
+	var operations []string
+	for _, value := range values {
+		for _, operation := range strings.Split(value, ",") {
+			if operation = strings.TrimSpace(operation); operation != "" {
+				operations = append(operations, operation)
+			}
+		}
+	}
+	return operations
+}
+
+// AuditSitemap godoc
+// @Summary Audit a sitemap
+// @Description Check every <loc> of an uploaded sitemap or sitemap index, optionally gzipped, against the URL operations and the stored redirect rules. Reports the URLs that are not canonical, that duplicate another URL once canonicalized, or that a redirect rule sends elsewhere. For a sitemap index only the sitemap file URLs it lists are checked; the files themselves are not fetched. The operations default to SITEMAP_URL_OPERATIONS. Sitemaps larger than SITEMAP_AUDIT_MAX_BYTES, uploaded or decompressed, are rejected with a 413.
+// @Tags URL Cleanup
+// @Accept multipart/form-data
+// @Accept xml
+// @Produce json
+// @Param file formData file false "Sitemap or sitemap index; the request body is used when there is no file"
+// @Param operations query string false "Comma separated operations"
+// @Success 200 {object} services.SitemapAuditResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 413 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/process_url/sitemap-audit [post]
+func AuditSitemap(c *gin.Context) {
+	maxBytes := config.SitemapAuditMaxBytes()
+	data, err := readSitemapUpload(c, maxBytes)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, services.ErrorResponse{Error: fmt.Sprintf("A sitemap can be at most %d bytes", maxBytes)})
+			return
+		}
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	operations := splitOperations(c.QueryArray("operations"))
+	if len(operations) == 0 {
+		operations = config.SitemapURLOperations()
+	}
+
+	rules, err := services.EnabledRedirectRules(config.DB)
+	if err != nil {
+		config.Log.WithError(err).Error("Error fetching redirect rules")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching redirect rules"})
+		return
+	}
+
+	audit, err := services.AuditSitemap(data, operations, rules, config.RedirectMaxHops(), config.SitemapMaxURLs(), maxBytes)
+	if err != nil {
+		if errors.Is(err, services.ErrSitemapFileTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, services.ErrorResponse{Error: fmt.Sprintf("A sitemap can be at most %d bytes", maxBytes)})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidSitemap) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "File is not a sitemap or sitemap index"})
+			return
+		}
+		if errors.Is(err, services.ErrSitemapTooLarge) {
+			c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: fmt.Sprintf("A sitemap can list at most %d URLs", config.SitemapMaxURLs())})
+			return
+		}
+		respondURLError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.SitemapAuditResponse{Data: audit})
+}
+
+// readSitemapUpload reads the uploaded "file" form field, or the request body
+// when the request is not a form. Bodies larger than maxBytes fail with an
+// *http.MaxBytesError.
+func readSitemapUpload(c *gin.Context, maxBytes int64) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return io.ReadAll(c.Request.Body)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	file, err := header.Open()
 	if err != nil {
-		config.Log.WithError(err).Error("Error processing URL")
-		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error processing URL"})
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// GetURLOperations godoc
+// @Summary List URL operations
+// @Description List the built-in operations and rule sets that can be used by /api/process_url
+// @Tags URL Cleanup
+// @Produce json
+// @Success 200 {object} services.URLOperationsResponse
+// @Router /api/process_url/operations [get]
+func GetURLOperations(c *gin.Context) {
+	c.JSON(http.StatusOK, services.URLOperationsResponse{Data: services.URLOperations.Operations()})
+}
+
+// respondURLError answers unknown operations with a 400 listing the valid
+// ones, rejected URLs with a 400 giving the reason and anything else with a
+// 500.
+func respondURLError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidURL) {
+		config.Log.WithError(err).Error("Invalid URL")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid URL: " + err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrUnknownURLOperation) {
+		config.Log.WithError(err).Error("Unknown operation")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{
+			Error: "Unknown operation, valid operations are: " + strings.Join(services.URLOperations.Names(), ", "),
+		})
+		return
+	}
+	config.Log.WithError(err).Error("Error processing URL")
+	c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error processing URL"})
+}
+
+// GetURLRuleSets godoc
+// @Summary List URL rule sets
+// @Description List the rule sets that can be used as the operation of /api/process_url
+// @Tags URL Cleanup
+// @Produce json
+// @Success 200 {object} services.URLRuleSetsResponse
+// @Router /api/process_url/rules [get]
+func GetURLRuleSets(c *gin.Context) {
+	c.JSON(http.StatusOK, services.URLRuleSetsResponse{
+		Data:     services.URLRules.RuleSets(),
+		LoadedAt: services.URLRules.LoadedAt(),
+	})
+}
+
+// ReloadURLRules godoc
+// @Summary Reload URL rule sets
+// @Description Reload the URL rule sets from the configured rules file without restarting the server. Requires the URL_RULES_RELOAD_TOKEN as a bearer token; the endpoint is disabled when no token is configured.
+// @Tags URL Cleanup
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} services.URLRuleSetsResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/process_url/rules/reload [post]
+func ReloadURLRules(c *gin.Context) {
+	token := config.URLRulesReloadToken()
+	if token == "" {
+		c.JSON(http.StatusForbidden, services.ErrorResponse{Error: "Reloading url rules is disabled"})
+		return
+	}
+	given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, services.ErrorResponse{Error: "Invalid or missing token"})
+		return
+	}
+
+	if err := services.URLRules.Reload(); err != nil {
+		config.Log.WithError(err).Error("Error reloading url rules")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error reloading url rules"})
 		return
 	}
 
-	c.JSON(http.StatusOK, services.SuccessProcessURL{ProcessedUrl: processedURL})
+	c.JSON(http.StatusOK, services.URLRuleSetsResponse{
+		Data:     services.URLRules.RuleSets(),
+		LoadedAt: services.URLRules.LoadedAt(),
+	})
 }
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddUser handles registering a new library member
+// @Summary Add a new user
+// @Description Register a new library member who can borrow books. The membership tier must be standard or one configured in FINE_TIER_OVERRIDES.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param user body models.User true "User to add"
+// @Success 201 {object} services.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/users [post]
+func AddUser(c *gin.Context) {
+	var user models.User
+	if err := c.ShouldBindJSON(&user); err != nil {
+		config.Log.WithError(err).Error("Invalid input")
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Invalid input"})
+		return
+	}
+
+	if user.Name == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Name cannot be empty"})
+		return
+	}
+
+	if user.Email == "" {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Email cannot be empty"})
+		return
+	}
+
+	if user.MembershipTier == "" {
+		user.MembershipTier = models.MembershipTierStandard
+	}
+
+	if !config.ValidMembershipTier(user.MembershipTier) {
+		c.JSON(http.StatusBadRequest, services.ErrorResponse{Error: "Unknown membership tier"})
+		return
+	}
+
+	if err := config.DB.Create(&user).Error; err != nil {
+		config.Log.WithError(err).Error("Error adding user")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error adding user"})
+		return
+	}
+	c.JSON(http.StatusCreated, services.UserResponse{Message: "User created successfully", Data: user})
+}
+
+// GetUsers handles listing library members
+// @Summary Get all users
+// @Description Get all library members with pagination
+// @Tags Users
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Number of items per page" default(10)
+// @Success 200 {object} services.UserListResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 500 {object} services.ErrorResponse
+// @Router /api/users [get]
+func GetUsers(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.User{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		config.Log.WithError(err).Error("Error counting users")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error counting users"})
+		return
+	}
+
+	var users []models.User
+	if err := query.Order("name ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&users).Error; err != nil {
+		config.Log.WithError(err).Error("Error fetching users")
+		c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.UserListResponse{
+		Data:       users,
+		Pagination: services.Pagination{Limit: pageSize, Page: page, TotalCount: total},
+	})
+}
+
+// GetUserByID handles retrieving a library member by ID
+// @Summary Get a user by ID
+// @Description Get details of a specific library member
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/users/{id} [get]
+func GetUserByID(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			config.Log.WithError(err).Error("User not found")
+			c.JSON(http.StatusNotFound, services.ErrorResponse{Error: "User not found"})
+		} else {
+			config.Log.WithError(err).Error("Error fetching user")
+			c.JSON(http.StatusInternalServerError, services.ErrorResponse{Error: "Error fetching user"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -39,6 +39,24 @@ const docTemplate = `{
                         "description": "Number of items per page",
                         "name": "pageSize",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search term matched against title, author and year",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return books with at least one copy available",
+                        "name": "available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only consider copies held by this branch",
+                        "name": "branch",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -107,6 +125,50 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/books/by-slug/{slug}": {
+            "get": {
+                "description": "Get details of a specific book by its slug. An old slug of a book redirects to its current slug",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Get a book by slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Book slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Book"
+                        }
+                    },
+                    "301": {
+                        "description": "Old slug, Location is the current slug"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/books/{id}": {
             "get": {
                 "description": "Get details of a specific book by its ID",
@@ -205,7 +267,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a specific book by its ID",
+                "description": "Delete a specific book by its ID together with its copies. Books with open loans, active holds or copies in transit cannot be deleted.",
                 "produces": [
                     "application/json"
                 ],
@@ -240,13 +302,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/services.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/process_url": {
+        "/api/books/{id}/checkout": {
             "post": {
-                "description": "Process a URL for canonicalization or redirection",
+                "description": "Lend a copy of a book to a user. Fails when no copy is available or the user's outstanding fines are above the threshold.",
                 "consumes": [
                     "application/json"
                 ],
@@ -254,25 +328,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "URL Cleanup"
+                    "Loans"
                 ],
-                "summary": "Process a URL",
+                "summary": "Check out a book",
                 "parameters": [
                     {
-                        "description": "URL and Operation",
-                        "name": "url",
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Borrower",
+                        "name": "checkout",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.URLRequest"
+                            "$ref": "#/definitions/controllers.CheckoutRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/services.SuccessProcessURL"
+                            "$ref": "#/definitions/services.LoanResponse"
                         }
                     },
                     "400": {
@@ -280,104 +361,4250 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/services.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.URLRequest": {
-            "type": "object",
-            "required": [
-                "operation",
-                "url"
-            ],
-            "properties": {
-                "operation": {
-                    "type": "string"
-                },
-                "url": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.Book": {
-            "type": "object",
-            "properties": {
-                "author": {
-                    "type": "string",
-                    "example": "F. Scott Fitzgerald"
-                },
-                "created_at": {
-                    "type": "string",
-                    "example": "2023-01-01T00:00:00Z"
-                },
-                "deleted_at": {
-                    "type": "string",
-                    "example": "2023-01-03T00:00:00Z"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "title": {
-                    "type": "string",
-                    "example": "The Great Gatsby"
-                },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2023-01-02T00:00:00Z"
-                },
-                "year": {
-                    "type": "integer",
-                    "example": 1925
-                }
-            }
         },
-        "services.BookListResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Book"
+        "/api/books/{id}/copies": {
+            "get": {
+                "description": "Get every physical copy of a specific book",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Copies"
+                ],
+                "summary": "Get the copies of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only list copies held by this branch",
+                        "name": "branch",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.CopyListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
                     }
-                },
-                "pagination": {
-                    "$ref": "#/definitions/services.Pagination"
                 }
-            }
-        },
-        "services.BookResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/models.Book"
-                },
-                "message": {
-                    "type": "string"
+            },
+            "post": {
+                "description": "Register a new physical copy of a specific book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Copies"
+                ],
+                "summary": "Add a copy of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy to add",
+                        "name": "copy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CopyChanges"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.CopyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "services.ErrorResponse": {
-            "type": "object",
+        "/api/books/{id}/copies/{copyId}": {
+            "put": {
+                "description": "Update the branch, barcode, condition, location or status of a copy. Copies on loan, on hold or in transit only change status through returns, holds and transfers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Copies"
+                ],
+                "summary": "Update a copy of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "copyId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy data to update",
+                        "name": "copy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CopyChanges"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.CopyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a copy that is not on loan, set aside for a hold or in transit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Copies"
+                ],
+                "summary": "Delete a copy of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "copyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/books/{id}/holds": {
+            "post": {
+                "description": "Join the queue for a book whose copies are all out. When a copy comes back it is set aside for the oldest hold until its pickup deadline.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Place a hold on a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User placing the hold",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/branches": {
+            "get": {
+                "description": "Get every library branch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Branches"
+                ],
+                "summary": "Get all branches",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BranchListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a library branch that holds copies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Branches"
+                ],
+                "summary": "Add a new branch",
+                "parameters": [
+                    {
+                        "description": "Branch to add",
+                        "name": "branch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Branch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.BranchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/branches/{id}": {
+            "get": {
+                "description": "Get details of a specific library branch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Branches"
+                ],
+                "summary": "Get a branch by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Branch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/holds": {
+            "get": {
+                "description": "Get holds with their position in the queue. Only waiting and ready holds are listed unless a status is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Get holds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "waiting",
+                            "ready",
+                            "fulfilled",
+                            "expired",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Hold status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.HoldListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/holds/{id}": {
+            "delete": {
+                "description": "Cancel a waiting or ready hold. A copy set aside for it goes to the next hold in the queue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Cancel a hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/linkcheck": {
+            "post": {
+                "description": "Check URLs, redirect targets and the links on pages for broken links in the background. Poll /api/linkcheck/{job} for the results.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Link Check"
+                ],
+                "summary": "Start a link check",
+                "parameters": [
+                    {
+                        "description": "What to check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LinkCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/services.LinkCheckJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/linkcheck/{job}": {
+            "get": {
+                "description": "Get the progress of a link check job and a page of its results, optionally only the broken ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Link Check"
+                ],
+                "summary": "Get a link check job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "job",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only list broken links",
+                        "name": "broken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of results per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.LinkCheckResultsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/links": {
+            "get": {
+                "description": "Get the short links with their click counts, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Short Links"
+                ],
+                "summary": "Get all short links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only list enabled (true) or disabled (false) links",
+                        "name": "enabled",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ShortLinkListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Canonicalize a URL on one of SHORTLINK_ALLOWED_HOSTS with SHORTLINK_URL_OPERATIONS and return a short link to it, with a generated code unless a custom code is given. A URL that already has an active short link gets that link back.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Short Links"
+                ],
+                "summary": "Add a short link",
+                "parameters": [
+                    {
+                        "description": "URL to shorten",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ShortLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ShortLinkResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.ShortLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/links/{id}": {
+            "get": {
+                "description": "Get a specific short link with its click count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Short Links"
+                ],
+                "summary": "Get a short link by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Short link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShortLink"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Enable or disable a short link, or change or remove (no_expiry) its expiry date",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Short Links"
+                ],
+                "summary": "Update a short link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Short link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes to the short link",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ShortLinkChanges"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ShortLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a specific short link. Its code stops redirecting and can be used again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Short Links"
+                ],
+                "summary": "Delete a short link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Short link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loans": {
+            "get": {
+                "description": "Get loans with pagination, optionally filtered by status and borrower",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Get loans",
+                "parameters": [
+                    {
+                        "enum": [
+                            "active",
+                            "overdue",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "Loan status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Borrower ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.LoanListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loans/{id}/renew": {
+            "post": {
+                "description": "Extend the due date of an active loan by another loan period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Renew a loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.LoanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loans/{id}/return": {
+            "post": {
+                "description": "Mark a loan as returned, making the copy available again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Return a loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.LoanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url": {
+            "post": {
+                "description": "Process a URL with one operation, e.g. canonical, redirection, clean, normalize or all, or with a chain of operations applied in order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "Process a URL",
+                "parameters": [
+                    {
+                        "description": "URL and Operation",
+                        "name": "url",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.URLRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return a services.URLExplanation listing every rule that changed the URL",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add the hreflang alternates of the processed URL for every configured locale",
+                        "name": "hreflang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SuccessProcessURL"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/batch": {
+            "post": {
+                "description": "Process up to URL_BATCH_MAX_URLS URLs concurrently. Send JSON, or a multipart form with a CSV or text \"file\" and \"operation\" or comma separated \"operations\" fields. Results keep the input order and carry either the processed URL or an error.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "Process a batch of URLs",
+                "parameters": [
+                    {
+                        "description": "URLs and operations",
+                        "name": "batch",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.URLBatchRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file with a url column, or one URL per line",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated operations",
+                        "name": "operations",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.URLBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/canonical-check": {
+            "post": {
+                "description": "Extract the rel=\"canonical\", hreflang alternate and og:url tags of one or more HTML documents and report where they disagree with the canonical URL computed from the document URL. The operations default to SITEMAP_URL_OPERATIONS.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "Check canonical tags of HTML documents",
+                "parameters": [
+                    {
+                        "description": "Document URL and HTML, or a batch of documents",
+                        "name": "documents",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CanonicalCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.CanonicalCheckResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/compare": {
+            "post": {
+                "description": "Process two or more URLs with the same operations and report whether they are equivalent, grouping them into clusters that share a processed URL",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "Compare URLs",
+                "parameters": [
+                    {
+                        "description": "URLs and operations",
+                        "name": "compare",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.URLCompareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.URLCompareResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/operations": {
+            "get": {
+                "description": "List the built-in operations and rule sets that can be used by /api/process_url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "List URL operations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.URLOperationsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/rules": {
+            "get": {
+                "description": "List the rule sets that can be used as the operation of /api/process_url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "List URL rule sets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.URLRuleSetsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/rules/reload": {
+            "post": {
+                "description": "Reload the URL rule sets from the configured rules file without restarting the server. Requires the URL_RULES_RELOAD_TOKEN as a bearer token; the endpoint is disabled when no token is configured.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "Reload URL rule sets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.URLRuleSetsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/sitemap-audit": {
+            "post": {
+                "description": "Check every \u003cloc\u003e of an uploaded sitemap or sitemap index, optionally gzipped, against the URL operations and the stored redirect rules. Reports the URLs that are not canonical, that duplicate another URL once canonicalized, or that a redirect rule sends elsewhere. For a sitemap index only the sitemap file URLs it lists are checked; the files themselves are not fetched. The operations default to SITEMAP_URL_OPERATIONS. Sitemaps larger than SITEMAP_AUDIT_MAX_BYTES, uploaded or decompressed, are rejected with a 413.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/xml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "Audit a sitemap",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Sitemap or sitemap index; the request body is used when there is no file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated operations",
+                        "name": "operations",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SitemapAuditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/process_url/stats": {
+            "get": {
+                "description": "Summarize the requests made to /api/process_url: the top hosts, operations and transformations (the rules that changed the URL), the percentage of URLs that were already canonical and the number of requests per hour, day or week. Requests are kept for URL_STATS_RETENTION_DAYS days.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "URL Cleanup"
+                ],
+                "summary": "URL processing statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of the range, RFC 3339 or YYYY-MM-DD (default 7 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, excluded, RFC 3339 or YYYY-MM-DD (default now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "day",
+                        "description": "Bucket size: hour, day or week",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Entries in every top list",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.URLStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/redirects": {
+            "get": {
+                "description": "Get the redirect rules in the order they are tried, highest priority first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Get all redirect rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only list enabled (true) or disabled (false) rules",
+                        "name": "enabled",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectRuleListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a rule redirecting a path to a target. Rules default to an enabled 301 exact match. Rules closing a redirect loop are rejected and chains longer than REDIRECT_MAX_CHAIN_LENGTH are reported as warnings or, with flatten, shortened.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Add a redirect rule",
+                "parameters": [
+                    {
+                        "description": "Redirect rule to add",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectRuleChanges"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Re-point exact rules of too long chains straight at the final target",
+                        "name": "flatten",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/redirects/audit": {
+            "get": {
+                "description": "Report every chain of two or more hops and every loop among the enabled redirect rules. Chains longer than REDIRECT_MAX_CHAIN_LENGTH are flagged as too long.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Audit redirect rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectAuditResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/redirects/export": {
+            "get": {
+                "description": "Export the enabled redirect rules as an nginx map block, Apache RewriteRules or the redirects of a vercel.json. Rules the format cannot express are left out and reported in X-Redirect-Export-Warning headers.",
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Export redirect rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "nginx, apache or vercel",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Server configuration",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "X-Redirect-Export-Warning": {
+                                "type": "string",
+                                "description": "A rule that was left out of the export"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/redirects/import": {
+            "post": {
+                "description": "Parse an nginx, Apache or vercel.json configuration sent as the request body and store the redirects it holds. Lines that are not redirects, and rules that cannot be stored, are reported under unparsed.",
+                "consumes": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Import redirect rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "nginx, apache or vercel",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only parse the configuration without storing rules",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Re-point exact rules of too long chains straight at the final target",
+                        "name": "flatten",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Server configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/redirects/resolve": {
+            "get": {
+                "description": "Follow the enabled redirect rules from a URL or path and return the final target with every hop",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Resolve a redirect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL or path to resolve",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectResolution"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "508": {
+                        "description": "Loop Detected",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/redirects/{id}": {
+            "get": {
+                "description": "Get details of a specific redirect rule",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Get a redirect rule by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Redirect rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RedirectRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the source, target, status code, match type, priority or enabled flag of a redirect rule. Loops and chains are checked like when adding a rule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Update a redirect rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Redirect rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Redirect rule data to update",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectRuleChanges"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Re-point exact rules of too long chains straight at the final target",
+                        "name": "flatten",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.RedirectRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a specific redirect rule",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Redirects"
+                ],
+                "summary": "Delete a redirect rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Redirect rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfers": {
+            "get": {
+                "description": "Get inter-branch transfers with pagination, optionally filtered by status and branch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Get transfers",
+                "parameters": [
+                    {
+                        "enum": [
+                            "requested",
+                            "in_transit",
+                            "received",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Transfer status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Branch the copy leaves or goes to",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransferListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Ask for a copy to be moved to another branch. The transfer starts in the requested status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Request an inter-branch transfer",
+                "parameters": [
+                    {
+                        "description": "Copy and destination branch",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfers/{id}/cancel": {
+            "post": {
+                "description": "Cancel a transfer that is still in the requested status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Cancel a transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfers/{id}/receive": {
+            "post": {
+                "description": "Record the arrival of a copy at its new branch and shelf location. The copy goes to the next hold or back on the shelf.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Receive a transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shelf location at the new branch",
+                        "name": "receive",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReceiveTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfers/{id}/ship": {
+            "post": {
+                "description": "Move a requested transfer to in transit. The copy must be on the shelf and leaves circulation until it is received.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Ship a transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "description": "Get all library members with pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get all users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of items per page",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.UserListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a new library member who can borrow books. The membership tier must be standard or one configured in FINE_TIER_OVERRIDES.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Add a new user",
+                "parameters": [
+                    {
+                        "description": "User to add",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "get": {
+                "description": "Get details of a specific library member",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/fines": {
+            "get": {
+                "description": "Get the fine ledger of a user, its balance, the fines still accruing on overdue loans and whether checkouts are blocked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fines"
+                ],
+                "summary": "Get the fines of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.FineSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/fines/payments": {
+            "post": {
+                "description": "Record a payment or a waiver reducing the fine balance of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fines"
+                ],
+                "summary": "Record a fine payment or waiver",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment or waiver",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.FineEntryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.FineEntryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/s/{code}": {
+            "get": {
+                "description": "Redirect to the target of a short link and count the click. Disabled links answer 404 and expired links 410.",
+                "tags": [
+                    "Short Links"
+                ],
+                "summary": "Follow a short link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Location is the target URL"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemap.xml": {
+            "get": {
+                "description": "List the canonical URL of every book with its last update. Past SITEMAP_MAX_URLS books a sitemap index pointing at /sitemaps/{file} is returned instead. /sitemap.xml.gz serves the same document gzipped.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "Sitemap"
+                ],
+                "summary": "Get the sitemap",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SitemapURLSet"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemap.xml.gz": {
+            "get": {
+                "description": "List the canonical URL of every book with its last update. Past SITEMAP_MAX_URLS books a sitemap index pointing at /sitemaps/{file} is returned instead. /sitemap.xml.gz serves the same document gzipped.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "Sitemap"
+                ],
+                "summary": "Get the sitemap",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SitemapURLSet"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemaps/{file}": {
+            "get": {
+                "description": "Get one file of a sitemap split behind a sitemap index, e.g. 2.xml or 2.xml.gz",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "Sitemap"
+                ],
+                "summary": "Get a sitemap file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Page number followed by .xml or .xml.gz",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SitemapURLSet"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/services.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.CanonicalCheckRequest": {
+            "type": "object",
+            "properties": {
+                "documents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.HTMLDocument"
+                    }
+                },
+                "html": {
+                    "type": "string",
+                    "example": "\u003clink rel=\"canonical\" href=\"/food-experiences\"\u003e"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "seo"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "normalize",
+                        "canonical"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences?page=2"
+                }
+            }
+        },
+        "controllers.CheckoutRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.FineEntryRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "kind"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "payment",
+                        "waiver"
+                    ],
+                    "example": "payment"
+                },
+                "loan_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Paid at the front desk"
+                }
+            }
+        },
+        "controllers.HoldRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.LinkCheckRequest": {
+            "type": "object",
+            "properties": {
+                "book_pages": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://www.byfood.com/books/1"
+                    ]
+                },
+                "redirect_targets": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://example.com/partner"
+                    ]
+                }
+            }
+        },
+        "controllers.ReceiveTransferRequest": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "type": "string",
+                    "example": "Shelf C2"
+                }
+            }
+        },
+        "controllers.TransferRequest": {
+            "type": "object",
+            "required": [
+                "copy_id",
+                "to_branch_id"
+            ],
+            "properties": {
+                "copy_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to_branch_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "controllers.URLBatchRequest": {
+            "type": "object",
+            "required": [
+                "urls"
+            ],
+            "properties": {
+                "operation": {
+                    "type": "string",
+                    "example": "canonical"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "strip_tracking",
+                        "lowercase_path"
+                    ]
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.URLCompareRequest": {
+            "type": "object",
+            "required": [
+                "urls"
+            ],
+            "properties": {
+                "operation": {
+                    "type": "string",
+                    "example": "all"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "normalize",
+                        "strip_tracking"
+                    ]
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://byfood.com/food-experiences/",
+                        "https://www.byfood.com/food-experiences?utm_source=x"
+                    ]
+                }
+            }
+        },
+        "controllers.URLRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "operation": {
+                    "type": "string",
+                    "example": "canonical"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "strip_tracking",
+                        "lowercase_path",
+                        "force_host"
+                    ]
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Book": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "available_copies": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2023-01-03T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "the-great-gatsby-f-scott-fitzgerald"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                },
+                "total_copies": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/books/the-great-gatsby-f-scott-fitzgerald"
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 1925
+                }
+            }
+        },
+        "models.Branch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1-1 Marunouchi, Chiyoda, Tokyo"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "TYO"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Tokyo Office"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                }
+            }
+        },
+        "models.Copy": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "BF-000123"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "condition": {
+                    "type": "string",
+                    "example": "good"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Shelf A3"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "available"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                }
+            }
+        },
+        "models.FineEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "charge"
+                },
+                "loan_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Returned 10 days late"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Hold": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/models.Book"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "copy_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pickup_deadline": {
+                    "type": "string",
+                    "example": "2023-01-08T00:00:00Z"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "ready_at": {
+                    "type": "string",
+                    "example": "2023-01-05T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "waiting"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.LinkCheckJob": {
+            "type": "object",
+            "properties": {
+                "broken": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "checked": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:05Z"
+                }
+            }
+        },
+        "models.LinkCheckResult": {
+            "type": "object",
+            "properties": {
+                "broken": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "checked_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:01Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:01Z"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 182
+                },
+                "error": {
+                    "type": "string"
+                },
+                "final_url": {
+                    "type": "string",
+                    "example": "https://example.com/new-page"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "job_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "redirects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkHop"
+                    }
+                },
+                "source": {
+                    "type": "string",
+                    "example": "redirect:12"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/old-page"
+                }
+            }
+        },
+        "models.LinkHop": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "type": "string",
+                    "example": "https://example.com/new-page"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 301
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/old-page"
+                }
+            }
+        },
+        "models.Loan": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/models.Book"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "checked_out_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "copy": {
+                    "$ref": "#/definitions/models.Copy"
+                },
+                "copy_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2023-01-15T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "renewals": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "returned_at": {
+                    "type": "string",
+                    "example": "2023-01-10T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.RedirectRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "match_type": {
+                    "type": "string",
+                    "example": "prefix"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "source": {
+                    "type": "string",
+                    "example": "/experiences"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 301
+                },
+                "target": {
+                    "type": "string",
+                    "example": "/food-experiences"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                }
+            }
+        },
+        "models.ShortLink": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "code": {
+                    "type": "string",
+                    "example": "gatsby"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_clicked_at": {
+                    "type": "string",
+                    "example": "2023-06-01T00:00:00Z"
+                },
+                "short_url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/s/gatsby"
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/books/the-great-gatsby-f-scott-fitzgerald"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                }
+            }
+        },
+        "models.Transfer": {
+            "type": "object",
+            "properties": {
+                "copy": {
+                    "$ref": "#/definitions/models.Copy"
+                },
+                "copy_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "from_branch_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "received_at": {
+                    "type": "string",
+                    "example": "2023-01-04T00:00:00Z"
+                },
+                "shipped_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "requested"
+                },
+                "to_branch_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2023-01-03T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "jane.doe@byfood.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "membership_tier": {
+                    "type": "string",
+                    "example": "standard"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                }
+            }
+        },
+        "services.BookListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Book"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.BookResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Book"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.BranchListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Branch"
+                    }
+                }
+            }
+        },
+        "services.BranchResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Branch"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CanonicalCheck": {
+            "type": "object",
+            "properties": {
+                "computed_canonical": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences"
+                },
+                "consistent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "error": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CanonicalIssue"
+                    }
+                },
+                "tags": {
+                    "$ref": "#/definitions/services.PageCanonicalTags"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences?page=2"
+                }
+            }
+        },
+        "services.CanonicalCheckResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CanonicalCheck"
+                    }
+                }
+            }
+        },
+        "services.CanonicalIssue": {
+            "type": "object",
+            "properties": {
+                "expected": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences"
+                },
+                "issue": {
+                    "type": "string",
+                    "example": "canonical_mismatch"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences?page=2"
+                }
+            }
+        },
+        "services.CopyChanges": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "BF-000123"
+                },
+                "branch_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "condition": {
+                    "type": "string",
+                    "example": "good"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Shelf A3"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "maintenance"
+                }
+            }
+        },
+        "services.CopyListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Copy"
+                    }
+                }
+            }
+        },
+        "services.CopyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Copy"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FineEntryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.FineEntry"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FineSummary": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Balance is what the ledger says the user owes.",
+                    "type": "integer",
+                    "example": 250
+                },
+                "checkout_blocked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FineEntry"
+                    }
+                },
+                "outstanding": {
+                    "description": "Outstanding is Balance plus Pending; it decides whether checkouts are blocked.",
+                    "type": "integer",
+                    "example": 325
+                },
+                "pending": {
+                    "description": "Pending is what the user's overdue loans would be charged if returned now.",
+                    "type": "integer",
+                    "example": 75
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "services.FineSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/services.FineSummary"
+                }
+            }
+        },
+        "services.HTMLDocument": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string",
+                    "example": "\u003chtml\u003e\u003chead\u003e\u003clink rel=\"canonical\" href=\"https://www.byfood.com/food-experiences\"\u003e\u003c/head\u003e\u003c/html\u003e"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences?page=2"
+                }
+            }
+        },
+        "services.HoldListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Hold"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.HoldResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Hold"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.HreflangAlternate": {
+            "type": "object",
+            "properties": {
+                "hreflang": {
+                    "type": "string",
+                    "example": "zh-TW"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/zh-tw/food-experiences"
+                }
+            }
+        },
+        "services.LinkCheckJobResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.LinkCheckJob"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.LinkCheckResultsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.LinkCheckJob"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkCheckResult"
+                    }
+                }
+            }
+        },
+        "services.LoanListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Loan"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.LoanResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Loan"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.LocaleRules": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default is the locale of paths without a prefix. Its prefix is removed\nunless PrefixDefault is set, in which case unprefixed paths get it.",
+                    "type": "string"
+                },
+                "locales": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prefix_default": {
+                    "type": "boolean"
+                },
+                "query_parameter": {
+                    "description": "QueryParameter names a parameter such as lang whose value, when it is a\nconfigured locale, is moved into the path prefix. A prefix already in the\npath wins over the parameter.",
+                    "type": "string"
+                }
+            }
+        },
+        "services.PageCanonicalTags": {
+            "type": "object",
+            "properties": {
+                "canonicals": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "hreflang": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.HreflangAlternate"
+                    }
+                },
+                "og_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Pagination": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.PrefixRewrite": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.QueryRules": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "drop_empty": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "sort": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "services.RedirectAudit": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RedirectChain"
+                    }
+                },
+                "loops": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RedirectChain"
+                    }
+                },
+                "max_chain_length": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "services.RedirectAuditResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/services.RedirectAudit"
+                }
+            }
+        },
+        "services.RedirectChain": {
+            "type": "object",
+            "properties": {
+                "length": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rule_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "too_long": {
+                    "description": "TooLong is set when the chain has more hops than the configured maximum.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "urls": {
+                    "description": "URLs holds the start followed by the target of every hop.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/a",
+                        "/b",
+                        "/c"
+                    ]
+                }
+            }
+        },
+        "services.RedirectHop": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/experiences/tokyo"
+                },
+                "rule_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 301
+                },
+                "to": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences/tokyo"
+                }
+            }
+        },
+        "services.RedirectImport": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RedirectRule"
+                    }
+                },
+                "unparsed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RedirectImportIssue"
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.RedirectImportIssue": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "RewriteCond is not supported"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "RewriteCond %{HTTP_HOST} ^byfood.com$"
+                }
+            }
+        },
+        "services.RedirectImportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/services.RedirectImport"
+                }
+            }
+        },
+        "services.RedirectResolution": {
+            "type": "object",
+            "properties": {
+                "hops": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RedirectHop"
+                    }
+                },
+                "status_code": {
+                    "description": "StatusCode is 302 when any hop is temporary and the first hop's status\ncode otherwise.",
+                    "type": "integer",
+                    "example": 301
+                },
+                "target": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences/tokyo"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/experiences/tokyo"
+                }
+            }
+        },
+        "services.RedirectRuleChanges": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "match_type": {
+                    "type": "string",
+                    "example": "prefix"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "source": {
+                    "type": "string",
+                    "example": "/experiences"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 301
+                },
+                "target": {
+                    "type": "string",
+                    "example": "/food-experiences"
+                }
+            }
+        },
+        "services.RedirectRuleListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RedirectRule"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.RedirectRuleResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.RedirectRule"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.RuleSet": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "fragment": {
+                    "type": "string"
+                },
+                "host_mappings": {
+                    "description": "HostMappings maps lowercase source hosts to target hosts. The \"*\" key\napplies to every host without an explicit mapping.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "host_query": {
+                    "description": "HostQuery replaces Query for the listed hosts, matched after host mapping.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/services.QueryRules"
+                    }
+                },
+                "locale": {
+                    "$ref": "#/definitions/services.LocaleRules"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "normalize": {
+                    "description": "Normalize applies the RFC 3986 normalizations before any other rule.",
+                    "type": "boolean"
+                },
+                "path_case": {
+                    "type": "string"
+                },
+                "prefix_rewrites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PrefixRewrite"
+                    }
+                },
+                "query": {
+                    "$ref": "#/definitions/services.QueryRules"
+                },
+                "trailing_slash": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ShortLinkChanges": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "no_expiry": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "services.ShortLinkListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ShortLink"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.ShortLinkRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "gatsby"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://byfood.com/books/the-great-gatsby-f-scott-fitzgerald/?utm_source=x"
+                }
+            }
+        },
+        "services.ShortLinkResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.ShortLink"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.SitemapAudit": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SitemapAuditEntry"
+                    }
+                },
+                "invalid": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "not_canonical": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "redirected": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urlset"
+                }
+            }
+        },
+        "services.SitemapAuditEntry": {
+            "type": "object",
             "properties": {
+                "canonical_url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/books/3"
+                },
+                "duplicate_of": {
+                    "description": "DuplicateOf is the index of the first \u003cloc\u003e with the same canonical URL.",
+                    "type": "integer",
+                    "example": 1
+                },
                 "error": {
                     "type": "string"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "issues": {
+                    "description": "Issues holds invalid, not_canonical, duplicate and redirect.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "not_canonical",
+                        "redirect"
+                    ]
+                },
+                "loc": {
+                    "type": "string",
+                    "example": "https://byfood.com/Books/3/"
+                },
+                "redirect": {
+                    "$ref": "#/definitions/services.RedirectResolution"
                 }
             }
         },
-        "services.Pagination": {
+        "services.SitemapAuditResponse": {
             "type": "object",
             "properties": {
-                "limit": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/services.SitemapAudit"
+                }
+            }
+        },
+        "services.SitemapURL": {
+            "type": "object",
+            "properties": {
+                "lastMod": {
+                    "type": "string"
                 },
-                "page": {
-                    "type": "integer"
+                "loc": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.SitemapURLSet": {
+            "type": "object",
+            "properties": {
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SitemapURL"
+                    }
                 },
-                "total_count": {
-                    "type": "integer"
+                "xmlns": {
+                    "type": "string"
                 }
             }
         },
@@ -392,7 +4619,274 @@ const docTemplate = `{
         "services.SuccessProcessURL": {
             "type": "object",
             "properties": {
+                "alternates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.HreflangAlternate"
+                    }
+                },
+                "processed_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TransferListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transfer"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.TransferResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.Transfer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.URLBatchResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLBatchResult"
+                    }
+                }
+            }
+        },
+        "services.URLBatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "processed_url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://BYFOOD.com/food-EXPeriences?utm_source=x"
+                }
+            }
+        },
+        "services.URLCluster": {
+            "type": "object",
+            "properties": {
+                "indexes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        0,
+                        2
+                    ]
+                },
                 "processed_url": {
+                    "type": "string",
+                    "example": "https://www.byfood.com/food-experiences"
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.URLCompareResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/services.URLComparison"
+                }
+            }
+        },
+        "services.URLComparison": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLCluster"
+                    }
+                },
+                "equivalent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLBatchResult"
+                    }
+                }
+            }
+        },
+        "services.URLOperationInfo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Remove utm_*, gclid, fbclid and other tracking parameters"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "builtin"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "strip_tracking"
+                }
+            }
+        },
+        "services.URLOperationsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLOperationInfo"
+                    }
+                }
+            }
+        },
+        "services.URLRuleSetsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RuleSet"
+                    }
+                },
+                "loaded_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.URLStats": {
+            "type": "object",
+            "properties": {
+                "already_canonical_percent": {
+                    "description": "AlreadyCanonicalPercent is the share of requests whose URL came back\nunchanged, from 0 to 100.",
+                    "type": "number",
+                    "example": 25
+                },
+                "bucket": {
+                    "type": "string",
+                    "example": "day"
+                },
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLStatsBucket"
+                    }
+                },
+                "changed": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-05-01T00:00:00Z"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-05-08T00:00:00Z"
+                },
+                "top_hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLStatsCount"
+                    }
+                },
+                "top_operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLStatsCount"
+                    }
+                },
+                "top_transformations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.URLStatsCount"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "services.URLStatsBucket": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2024-05-01T00:00:00Z"
+                }
+            }
+        },
+        "services.URLStatsCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "value": {
+                    "type": "string",
+                    "example": "www.byfood.com"
+                }
+            }
+        },
+        "services.UserListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/services.Pagination"
+                }
+            }
+        },
+        "services.UserResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "message": {
                     "type": "string"
                 }
             }
@@ -4,6 +4,14 @@ import (
 	"byfood-test-backend/config"
 	"byfood-test-backend/controllers"
 	"byfood-test-backend/docs"
+	"byfood-test-backend/services"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 
@@ -17,10 +25,26 @@ func init() {
 	config.InitLogger()
 	config.ConnectToDB()
 	config.MigrateDatabase()
+
+	if err := services.BackfillBookSlugs(config.DB); err != nil {
+		config.Log.WithError(err).Error("Error assigning book slugs")
+	}
+
+	if gin.Mode() == gin.ReleaseMode && (config.SitemapBaseURL() == "" || config.ShortLinkBaseURL() == "") {
+		config.Log.Fatal("SITEMAP_BASE_URL and SHORTLINK_BASE_URL must be set in release mode")
+	}
+
+	services.URLRules.SetPath(config.URLRulesFile())
+	if err := services.URLRules.Reload(); err != nil {
+		config.Log.WithError(err).Fatal("Error loading url rules")
+	}
 }
 
 func main() {
 	router := gin.Default()
+	if err := router.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		config.Log.WithError(err).Fatal("Invalid TRUSTED_PROXIES")
+	}
 
 	docs.SwaggerInfo.Title = "Book Management System API"
 	docs.SwaggerInfo.Description = "This is a server for managing books."
@@ -28,7 +52,7 @@ func main() {
 	docs.SwaggerInfo.BasePath = "/"
 	docs.SwaggerInfo.Schemes = []string{"http", "https"}
 
-	config := cors.Config{
+	corsConfig := cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
@@ -36,17 +60,112 @@ func main() {
 		AllowCredentials: true,
 	}
 
-	router.Use(cors.New(config))
+	router.Use(cors.New(corsConfig))
 
 	api := router.Group("/api")
 	{
 		api.POST("/books", controllers.AddBook)
 		api.GET("/books", controllers.GetBooks)
+		api.GET("/books/by-slug/:slug", controllers.GetBookBySlug)
 		api.GET("/books/:id", controllers.GetBookByID)
 		api.PUT("/books/:id", controllers.UpdateBookByID)
 		api.DELETE("/books/:id", controllers.DeleteBookByID)
+		api.POST("/books/:id/checkout", controllers.CheckoutBook)
+		api.GET("/books/:id/copies", controllers.GetBookCopies)
+		api.POST("/books/:id/copies", controllers.AddBookCopy)
+		api.PUT("/books/:id/copies/:copyId", controllers.UpdateBookCopy)
+		api.DELETE("/books/:id/copies/:copyId", controllers.DeleteBookCopy)
+		api.POST("/books/:id/holds", controllers.PlaceHold)
+		api.GET("/loans", controllers.GetLoans)
+		api.POST("/loans/:id/return", controllers.ReturnLoan)
+		api.POST("/loans/:id/renew", controllers.RenewLoan)
+		api.GET("/holds", controllers.GetHolds)
+		api.DELETE("/holds/:id", controllers.CancelHold)
+		api.POST("/users", controllers.AddUser)
+		api.GET("/users", controllers.GetUsers)
+		api.GET("/users/:id", controllers.GetUserByID)
+		api.GET("/users/:id/fines", controllers.GetUserFines)
+		api.POST("/users/:id/fines/payments", controllers.AddUserFineEntry)
+		api.POST("/branches", controllers.AddBranch)
+		api.GET("/branches", controllers.GetBranches)
+		api.GET("/branches/:id", controllers.GetBranchByID)
+		api.POST("/transfers", controllers.RequestTransfer)
+		api.GET("/transfers", controllers.GetTransfers)
+		api.POST("/transfers/:id/ship", controllers.ShipTransfer)
+		api.POST("/transfers/:id/receive", controllers.ReceiveTransfer)
+		api.POST("/transfers/:id/cancel", controllers.CancelTransfer)
 		api.POST("/process_url", controllers.ProcessURL)
+		api.POST("/process_url/batch", controllers.ProcessURLBatch)
+		api.POST("/process_url/compare", controllers.CompareURLs)
+		api.POST("/process_url/sitemap-audit", controllers.AuditSitemap)
+		api.POST("/process_url/canonical-check", controllers.CheckCanonicalTags)
+		api.GET("/process_url/stats", controllers.GetURLStats)
+		api.GET("/process_url/operations", controllers.GetURLOperations)
+		api.GET("/process_url/rules", controllers.GetURLRuleSets)
+		api.POST("/process_url/rules/reload", controllers.ReloadURLRules)
+		api.POST("/redirects", controllers.AddRedirectRule)
+		api.GET("/redirects", controllers.GetRedirectRules)
+		api.GET("/redirects/resolve", controllers.ResolveRedirect)
+		api.GET("/redirects/audit", controllers.AuditRedirectRules)
+		api.GET("/redirects/export", controllers.ExportRedirectRules)
+		api.POST("/redirects/import", controllers.ImportRedirectRules)
+		api.GET("/redirects/:id", controllers.GetRedirectRuleByID)
+		api.PUT("/redirects/:id", controllers.UpdateRedirectRule)
+		api.DELETE("/redirects/:id", controllers.DeleteRedirectRule)
+		api.POST("/links", controllers.AddShortLink)
+		api.GET("/links", controllers.GetShortLinks)
+		api.GET("/links/:id", controllers.GetShortLinkByID)
+		api.PUT("/links/:id", controllers.UpdateShortLink)
+		api.DELETE("/links/:id", controllers.DeleteShortLink)
+		api.POST("/linkcheck", controllers.StartLinkCheck)
+		api.GET("/linkcheck/:job", controllers.GetLinkCheckJob)
 		api.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
-	router.Run()
+
+	router.GET("/sitemap.xml", controllers.GetSitemap)
+	router.GET("/sitemap.xml.gz", controllers.GetSitemap)
+	router.GET("/sitemaps/:file", controllers.GetSitemapPage)
+	router.GET("/s/:code", controllers.FollowShortLink)
+
+	if config.RedirectsCatchAll() {
+		router.NoRoute(controllers.HandleRedirect)
+	}
+
+	go services.RunHoldExpiry(config.DB, config.HoldExpiryInterval())
+	go services.URLRules.Watch(config.URLRulesReloadInterval())
+	if config.URLStatsEnabled() {
+		services.URLRecords = services.StartURLRecorder(config.DB)
+		go services.RunURLRecordPruning(config.DB, config.URLStatsRetention(), config.URLStatsPruneInterval())
+	}
+
+	server := &http.Server{Addr: ":" + serverPort(), Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			config.Log.WithError(err).Fatal("Error running server")
+		}
+	}()
+
+	// On SIGINT or SIGTERM requests in flight are finished, running link check
+	// jobs are stopped and queued URL records are stored before exiting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		config.Log.WithError(err).Error("Error shutting down server")
+	}
+	services.StopLinkCheckJobs()
+	if services.URLRecords != nil {
+		services.URLRecords.Stop()
+	}
+}
+
+// serverPort returns the port from PORT, defaulting to 8080 like gin does.
+func serverPort() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return port
+	}
+	return "8080"
 }
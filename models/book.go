@@ -3,11 +3,24 @@ package models
 import "time"
 
 type Book struct {
-	ID        uint       `json:"id" example:"1"`
-	CreatedAt time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2023-01-03T00:00:00Z"`
-	Title     string     `json:"title" example:"The Great Gatsby"`
-	Author    string     `json:"author" example:"F. Scott Fitzgerald"`
-	Year      int        `json:"year" example:"1925"`
+	ID              uint       `json:"id" example:"1"`
+	CreatedAt       time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt       time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" example:"2023-01-03T00:00:00Z"`
+	Title           string     `json:"title" example:"The Great Gatsby"`
+	Author          string     `json:"author" example:"F. Scott Fitzgerald"`
+	Year            int        `json:"year" example:"1925"`
+	Slug            string     `json:"slug" gorm:"uniqueIndex:idx_books_slug,where:slug <> ''" example:"the-great-gatsby-f-scott-fitzgerald"`
+	URL             string     `json:"url,omitempty" gorm:"-" example:"https://www.byfood.com/books/the-great-gatsby-f-scott-fitzgerald"`
+	TotalCopies     int        `json:"total_copies" gorm:"-" example:"3"`
+	AvailableCopies int        `json:"available_copies" gorm:"-" example:"2"`
+}
+
+// BookSlug is a slug a book was known by before its title or author changed.
+// Old slugs keep resolving to the book.
+type BookSlug struct {
+	ID        uint      `json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	BookID    uint      `json:"book_id" gorm:"not null;index" example:"1"`
+	Slug      string    `json:"slug" gorm:"not null;uniqueIndex" example:"the-great-gatsby"`
 }
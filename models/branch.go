@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+// Branch is a library location holding physical copies.
+type Branch struct {
+	ID        uint      `json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	Code      string    `json:"code" gorm:"uniqueIndex" example:"TYO"`
+	Name      string    `json:"name" example:"Tokyo Office"`
+	Address   string    `json:"address,omitempty" example:"1-1 Marunouchi, Chiyoda, Tokyo"`
+}
+
+// Transfer moves a copy from one branch to another. It goes from requested to
+// in_transit when the copy is shipped and to received when it arrives.
+type Transfer struct {
+	ID           uint       `json:"id" example:"1"`
+	CreatedAt    time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt    time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	CopyID       uint       `json:"copy_id" gorm:"index" example:"1"`
+	Copy         *Copy      `json:"copy,omitempty"`
+	FromBranchID uint       `json:"from_branch_id" gorm:"index" example:"1"`
+	ToBranchID   uint       `json:"to_branch_id" gorm:"index" example:"2"`
+	Status       string     `json:"status" gorm:"index;default:requested" example:"requested"`
+	ShippedAt    *time.Time `json:"shipped_at,omitempty" example:"2023-01-02T00:00:00Z"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty" example:"2023-01-04T00:00:00Z"`
+}
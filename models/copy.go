@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+const (
+	CopyStatusAvailable   = "available"
+	CopyStatusOnLoan      = "on_loan"
+	CopyStatusOnHold      = "on_hold"
+	CopyStatusInTransit   = "in_transit"
+	CopyStatusMaintenance = "maintenance"
+	CopyStatusLost        = "lost"
+)
+
+const (
+	CopyConditionNew     = "new"
+	CopyConditionGood    = "good"
+	CopyConditionFair    = "fair"
+	CopyConditionPoor    = "poor"
+	CopyConditionDamaged = "damaged"
+)
+
+// Copy is a physical copy of a Book that can be lent out.
+type Copy struct {
+	ID        uint      `json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	BookID    uint      `json:"book_id" gorm:"index" example:"1"`
+	BranchID  *uint     `json:"branch_id,omitempty" gorm:"index" example:"1"`
+	Barcode   string    `json:"barcode" gorm:"uniqueIndex" example:"BF-000123"`
+	Condition string    `json:"condition" gorm:"default:good" example:"good"`
+	Location  string    `json:"location" example:"Shelf A3"`
+	Status    string    `json:"status" gorm:"index;default:available" example:"available"`
+}
+
+// ValidCopyStatus reports whether status is a known copy status.
+func ValidCopyStatus(status string) bool {
+	switch status {
+	case CopyStatusAvailable, CopyStatusOnLoan, CopyStatusOnHold, CopyStatusInTransit, CopyStatusMaintenance, CopyStatusLost:
+		return true
+	}
+	return false
+}
+
+// CopyInCirculation reports whether a copy with the given status is lent out,
+// set aside for a hold or in transit between branches, in which case only the
+// loan, hold and transfer flows may change it.
+func CopyInCirculation(status string) bool {
+	return status == CopyStatusOnLoan || status == CopyStatusOnHold || status == CopyStatusInTransit
+}
+
+// ValidCopyCondition reports whether condition is a known copy condition.
+func ValidCopyCondition(condition string) bool {
+	switch condition {
+	case CopyConditionNew, CopyConditionGood, CopyConditionFair, CopyConditionPoor, CopyConditionDamaged:
+		return true
+	}
+	return false
+}
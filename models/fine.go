@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	FineEntryCharge  = "charge"
+	FineEntryPayment = "payment"
+	FineEntryWaiver  = "waiver"
+)
+
+// FineEntry is a line in a user's fine ledger. Charges increase the balance,
+// payments and waivers reduce it. Amounts are in minor currency units.
+type FineEntry struct {
+	ID        uint      `json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UserID    uint      `json:"user_id" gorm:"index" example:"1"`
+	LoanID    *uint     `json:"loan_id,omitempty" gorm:"index" example:"1"`
+	Kind      string    `json:"kind" example:"charge"`
+	Amount    int64     `json:"amount" example:"250"`
+	Note      string    `json:"note,omitempty" example:"Returned 10 days late"`
+}
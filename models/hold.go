@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	HoldStatusWaiting   = "waiting"
+	HoldStatusReady     = "ready"
+	HoldStatusFulfilled = "fulfilled"
+	HoldStatusExpired   = "expired"
+	HoldStatusCancelled = "cancelled"
+)
+
+// Hold is a place in the queue for a book. Holds are served in the order they
+// were placed; once a copy is set aside the borrower has until PickupDeadline
+// to check it out.
+type Hold struct {
+	ID             uint       `json:"id" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	BookID         uint       `json:"book_id" gorm:"index" example:"1"`
+	Book           *Book      `json:"book,omitempty"`
+	UserID         uint       `json:"user_id" gorm:"index" example:"1"`
+	Status         string     `json:"status" gorm:"index;default:waiting" example:"waiting"`
+	CopyID         *uint      `json:"copy_id,omitempty" example:"1"`
+	ReadyAt        *time.Time `json:"ready_at,omitempty" example:"2023-01-05T00:00:00Z"`
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty" example:"2023-01-08T00:00:00Z"`
+	Position       int        `json:"position,omitempty" gorm:"-" example:"2"`
+}
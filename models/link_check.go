@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+const (
+	LinkCheckStatusPending = "pending"
+	LinkCheckStatusRunning = "running"
+	LinkCheckStatusDone    = "done"
+	LinkCheckStatusFailed  = "failed"
+)
+
+// LinkCheckJob is a run of the link checker over a set of URLs. The counters
+// are updated as results come in.
+type LinkCheckJob struct {
+	ID         uint       `json:"id" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"2023-01-01T00:00:05Z"`
+	Status     string     `json:"status" gorm:"index;default:pending" example:"running"`
+	Total      int        `json:"total" example:"120"`
+	Checked    int        `json:"checked" example:"80"`
+	Broken     int        `json:"broken" example:"3"`
+	Error      string     `json:"error,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" example:"2023-01-01T00:01:00Z"`
+}
+
+// LinkCheckResult is the outcome of fetching one URL. Source tells where the
+// URL came from: the request, a redirect rule or the page it was linked from.
+type LinkCheckResult struct {
+	ID         uint       `json:"id" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-01-01T00:00:01Z"`
+	JobID      uint       `json:"job_id" gorm:"index" example:"1"`
+	URL        string     `json:"url" example:"https://example.com/old-page"`
+	Source     string     `json:"source" example:"redirect:12"`
+	StatusCode int        `json:"status_code,omitempty" example:"404"`
+	FinalURL   string     `json:"final_url,omitempty" example:"https://example.com/new-page"`
+	Redirects  []LinkHop  `json:"redirects" gorm:"serializer:json"`
+	Broken     bool       `json:"broken" gorm:"index" example:"true"`
+	Error      string     `json:"error,omitempty"`
+	DurationMs int64      `json:"duration_ms" example:"182"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty" example:"2023-01-01T00:00:01Z"`
+}
+
+// LinkHop is one redirect response on the way to the final URL.
+type LinkHop struct {
+	URL        string `json:"url" example:"https://example.com/old-page"`
+	StatusCode int    `json:"status_code" example:"301"`
+	Location   string `json:"location" example:"https://example.com/new-page"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	LoanStatusActive   = "active"
+	LoanStatusOverdue  = "overdue"
+	LoanStatusReturned = "returned"
+)
+
+type Loan struct {
+	ID           uint       `json:"id" example:"1"`
+	CreatedAt    time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt    time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	BookID       uint       `json:"book_id" gorm:"index" example:"1"`
+	Book         *Book      `json:"book,omitempty"`
+	CopyID       uint       `json:"copy_id" gorm:"index" example:"1"`
+	Copy         *Copy      `json:"copy,omitempty"`
+	UserID       uint       `json:"user_id" gorm:"index" example:"1"`
+	User         *User      `json:"user,omitempty"`
+	CheckedOutAt time.Time  `json:"checked_out_at" example:"2023-01-01T00:00:00Z"`
+	DueAt        time.Time  `json:"due_at" example:"2023-01-15T00:00:00Z"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty" example:"2023-01-10T00:00:00Z"`
+	Renewals     int        `json:"renewals" example:"0"`
+	Status       string     `json:"status" gorm:"-" example:"active"`
+}
+
+// StatusAt reports whether the loan is active, overdue or returned at the given time.
+func (l Loan) StatusAt(now time.Time) string {
+	if l.ReturnedAt != nil {
+		return LoanStatusReturned
+	}
+	if now.After(l.DueAt) {
+		return LoanStatusOverdue
+	}
+	return LoanStatusActive
+}
+
+func (l *Loan) AfterFind(tx *gorm.DB) error {
+	l.Status = l.StatusAt(time.Now())
+	return nil
+}
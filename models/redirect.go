@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	RedirectMatchExact  = "exact"
+	RedirectMatchPrefix = "prefix"
+	RedirectMatchRegex  = "regex"
+)
+
+// RedirectRule sends requests whose path matches Source to Target.
+//
+// An exact rule matches the path itself, a prefix rule matches the path and
+// everything below it, appending the rest of the path to Target, and a regex
+// rule matches a regular expression whose groups can be used in Target as $1,
+// $2 and so on. Enabled rules are tried from the highest priority down.
+type RedirectRule struct {
+	ID         uint      `json:"id" example:"1"`
+	CreatedAt  time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt  time.Time `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	Source     string    `json:"source" gorm:"index" example:"/experiences"`
+	Target     string    `json:"target" example:"/food-experiences"`
+	StatusCode int       `json:"status_code" gorm:"default:301" example:"301"`
+	MatchType  string    `json:"match_type" gorm:"default:exact" example:"prefix"`
+	Priority   int       `json:"priority" gorm:"index" example:"0"`
+	Enabled    bool      `json:"enabled" gorm:"index" example:"true"`
+}
+
+// ValidRedirectMatch reports whether matchType is a known match type.
+func ValidRedirectMatch(matchType string) bool {
+	switch matchType {
+	case RedirectMatchExact, RedirectMatchPrefix, RedirectMatchRegex:
+		return true
+	}
+	return false
+}
+
+// ValidRedirectStatus reports whether code is a redirect status rules may use.
+func ValidRedirectStatus(code int) bool {
+	return code == 301 || code == 302 || code == 308
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ShortLink sends requests for /s/{Code} to TargetURL, the canonical form of
+// the URL it was created for. Disabled and expired links no longer redirect.
+type ShortLink struct {
+	ID            uint       `json:"id" example:"1"`
+	CreatedAt     time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt     time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	Code          string     `json:"code" gorm:"not null;uniqueIndex" example:"gatsby"`
+	TargetURL     string     `json:"target_url" gorm:"not null;index" example:"https://www.byfood.com/books/the-great-gatsby-f-scott-fitzgerald"`
+	Enabled       bool       `json:"enabled" example:"true"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	Clicks        int64      `json:"clicks" example:"42"`
+	LastClickedAt *time.Time `json:"last_clicked_at,omitempty" example:"2023-06-01T00:00:00Z"`
+	ShortURL      string     `json:"short_url,omitempty" gorm:"-" example:"https://www.byfood.com/s/gatsby"`
+}
+
+// Active reports whether the link redirects at now.
+func (l ShortLink) Active(now time.Time) bool {
+	return l.Enabled && (l.ExpiresAt == nil || now.Before(*l.ExpiresAt))
+}
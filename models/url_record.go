@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// URLRecord is one request to /api/process_url, kept for the URL statistics.
+// Transformation lists the rules that changed the URL, in order, and is empty
+// when the URL was already canonical.
+type URLRecord struct {
+	ID             uint      `json:"id" example:"1"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index" example:"2023-01-01T00:00:00Z"`
+	InputURL       string    `json:"input_url" example:"https://BYFOOD.com/food-experiences/?utm_source=x"`
+	OutputURL      string    `json:"output_url" example:"https://www.byfood.com/food-experiences"`
+	Operation      string    `json:"operation" gorm:"index" example:"canonical"`
+	Host           string    `json:"host" gorm:"index" example:"www.byfood.com"`
+	Changed        bool      `json:"changed" example:"true"`
+	Transformation string    `json:"transformation" gorm:"index" example:"canonical.host_mappings,canonical.query"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+const MembershipTierStandard = "standard"
+
+type User struct {
+	ID             uint       `json:"id" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2023-01-02T00:00:00Z"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" example:"2023-01-03T00:00:00Z"`
+	Name           string     `json:"name" example:"Jane Doe"`
+	Email          string     `json:"email" gorm:"uniqueIndex" example:"jane.doe@byfood.com"`
+	MembershipTier string     `json:"membership_tier" gorm:"default:standard" example:"standard"`
+}
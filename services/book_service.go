@@ -0,0 +1,60 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrBookInCirculation = errors.New("book has open loans, holds or copies in transit")
+
+// DeleteBook removes a book together with its copies, their transfers and
+// its slug history. Books with a loan that has not been returned, an active
+// hold or a copy in circulation are kept. Returned loans and closed holds
+// stay as history.
+func DeleteBook(db *gorm.DB, bookID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var book models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, bookID).Error; err != nil {
+			return notFound(err, ErrBookNotFound)
+		}
+
+		var open int64
+		if err := tx.Model(&models.Loan{}).Where("book_id = ? AND returned_at IS NULL", bookID).Count(&open).Error; err != nil {
+			return err
+		}
+		if open == 0 {
+			err := tx.Model(&models.Hold{}).
+				Where("book_id = ? AND status IN ?", bookID, []string{models.HoldStatusWaiting, models.HoldStatusReady}).
+				Count(&open).Error
+			if err != nil {
+				return err
+			}
+		}
+		if open == 0 {
+			err := tx.Model(&models.Copy{}).
+				Where("book_id = ? AND status IN ?", bookID, []string{models.CopyStatusOnLoan, models.CopyStatusOnHold, models.CopyStatusInTransit}).
+				Count(&open).Error
+			if err != nil {
+				return err
+			}
+		}
+		if open > 0 {
+			return ErrBookInCirculation
+		}
+
+		copies := tx.Model(&models.Copy{}).Select("id").Where("book_id = ?", bookID)
+		if err := tx.Where("copy_id IN (?)", copies).Delete(&models.Transfer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id = ?", bookID).Delete(&models.Copy{}).Error; err != nil {
+			return err
+		}
+		if err := DeleteBookSlugs(tx, bookID); err != nil {
+			return err
+		}
+		return tx.Delete(&book).Error
+	})
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// fallbackBookSlug is used when nothing of the title and author can be
+// transliterated.
+const fallbackBookSlug = "book"
+
+// bookSlugLock is the key of the transaction-level advisory lock that
+// serializes slug assignments, so two books cannot both find the same slug
+// free before either is saved.
+const bookSlugLock = 7042001
+
+// BookSlugBase returns the slug a book gets before it is made unique.
+func BookSlugBase(book models.Book) string {
+	if slug := Slugify(book.Title + " " + book.Author); slug != "" {
+		return slug
+	}
+	return fallbackBookSlug
+}
+
+// AssignBookSlug sets the slug of book from its title and author, adding a
+// numeric suffix when another book has, or had, the same slug. When the slug
+// changes the old one is kept in the slug history. The book itself is not
+// saved; tx must be a transaction that saves it, since the slug stays
+// reserved for it until tx ends.
+func AssignBookSlug(tx *gorm.DB, book *models.Book) error {
+	base := BookSlugBase(*book)
+	if book.Slug != "" && slugHasBase(book.Slug, base) {
+		return nil
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", bookSlugLock).Error; err != nil {
+		return err
+	}
+
+	slug, err := uniqueBookSlug(tx, base, book.ID)
+	if err != nil {
+		return err
+	}
+
+	if book.Slug != "" {
+		if err := tx.Create(&models.BookSlug{BookID: book.ID, Slug: book.Slug}).Error; err != nil {
+			return err
+		}
+	}
+	if book.ID != 0 {
+		// The book gets one of its old slugs back.
+		if err := tx.Where("book_id = ? AND slug = ?", book.ID, slug).Delete(&models.BookSlug{}).Error; err != nil {
+			return err
+		}
+	}
+
+	book.Slug = slug
+	return nil
+}
+
+// FindBookBySlug returns the book with slug. moved reports that slug is an
+// old slug of the book, which now has another one.
+func FindBookBySlug(db *gorm.DB, slug string) (book models.Book, moved bool, err error) {
+	err = db.Where("slug = ?", slug).First(&book).Error
+	if err == nil {
+		return book, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return book, false, err
+	}
+
+	var history models.BookSlug
+	if err := db.Where("slug = ?", slug).First(&history).Error; err != nil {
+		return book, false, notFound(err, ErrBookNotFound)
+	}
+	if err := db.First(&book, history.BookID).Error; err != nil {
+		return book, false, notFound(err, ErrBookNotFound)
+	}
+	return book, true, nil
+}
+
+// DeleteBookSlugs removes the slug history of a book.
+func DeleteBookSlugs(tx *gorm.DB, bookID uint) error {
+	return tx.Where("book_id = ?", bookID).Delete(&models.BookSlug{}).Error
+}
+
+// BackfillBookSlugs assigns a slug to every book that has none, such as books
+// added before slugs existed.
+func BackfillBookSlugs(db *gorm.DB) error {
+	var books []models.Book
+	if err := db.Where("slug = '' OR slug IS NULL").Order("id").Find(&books).Error; err != nil {
+		return err
+	}
+
+	for i := range books {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := AssignBookSlug(tx, &books[i]); err != nil {
+				return err
+			}
+			return tx.Model(&books[i]).UpdateColumn("slug", books[i].Slug).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueBookSlug returns base, or base with the first free numeric suffix,
+// that no other book uses now or used before. Slugs in the history of the
+// book itself are free.
+func uniqueBookSlug(tx *gorm.DB, base string, bookID uint) (string, error) {
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = base + "-" + strconv.Itoa(n)
+		}
+
+		var taken int64
+		if err := tx.Model(&models.Book{}).Where("slug = ? AND id <> ?", candidate, bookID).Count(&taken).Error; err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			if err := tx.Model(&models.BookSlug{}).Where("slug = ? AND book_id <> ?", candidate, bookID).Count(&taken).Error; err != nil {
+				return "", err
+			}
+		}
+		if taken == 0 {
+			return candidate, nil
+		}
+	}
+}
+
+// slugHasBase reports whether slug is base, possibly with a numeric suffix.
+func slugHasBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n > 1 && strconv.Itoa(n) == suffix
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	CanonicalIssueMissing              = "missing_canonical"
+	CanonicalIssueMultiple             = "multiple_canonicals"
+	CanonicalIssueMismatch             = "canonical_mismatch"
+	CanonicalIssueNotNormalized        = "canonical_not_normalized"
+	CanonicalIssueOGURLMismatch        = "og_url_mismatch"
+	CanonicalIssueHreflangMissingSelf  = "hreflang_missing_self"
+	CanonicalIssueHreflangNotCanonical = "hreflang_not_canonical"
+	CanonicalIssueHreflangDuplicate    = "hreflang_duplicate"
+	CanonicalIssueInvalidURL           = "invalid_url"
+)
+
+var (
+	ErrNoHTMLDocuments      = errors.New("no html documents to check")
+	ErrTooManyHTMLDocuments = errors.New("too many html documents to check")
+)
+
+// HTMLDocument is a page and the URL it was served from.
+type HTMLDocument struct {
+	URL  string `json:"url" example:"https://www.byfood.com/food-experiences?page=2"`
+	HTML string `json:"html" example:"<html><head><link rel=\"canonical\" href=\"https://www.byfood.com/food-experiences\"></head></html>"`
+}
+
+// PageCanonicalTags are the URLs a page declares about itself. Relative URLs
+// are resolved against the page URL and any <base href>.
+type PageCanonicalTags struct {
+	Canonicals []string            `json:"canonicals"`
+	OGURL      string              `json:"og_url,omitempty"`
+	Hreflang   []HreflangAlternate `json:"hreflang"`
+}
+
+// CanonicalIssue is one disagreement between the tags of a page and its
+// computed canonical URL. URL is the declared URL concerned and Expected what
+// it should have been, when there is one.
+type CanonicalIssue struct {
+	Issue    string `json:"issue" example:"canonical_mismatch"`
+	URL      string `json:"url,omitempty" example:"https://www.byfood.com/food-experiences?page=2"`
+	Expected string `json:"expected,omitempty" example:"https://www.byfood.com/food-experiences"`
+}
+
+// CanonicalCheck reports whether a page declares the canonical URL
+// ProcessURL computes for it.
+type CanonicalCheck struct {
+	URL               string            `json:"url" example:"https://www.byfood.com/food-experiences?page=2"`
+	ComputedCanonical string            `json:"computed_canonical,omitempty" example:"https://www.byfood.com/food-experiences"`
+	Tags              PageCanonicalTags `json:"tags"`
+	Consistent        bool              `json:"consistent" example:"false"`
+	Issues            []CanonicalIssue  `json:"issues"`
+	Error             string            `json:"error,omitempty"`
+}
+
+// CheckCanonicalTags checks every document against the canonical URL the
+// operations compute for its URL. A document whose URL cannot be processed
+// only fails its own check.
+func CheckCanonicalTags(documents []HTMLDocument, operations []string, maxDocuments int) ([]CanonicalCheck, error) {
+	if len(documents) == 0 {
+		return nil, ErrNoHTMLDocuments
+	}
+	if maxDocuments > 0 && len(documents) > maxDocuments {
+		return nil, ErrTooManyHTMLDocuments
+	}
+	resolved, err := URLOperations.Resolve(operations)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]CanonicalCheck, len(documents))
+	for i, document := range documents {
+		checks[i] = checkCanonicalTags(document, resolved)
+	}
+	return checks, nil
+}
+
+func checkCanonicalTags(document HTMLDocument, operations []URLOperation) CanonicalCheck {
+	check := CanonicalCheck{URL: document.URL, Issues: []CanonicalIssue{}}
+
+	computed, err := applyURLOperations(document.URL, operations)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.ComputedCanonical = computed
+
+	pageURL, _ := url.Parse(document.URL)
+	check.Tags, err = ExtractCanonicalTags(strings.NewReader(document.HTML), pageURL)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	issue := func(name, declared, expected string) {
+		check.Issues = append(check.Issues, CanonicalIssue{Issue: name, URL: declared, Expected: expected})
+	}
+	// compare reports declared when it does not canonicalize to expected, or
+	// when it does but is not written in its canonical form.
+	compare := func(mismatch, declared, expected string) {
+		processed, err := applyURLOperations(declared, operations)
+		switch {
+		case err != nil:
+			issue(CanonicalIssueInvalidURL, declared, "")
+		case processed != expected:
+			issue(mismatch, declared, expected)
+		case declared != processed:
+			issue(CanonicalIssueNotNormalized, declared, processed)
+		}
+	}
+
+	switch len(uniqueStrings(check.Tags.Canonicals)) {
+	case 0:
+		issue(CanonicalIssueMissing, "", computed)
+	case 1:
+		compare(CanonicalIssueMismatch, check.Tags.Canonicals[0], computed)
+	default:
+		for _, canonical := range uniqueStrings(check.Tags.Canonicals) {
+			issue(CanonicalIssueMultiple, canonical, computed)
+		}
+	}
+
+	if check.Tags.OGURL != "" {
+		compare(CanonicalIssueOGURLMismatch, check.Tags.OGURL, computed)
+	}
+
+	if len(check.Tags.Hreflang) > 0 {
+		seen := make(map[string]bool)
+		self := false
+		for _, alternate := range check.Tags.Hreflang {
+			tag := strings.ToLower(alternate.Hreflang)
+			if seen[tag] {
+				issue(CanonicalIssueHreflangDuplicate, alternate.URL, "")
+			}
+			seen[tag] = true
+
+			processed, err := applyURLOperations(alternate.URL, operations)
+			switch {
+			case err != nil:
+				issue(CanonicalIssueInvalidURL, alternate.URL, "")
+			case processed != alternate.URL:
+				issue(CanonicalIssueHreflangNotCanonical, alternate.URL, processed)
+			}
+			if processed == computed {
+				self = true
+			}
+		}
+		if !self {
+			issue(CanonicalIssueHreflangMissingSelf, "", computed)
+		}
+	}
+
+	check.Consistent = len(check.Issues) == 0
+	return check
+}
+
+// ExtractCanonicalTags reads the rel="canonical" and rel="alternate"
+// hreflang links and the og:url meta tag of an HTML document.
+func ExtractCanonicalTags(r io.Reader, pageURL *url.URL) (PageCanonicalTags, error) {
+	tags := PageCanonicalTags{Canonicals: []string{}, Hreflang: []HreflangAlternate{}}
+	base := pageURL
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return PageCanonicalTags{}, err
+			}
+			return tags, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "base":
+				if href, ok := htmlAttribute(token, "href"); ok {
+					base = resolveReference(base, href)
+				}
+			case "link":
+				href, ok := htmlAttribute(token, "href")
+				if !ok {
+					continue
+				}
+				rel, _ := htmlAttribute(token, "rel")
+				for _, value := range strings.Fields(strings.ToLower(rel)) {
+					switch value {
+					case "canonical":
+						tags.Canonicals = append(tags.Canonicals, resolveURL(base, href))
+					case "alternate":
+						if hreflang, ok := htmlAttribute(token, "hreflang"); ok {
+							tags.Hreflang = append(tags.Hreflang, HreflangAlternate{Hreflang: hreflang, URL: resolveURL(base, href)})
+						}
+					}
+				}
+			case "meta":
+				property, _ := htmlAttribute(token, "property")
+				if strings.EqualFold(property, "og:url") && tags.OGURL == "" {
+					if content, ok := htmlAttribute(token, "content"); ok {
+						tags.OGURL = resolveURL(base, content)
+					}
+				}
+			}
+		}
+	}
+}
+
+func htmlAttribute(token html.Token, name string) (string, bool) {
+	for _, attribute := range token.Attr {
+		if attribute.Key == name {
+			return strings.TrimSpace(attribute.Val), true
+		}
+	}
+	return "", false
+}
+
+// resolveReference resolves ref against base, keeping base when either is
+// missing or ref does not parse.
+func resolveReference(base *url.URL, ref string) *url.URL {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return base
+	}
+	if base == nil {
+		return parsed
+	}
+	return base.ResolveReference(parsed)
+}
+
+// resolveURL resolves ref against base, returning ref unchanged when it does
+// not parse so it is reported as it was written.
+func resolveURL(base *url.URL, ref string) string {
+	parsed, err := url.Parse(ref)
+	if err != nil || base == nil {
+		return ref
+	}
+	return base.ResolveReference(parsed).String()
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCopyNotFound          = errors.New("copy not found")
+	ErrCopyInCirculation     = errors.New("copy is on loan, set aside for a hold or in transit")
+	ErrInvalidCopyStatus     = errors.New("invalid copy status")
+	ErrInvalidCopyCondition  = errors.New("invalid copy condition")
+	ErrCopyStatusUnavailable = errors.New("copies can only be lent, set aside or moved through checkouts, holds and transfers")
+	ErrBranchNotFound        = errors.New("branch not found")
+)
+
+// CopyChanges holds the fields of a copy that may be edited directly. Empty
+// fields are left untouched.
+type CopyChanges struct {
+	BranchID  *uint  `json:"branch_id" example:"1"`
+	Barcode   string `json:"barcode" example:"BF-000123"`
+	Condition string `json:"condition" example:"good"`
+	Location  string `json:"location" example:"Shelf A3"`
+	Status    string `json:"status" example:"maintenance"`
+}
+
+type copyCount struct {
+	BookID    uint
+	Total     int
+	Available int
+}
+
+// AttachCopyCounts fills in the total and available copy counts of the books,
+// counting only the copies of one branch when branchID is not zero.
+func AttachCopyCounts(db *gorm.DB, books []models.Book, branchID uint) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	query := db.Model(&models.Copy{}).
+		Select("book_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE status = ?) AS available", models.CopyStatusAvailable).
+		Where("book_id IN ?", ids)
+	if branchID != 0 {
+		query = query.Where("branch_id = ?", branchID)
+	}
+
+	var counts []copyCount
+	err := query.Group("book_id").Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	byBook := make(map[uint]copyCount, len(counts))
+	for _, count := range counts {
+		byBook[count.BookID] = count
+	}
+	for i := range books {
+		books[i].TotalCopies = byBook[books[i].ID].Total
+		books[i].AvailableCopies = byBook[books[i].ID].Available
+	}
+	return nil
+}
+
+// AttachCopyCount fills in the total and available copy counts of a single book.
+func AttachCopyCount(db *gorm.DB, book *models.Book) error {
+	books := []models.Book{*book}
+	if err := AttachCopyCounts(db, books, 0); err != nil {
+		return err
+	}
+	*book = books[0]
+	return nil
+}
+
+// CopiesQuery returns a subquery selecting the ids of books with at least one
+// copy, optionally restricted to copies on the shelf and to a single branch
+// when branchID is not zero.
+func CopiesQuery(db *gorm.DB, availableOnly bool, branchID uint) *gorm.DB {
+	query := db.Model(&models.Copy{}).Select("book_id")
+	if availableOnly {
+		query = query.Where("status = ?", models.CopyStatusAvailable)
+	}
+	if branchID != 0 {
+		query = query.Where("branch_id = ?", branchID)
+	}
+	return query
+}
+
+// UpdateCopy applies changes to a copy of the given book. The copy row is
+// locked so a status change cannot race with a checkout or a return. A copy
+// coming back on the shelf is offered to the hold queue first.
+func UpdateCopy(db *gorm.DB, bookID, copyID uint, changes CopyChanges, now time.Time) (models.Copy, error) {
+	if changes.Condition != "" && !models.ValidCopyCondition(changes.Condition) {
+		return models.Copy{}, ErrInvalidCopyCondition
+	}
+	if changes.Status != "" && !models.ValidCopyStatus(changes.Status) {
+		return models.Copy{}, ErrInvalidCopyStatus
+	}
+
+	var bookCopy models.Copy
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCopy(tx, bookID, copyID, &bookCopy); err != nil {
+			return err
+		}
+
+		if changes.BranchID != nil {
+			if err := checkBranch(tx, *changes.BranchID); err != nil {
+				return err
+			}
+			bookCopy.BranchID = changes.BranchID
+		}
+		if changes.Barcode != "" {
+			bookCopy.Barcode = changes.Barcode
+		}
+		if changes.Condition != "" {
+			bookCopy.Condition = changes.Condition
+		}
+		if changes.Location != "" {
+			bookCopy.Location = changes.Location
+		}
+
+		if changes.Status == "" || changes.Status == bookCopy.Status {
+			return tx.Save(&bookCopy).Error
+		}
+		if models.CopyInCirculation(bookCopy.Status) {
+			return ErrCopyInCirculation
+		}
+		if models.CopyInCirculation(changes.Status) {
+			return ErrCopyStatusUnavailable
+		}
+		if changes.Status == models.CopyStatusAvailable {
+			return releaseCopy(tx, &bookCopy, now)
+		}
+		bookCopy.Status = changes.Status
+		return tx.Save(&bookCopy).Error
+	})
+	if err != nil {
+		return models.Copy{}, err
+	}
+	return bookCopy, nil
+}
+
+// AddCopy registers a new copy of a book. A copy added as available is offered
+// to the hold queue first.
+func AddCopy(db *gorm.DB, bookCopy *models.Copy, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if bookCopy.BranchID != nil {
+			if err := checkBranch(tx, *bookCopy.BranchID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(bookCopy).Error; err != nil {
+			return err
+		}
+		if bookCopy.Status != models.CopyStatusAvailable {
+			return nil
+		}
+		return releaseCopy(tx, bookCopy, now)
+	})
+}
+
+// DeleteCopy removes a copy that is not on loan or set aside for a hold.
+func DeleteCopy(db *gorm.DB, bookID, copyID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var bookCopy models.Copy
+		if err := lockCopy(tx, bookID, copyID, &bookCopy); err != nil {
+			return err
+		}
+		if models.CopyInCirculation(bookCopy.Status) {
+			return ErrCopyInCirculation
+		}
+		return tx.Delete(&bookCopy).Error
+	})
+}
+
+func lockCopy(tx *gorm.DB, bookID, copyID uint, bookCopy *models.Copy) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ?", bookID).
+		First(bookCopy, copyID).Error
+	return notFound(err, ErrCopyNotFound)
+}
+
+func checkBranch(tx *gorm.DB, branchID uint) error {
+	var branch models.Branch
+	return notFound(tx.First(&branch, branchID).Error, ErrBranchNotFound)
+}
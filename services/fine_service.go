@@ -0,0 +1,182 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidFineEntry      = errors.New("fine entries must be a payment or a waiver with a positive amount")
+	ErrPaymentExceedsBalance = errors.New("amount exceeds the outstanding fine balance")
+	ErrFineBalanceTooHigh    = errors.New("outstanding fines exceed the checkout threshold")
+	ErrFineLoanNotFound      = errors.New("loan not found for this user")
+)
+
+// FineSummary is the state of a user's fine account.
+type FineSummary struct {
+	UserID uint `json:"user_id" example:"1"`
+	// Balance is what the ledger says the user owes.
+	Balance int64 `json:"balance" example:"250"`
+	// Pending is what the user's overdue loans would be charged if returned now.
+	Pending int64 `json:"pending" example:"75"`
+	// Outstanding is Balance plus Pending; it decides whether checkouts are blocked.
+	Outstanding     int64              `json:"outstanding" example:"325"`
+	CheckoutBlocked bool               `json:"checkout_blocked" example:"false"`
+	Entries         []models.FineEntry `json:"entries"`
+}
+
+// CalculateFine returns the fine for a loan returned at returnedAt under the
+// given rules. Days are counted from the due date, rounding partial days up;
+// the grace days are free and the total is capped at MaxAmount.
+func CalculateFine(loan models.Loan, rules config.FineRules, returnedAt time.Time) int64 {
+	late := returnedAt.Sub(loan.DueAt)
+	if late <= 0 {
+		return 0
+	}
+
+	day := 24 * time.Hour
+	days := int((late + day - 1) / day)
+	chargeable := days - rules.GraceDays
+	if chargeable <= 0 {
+		return 0
+	}
+
+	fine := int64(chargeable) * rules.DailyRate
+	if rules.MaxAmount > 0 && fine > rules.MaxAmount {
+		fine = rules.MaxAmount
+	}
+	return fine
+}
+
+// GetFineSummary returns the ledger, the balance and the fines still accruing
+// on overdue loans of a user.
+func GetFineSummary(db *gorm.DB, userID uint, now time.Time) (FineSummary, error) {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return FineSummary{}, notFound(err, ErrUserNotFound)
+	}
+
+	summary := FineSummary{UserID: user.ID, Entries: []models.FineEntry{}}
+	if err := db.Where("user_id = ?", user.ID).Order("id ASC").Find(&summary.Entries).Error; err != nil {
+		return FineSummary{}, err
+	}
+	summary.Balance = ledgerBalance(summary.Entries)
+
+	pending, err := pendingFines(db, user, now)
+	if err != nil {
+		return FineSummary{}, err
+	}
+	summary.Pending = pending
+	summary.Outstanding = summary.Balance + summary.Pending
+	summary.CheckoutBlocked = summary.Outstanding > config.FineBlockThreshold()
+	return summary, nil
+}
+
+// RecordFineEntry records a payment or a waiver against a user's balance. The
+// user row is locked so concurrent payments cannot both settle the same debt.
+// An entry that names a loan must name one of the user's loans.
+func RecordFineEntry(db *gorm.DB, userID uint, entry models.FineEntry) (models.FineEntry, error) {
+	if (entry.Kind != models.FineEntryPayment && entry.Kind != models.FineEntryWaiver) || entry.Amount <= 0 {
+		return models.FineEntry{}, ErrInvalidFineEntry
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return notFound(err, ErrUserNotFound)
+		}
+
+		if entry.LoanID != nil {
+			var loan models.Loan
+			if err := tx.Where("user_id = ?", user.ID).First(&loan, *entry.LoanID).Error; err != nil {
+				return notFound(err, ErrFineLoanNotFound)
+			}
+		}
+
+		var entries []models.FineEntry
+		if err := tx.Where("user_id = ?", user.ID).Find(&entries).Error; err != nil {
+			return err
+		}
+		if entry.Amount > ledgerBalance(entries) {
+			return ErrPaymentExceedsBalance
+		}
+
+		entry.ID = 0
+		entry.UserID = user.ID
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return models.FineEntry{}, err
+	}
+	return entry, nil
+}
+
+// chargeOverdueFine adds the fine for a returned loan to the borrower's ledger.
+func chargeOverdueFine(tx *gorm.DB, loan models.Loan) error {
+	var user models.User
+	if err := tx.First(&user, loan.UserID).Error; err != nil {
+		return err
+	}
+
+	fine := CalculateFine(loan, config.FineRulesFor(user.MembershipTier), *loan.ReturnedAt)
+	if fine == 0 {
+		return nil
+	}
+
+	return tx.Create(&models.FineEntry{
+		UserID: user.ID,
+		LoanID: &loan.ID,
+		Kind:   models.FineEntryCharge,
+		Amount: fine,
+		Note:   fmt.Sprintf("Loan %d returned after its due date", loan.ID),
+	}).Error
+}
+
+// checkFineBalance refuses new checkouts for users whose outstanding fines are
+// above the configured threshold.
+func checkFineBalance(tx *gorm.DB, user models.User, now time.Time) error {
+	var entries []models.FineEntry
+	if err := tx.Where("user_id = ?", user.ID).Find(&entries).Error; err != nil {
+		return err
+	}
+	pending, err := pendingFines(tx, user, now)
+	if err != nil {
+		return err
+	}
+	if ledgerBalance(entries)+pending > config.FineBlockThreshold() {
+		return ErrFineBalanceTooHigh
+	}
+	return nil
+}
+
+func pendingFines(db *gorm.DB, user models.User, now time.Time) (int64, error) {
+	var loans []models.Loan
+	if err := db.Where("user_id = ? AND returned_at IS NULL AND due_at < ?", user.ID, now).Find(&loans).Error; err != nil {
+		return 0, err
+	}
+
+	rules := config.FineRulesFor(user.MembershipTier)
+	var pending int64
+	for _, loan := range loans {
+		pending += CalculateFine(loan, rules, now)
+	}
+	return pending, nil
+}
+
+func ledgerBalance(entries []models.FineEntry) int64 {
+	var balance int64
+	for _, entry := range entries {
+		if entry.Kind == models.FineEntryCharge {
+			balance += entry.Amount
+		} else {
+			balance -= entry.Amount
+		}
+	}
+	return balance
+}
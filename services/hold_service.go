@@ -0,0 +1,214 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrHoldNotFound      = errors.New("hold not found")
+	ErrCopyAvailable     = errors.New("a copy of this book is available")
+	ErrDuplicateHold     = errors.New("user already has a hold on this book")
+	ErrHoldNotCancelable = errors.New("hold is no longer active")
+)
+
+// PlaceHold puts the user at the back of the queue for a book. Holds are only
+// accepted while no copy is available to check out.
+func PlaceHold(db *gorm.DB, bookID, userID uint) (models.Hold, error) {
+	var hold models.Hold
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var book models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, bookID).Error; err != nil {
+			return notFound(err, ErrBookNotFound)
+		}
+
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return notFound(err, ErrUserNotFound)
+		}
+
+		var available int64
+		if err := tx.Model(&models.Copy{}).Where("book_id = ? AND status = ?", book.ID, models.CopyStatusAvailable).Count(&available).Error; err != nil {
+			return err
+		}
+		if available > 0 {
+			return ErrCopyAvailable
+		}
+
+		var existing int64
+		err := tx.Model(&models.Hold{}).
+			Where("book_id = ? AND user_id = ? AND status IN ?", book.ID, user.ID, activeHoldStatuses).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrDuplicateHold
+		}
+
+		hold = models.Hold{BookID: book.ID, UserID: user.ID, Status: models.HoldStatusWaiting}
+		return tx.Create(&hold).Error
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	if err := AttachHoldPosition(db, &hold); err != nil {
+		return models.Hold{}, err
+	}
+	return hold, nil
+}
+
+// CancelHold withdraws a hold. A copy that was set aside for it goes to the
+// next person in line.
+func CancelHold(db *gorm.DB, holdID uint, now time.Time) (models.Hold, error) {
+	var hold models.Hold
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, holdID).Error; err != nil {
+			return notFound(err, ErrHoldNotFound)
+		}
+		if hold.Status != models.HoldStatusWaiting && hold.Status != models.HoldStatusReady {
+			return ErrHoldNotCancelable
+		}
+
+		return closeHold(tx, &hold, models.HoldStatusCancelled, now)
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return hold, nil
+}
+
+// ExpireHolds closes every ready hold whose pickup deadline has passed and
+// hands its copy to the next hold in the queue. It returns the number of holds
+// that expired.
+func ExpireHolds(db *gorm.DB, now time.Time) (int, error) {
+	expired := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var holds []models.Hold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND pickup_deadline < ?", models.HoldStatusReady, now).
+			Order("pickup_deadline ASC").
+			Find(&holds).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range holds {
+			if err := closeHold(tx, &holds[i], models.HoldStatusExpired, now); err != nil {
+				return err
+			}
+			expired++
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// RunHoldExpiry sweeps expired holds every interval. It blocks, so it is meant
+// to be started in its own goroutine.
+func RunHoldExpiry(db *gorm.DB, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		expired, err := ExpireHolds(db, now)
+		if err != nil {
+			config.Log.WithError(err).Error("Error expiring holds")
+			continue
+		}
+		if expired > 0 {
+			config.Log.WithField("expired", expired).Info("Expired holds")
+		}
+	}
+}
+
+// AttachHoldPositions fills in the queue position of every waiting hold.
+func AttachHoldPositions(db *gorm.DB, holds []models.Hold) error {
+	for i := range holds {
+		if err := AttachHoldPosition(db, &holds[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AttachHoldPosition fills in the queue position of a waiting hold, counting
+// from 1 for the next person in line.
+func AttachHoldPosition(db *gorm.DB, hold *models.Hold) error {
+	hold.Position = 0
+	if hold.Status != models.HoldStatusWaiting {
+		return nil
+	}
+
+	var ahead int64
+	err := db.Model(&models.Hold{}).
+		Where("book_id = ? AND status = ? AND id < ?", hold.BookID, models.HoldStatusWaiting, hold.ID).
+		Count(&ahead).Error
+	if err != nil {
+		return err
+	}
+	hold.Position = int(ahead) + 1
+	return nil
+}
+
+var activeHoldStatuses = []string{models.HoldStatusWaiting, models.HoldStatusReady}
+
+// closeHold moves a hold to a final status and releases the copy set aside for
+// it, if any.
+func closeHold(tx *gorm.DB, hold *models.Hold, status string, now time.Time) error {
+	copyID := hold.CopyID
+	hold.Status = status
+	if err := tx.Save(hold).Error; err != nil {
+		return err
+	}
+	if copyID == nil {
+		return nil
+	}
+
+	var bookCopy models.Copy
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, *copyID).Error; err != nil {
+		return err
+	}
+	return releaseCopy(tx, &bookCopy, now)
+}
+
+// releaseCopy puts a copy back into circulation. When someone is waiting for
+// the book the copy is set aside for the oldest waiting hold, otherwise it
+// goes back on the shelf. The caller must hold a lock on the copy row. The
+// queue head is locked without skipping so a concurrent release waits for it
+// instead of serving a later hold first.
+func releaseCopy(tx *gorm.DB, bookCopy *models.Copy, now time.Time) error {
+	var hold models.Hold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookCopy.BookID, models.HoldStatusWaiting).
+		Order("id ASC").
+		First(&hold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bookCopy.Status = models.CopyStatusAvailable
+		return tx.Save(bookCopy).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	deadline := now.Add(config.HoldPickupPeriod())
+	hold.Status = models.HoldStatusReady
+	hold.CopyID = &bookCopy.ID
+	hold.ReadyAt = &now
+	hold.PickupDeadline = &deadline
+	if err := tx.Save(&hold).Error; err != nil {
+		return err
+	}
+
+	bookCopy.Status = models.CopyStatusOnHold
+	return tx.Save(bookCopy).Error
+}
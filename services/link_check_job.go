@@ -0,0 +1,231 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLinkCheckJobNotFound = errors.New("link check job not found")
+	ErrEmptyLinkCheck       = errors.New("link check has no urls")
+	ErrLinkCheckTooLarge    = errors.New("link check has too many urls")
+	ErrLinkCheckStopped     = errors.New("link check stopped before it finished")
+)
+
+// Background jobs run under linkCheckJobs, which StopLinkCheckJobs cancels on
+// shutdown. runningLinkCheckJobs lets it wait for them to record their state.
+var (
+	linkCheckJobs, cancelLinkCheckJobs = context.WithCancel(context.Background())
+	runningLinkCheckJobs               sync.WaitGroup
+)
+
+// LinkTarget is a URL to check and where it came from.
+type LinkTarget struct {
+	URL    string
+	Source string
+}
+
+const (
+	LinkSourceRequest = "request"
+	LinkSourcePage    = "page"
+)
+
+// RedirectTargetLinks returns the external targets of redirect rules, the
+// outbound links of the site. Relative targets and regex targets with group
+// references are skipped.
+func RedirectTargetLinks(rules []models.RedirectRule) []LinkTarget {
+	var links []LinkTarget
+	for _, rule := range rules {
+		target := strings.ToLower(rule.Target)
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			continue
+		}
+		if rule.MatchType == models.RedirectMatchRegex && strings.Contains(rule.Target, "$") {
+			continue
+		}
+		links = append(links, LinkTarget{URL: rule.Target, Source: fmt.Sprintf("redirect:%d", rule.ID)})
+	}
+	return links
+}
+
+// BookPageLinks returns the canonical page URL of every book, as listed in the
+// sitemap.
+func BookPageLinks(db *gorm.DB, sitemap BookSitemap) ([]LinkTarget, error) {
+	var books []models.Book
+	if err := db.Select("id", "slug", "updated_at").Order("id").Find(&books).Error; err != nil {
+		return nil, err
+	}
+	urlSet, err := sitemap.BuildURLSet(books)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]LinkTarget, len(books))
+	for i, book := range books {
+		links[i] = LinkTarget{URL: urlSet.URLs[i].Loc, Source: fmt.Sprintf("book:%d", book.ID)}
+	}
+	return links, nil
+}
+
+// StartLinkCheckJob stores a new job and runs it in the background. links are
+// checked as they are; pages are checked and the links found on them are
+// checked as well, up to maxURLs URLs in total. A job still running after
+// timeout, or at shutdown, is stopped and marked failed.
+func StartLinkCheckJob(db *gorm.DB, checker *LinkChecker, links, pages []LinkTarget, maxURLs int, timeout time.Duration) (models.LinkCheckJob, error) {
+	if len(links)+len(pages) == 0 {
+		return models.LinkCheckJob{}, ErrEmptyLinkCheck
+	}
+	if maxURLs > 0 && len(links)+len(pages) > maxURLs {
+		return models.LinkCheckJob{}, ErrLinkCheckTooLarge
+	}
+
+	job := models.LinkCheckJob{Status: models.LinkCheckStatusPending}
+	if err := db.Create(&job).Error; err != nil {
+		return models.LinkCheckJob{}, err
+	}
+
+	runningLinkCheckJobs.Add(1)
+	go func(job models.LinkCheckJob) {
+		defer runningLinkCheckJobs.Done()
+		ctx, cancel := context.WithTimeout(linkCheckJobs, timeout)
+		defer cancel()
+		if err := RunLinkCheckJob(ctx, db, checker, &job, links, pages, maxURLs); err != nil {
+			config.Log.WithError(err).WithField("job", job.ID).Error("Error running link check")
+		}
+	}(job)
+	return job, nil
+}
+
+// StopLinkCheckJobs stops the background jobs and waits until they are marked
+// failed.
+func StopLinkCheckJobs() {
+	cancelLinkCheckJobs()
+	runningLinkCheckJobs.Wait()
+}
+
+// RunLinkCheckJob runs a stored job to completion, saving every result as it
+// comes in. When it fails, or ctx is done first, the job is marked failed
+// with the error.
+func RunLinkCheckJob(ctx context.Context, db *gorm.DB, checker *LinkChecker, job *models.LinkCheckJob, links, pages []LinkTarget, maxURLs int) error {
+	err := runLinkCheckJob(ctx, db, checker, job, links, pages, maxURLs)
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = models.LinkCheckStatusDone
+	if err != nil {
+		job.Status = models.LinkCheckStatusFailed
+		job.Error = err.Error()
+	}
+	if saveErr := db.Save(job).Error; saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+func runLinkCheckJob(ctx context.Context, db *gorm.DB, checker *LinkChecker, job *models.LinkCheckJob, links, pages []LinkTarget, maxURLs int) error {
+	// Each URL is checked once, the first source it was listed with wins.
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		seen[page.URL] = true
+	}
+	unique := make([]LinkTarget, 0, len(links))
+	for _, link := range links {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			unique = append(unique, link)
+		}
+	}
+	links = unique
+
+	job.Status = models.LinkCheckStatusRunning
+	job.Total = len(links) + len(pages)
+	if err := db.Save(job).Error; err != nil {
+		return err
+	}
+
+	// save records a result and the job counters. It is only called from
+	// this goroutine, results of CheckAll are handed over through a channel.
+	save := func(target LinkTarget, result models.LinkCheckResult) error {
+		now := time.Now()
+		result.JobID = job.ID
+		result.URL = target.URL
+		result.Source = target.Source
+		result.CheckedAt = &now
+		if err := db.Create(&result).Error; err != nil {
+			return err
+		}
+
+		job.Checked++
+		if result.Broken {
+			job.Broken++
+		}
+		return db.Model(job).Updates(map[string]interface{}{"total": job.Total, "checked": job.Checked, "broken": job.Broken}).Error
+	}
+
+	for _, page := range pages {
+		if ctx.Err() != nil {
+			break
+		}
+		result, found := checker.CheckPage(ctx, page.URL)
+		if err := save(page, result); err != nil {
+			return err
+		}
+		for _, link := range found {
+			if seen[link] || (maxURLs > 0 && job.Total >= maxURLs) {
+				continue
+			}
+			seen[link] = true
+			links = append(links, LinkTarget{URL: link, Source: page.URL})
+			job.Total++
+		}
+	}
+
+	type checked struct {
+		index  int
+		result models.LinkCheckResult
+	}
+	results := make(chan checked)
+	go func() {
+		urls := make([]string, len(links))
+		for i, link := range links {
+			urls[i] = link.URL
+		}
+		checker.CheckAll(ctx, urls, func(i int, result models.LinkCheckResult) {
+			results <- checked{i, result}
+		})
+		close(results)
+	}()
+
+	// Results that come in after ctx is done failed because of it and are
+	// not saved as broken links.
+	var saveErr error
+	for checked := range results {
+		if saveErr == nil && ctx.Err() == nil {
+			saveErr = save(links[checked.index], checked.result)
+		}
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrLinkCheckStopped, err)
+	}
+	return nil
+}
+
+// GetLinkCheckJob returns a job with its progress.
+func GetLinkCheckJob(db *gorm.DB, id uint) (models.LinkCheckJob, error) {
+	var job models.LinkCheckJob
+	if err := db.First(&job, id).Error; err != nil {
+		return models.LinkCheckJob{}, notFound(err, ErrLinkCheckJobNotFound)
+	}
+	return job, nil
+}
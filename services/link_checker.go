@@ -0,0 +1,337 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxLinkCheckPageSize caps how much of a crawled page is read for links.
+const maxLinkCheckPageSize = 5 << 20
+
+var (
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrRedirectCycle    = errors.New("redirects lead back to a url already visited")
+)
+
+// LinkChecker fetches URLs and records their status code and redirect chain.
+// At most Concurrency URLs are fetched at once, requests to the same host are
+// made one at a time with HostDelay between them, and every URL, including
+// redirect targets, has to pass Policy. Unless Policy allows private hosts,
+// connections to private addresses are refused after DNS resolution too.
+type LinkChecker struct {
+	Concurrency  int
+	HostDelay    time.Duration
+	Timeout      time.Duration
+	MaxRedirects int
+	UserAgent    string
+	Policy       URLPolicy
+
+	once   sync.Once
+	client *http.Client
+	mu     sync.Mutex
+	hosts  map[string]*hostGate
+}
+
+// hostGate serializes the requests to one host. last is only touched while
+// the token is held.
+type hostGate struct {
+	token chan struct{}
+	last  time.Time
+}
+
+// DefaultLinkChecker returns a link checker set up from the configuration.
+func DefaultLinkChecker() *LinkChecker {
+	return &LinkChecker{
+		Concurrency:  config.LinkCheckConcurrency(),
+		HostDelay:    config.LinkCheckHostDelay(),
+		Timeout:      config.LinkCheckTimeout(),
+		MaxRedirects: config.LinkCheckMaxRedirects(),
+		UserAgent:    config.LinkCheckUserAgent(),
+		Policy:       DefaultURLPolicy(),
+	}
+}
+
+func (lc *LinkChecker) init() {
+	lc.once.Do(func() {
+		dialer := &net.Dialer{Timeout: lc.Timeout}
+		if !lc.Policy.AllowPrivate {
+			dialer.Control = refusePrivateAddress
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+
+		lc.client = &http.Client{
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		lc.hosts = make(map[string]*hostGate)
+	})
+}
+
+// refusePrivateAddress stops connections to the addresses URLPolicy rejects
+// as IP literals, so a public name resolving to one is refused as well.
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if reason := privateHost(host); reason != "" {
+		return fmt.Errorf("address %s is %s", host, reason)
+	}
+	return nil
+}
+
+// Check fetches rawURL, following up to MaxRedirects redirects. The result is
+// broken when the URL is rejected, cannot be fetched or ends in a 4xx or 5xx.
+func (lc *LinkChecker) Check(ctx context.Context, rawURL string) models.LinkCheckResult {
+	result, _ := lc.fetch(ctx, rawURL, false)
+	return result
+}
+
+// CheckPage fetches a page like Check and returns the http(s) links of its
+// <a href> tags, resolved, without fragment and deduplicated.
+func (lc *LinkChecker) CheckPage(ctx context.Context, pageURL string) (models.LinkCheckResult, []string) {
+	result, body := lc.fetch(ctx, pageURL, true)
+	if result.Broken || body == nil {
+		return result, nil
+	}
+	base, err := url.Parse(result.FinalURL)
+	if err != nil {
+		return result, nil
+	}
+	return result, extractLinks(bytes.NewReader(body), base)
+}
+
+// CheckAll checks urls with at most Concurrency requests in flight and calls
+// report with the index and result of each URL as soon as it is done.
+// report may be called from several goroutines at once. Once ctx is done no
+// further URLs are started.
+func (lc *LinkChecker) CheckAll(ctx context.Context, urls []string, report func(int, models.LinkCheckResult)) {
+	workers := lc.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(urls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				report(i, lc.Check(ctx, urls[i]))
+			}
+		}()
+	}
+
+	for i := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+func (lc *LinkChecker) fetch(ctx context.Context, rawURL string, readBody bool) (models.LinkCheckResult, []byte) {
+	lc.init()
+	started := time.Now()
+	result := models.LinkCheckResult{URL: rawURL, Redirects: []models.LinkHop{}}
+	fail := func(err error) (models.LinkCheckResult, []byte) {
+		result.Broken = true
+		result.Error = err.Error()
+		result.DurationMs = time.Since(started).Milliseconds()
+		return result, nil
+	}
+
+	current, err := lc.Policy.ValidateURL(rawURL)
+	if err != nil {
+		return fail(err)
+	}
+	seen := map[string]bool{current.String(): true}
+
+	for {
+		status, location, body, err := lc.request(ctx, current, readBody)
+		if err != nil {
+			return fail(err)
+		}
+		result.StatusCode = status
+		result.FinalURL = current.String()
+
+		if status < 300 || status >= 400 || location == "" {
+			result.Broken = status >= 400
+			result.DurationMs = time.Since(started).Milliseconds()
+			return result, body
+		}
+
+		next, err := current.Parse(location)
+		if err != nil {
+			return fail(fmt.Errorf("invalid redirect location %q", location))
+		}
+		next.Fragment = ""
+		result.Redirects = append(result.Redirects, models.LinkHop{URL: current.String(), StatusCode: status, Location: next.String()})
+		if len(result.Redirects) > lc.MaxRedirects {
+			return fail(ErrTooManyRedirects)
+		}
+		if seen[next.String()] {
+			return fail(ErrRedirectCycle)
+		}
+		seen[next.String()] = true
+
+		if current, err = lc.Policy.ValidateURL(next.String()); err != nil {
+			return fail(fmt.Errorf("redirect to %s: %w", next, err))
+		}
+	}
+}
+
+// request makes one request to u, waiting for its turn at the host. It tries
+// HEAD first unless the body is needed and falls back to GET when the server
+// does not support HEAD.
+func (lc *LinkChecker) request(ctx context.Context, u *url.URL, readBody bool) (int, string, []byte, error) {
+	release, err := lc.acquire(ctx, u.Host)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	defer release()
+
+	methods := []string{http.MethodHead, http.MethodGet}
+	if readBody {
+		methods = methods[1:]
+	}
+
+	for _, method := range methods {
+		status, location, body, err := lc.do(ctx, method, u, readBody)
+		if err != nil {
+			return 0, "", nil, err
+		}
+		if method == http.MethodHead && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+			continue
+		}
+		return status, location, body, nil
+	}
+	return 0, "", nil, errors.New("no request was made")
+}
+
+func (lc *LinkChecker) do(ctx context.Context, method string, u *url.URL, readBody bool) (int, string, []byte, error) {
+	if lc.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lc.Timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if lc.UserAgent != "" {
+		request.Header.Set("User-Agent", lc.UserAgent)
+	}
+
+	response, err := lc.client.Do(request)
+	if err != nil {
+		return 0, "", nil, unwrapURLError(err)
+	}
+	defer response.Body.Close()
+
+	var body []byte
+	if readBody && response.StatusCode < 300 && strings.Contains(response.Header.Get("Content-Type"), "html") {
+		if body, err = io.ReadAll(io.LimitReader(response.Body, maxLinkCheckPageSize)); err != nil {
+			return 0, "", nil, err
+		}
+	} else {
+		io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	}
+	return response.StatusCode, response.Header.Get("Location"), body, nil
+}
+
+// acquire waits until no other request to host is in flight and HostDelay
+// has passed since the last one. The returned function gives the host back.
+func (lc *LinkChecker) acquire(ctx context.Context, host string) (func(), error) {
+	lc.mu.Lock()
+	gate, ok := lc.hosts[host]
+	if !ok {
+		gate = &hostGate{token: make(chan struct{}, 1)}
+		lc.hosts[host] = gate
+	}
+	lc.mu.Unlock()
+
+	select {
+	case gate.token <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if wait := time.Until(gate.last.Add(lc.HostDelay)); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			<-gate.token
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		gate.last = time.Now()
+		<-gate.token
+	}, nil
+}
+
+// extractLinks returns the http(s) targets of the <a href> tags of a page.
+func extractLinks(r io.Reader, base *url.URL) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "base":
+				if href, ok := htmlAttribute(token, "href"); ok {
+					base = resolveReference(base, href)
+				}
+			case "a":
+				href, ok := htmlAttribute(token, "href")
+				if !ok {
+					continue
+				}
+				parsed, err := url.Parse(href)
+				if err != nil {
+					continue
+				}
+				link := base.ResolveReference(parsed)
+				if link.Scheme != "http" && link.Scheme != "https" {
+					continue
+				}
+				link.Fragment = ""
+				if value := link.String(); !seen[value] {
+					seen[value] = true
+					links = append(links, value)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBookNotFound        = errors.New("book not found")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrLoanNotFound        = errors.New("loan not found")
+	ErrNoCopyAvailable     = errors.New("no copy of this book is available")
+	ErrLoanAlreadyReturned = errors.New("loan has already been returned")
+	ErrRenewalLimitReached = errors.New("loan has reached the maximum number of renewals")
+	ErrLoanOverdue         = errors.New("overdue loans cannot be renewed")
+)
+
+// CheckoutBook lends a copy of the book to the user unless their outstanding
+// fines are above the configured threshold. It takes the copy set aside for
+// the user's ready hold if there is one, otherwise an available copy. The chosen
+// copy is locked for the duration of the transaction and copies locked by a
+// concurrent checkout are skipped, so two borrowers never get the same copy.
+func CheckoutBook(db *gorm.DB, bookID, userID uint, now time.Time) (models.Loan, error) {
+	var loan models.Loan
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var book models.Book
+		if err := tx.First(&book, bookID).Error; err != nil {
+			return notFound(err, ErrBookNotFound)
+		}
+
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return notFound(err, ErrUserNotFound)
+		}
+		if err := checkFineBalance(tx, user, now); err != nil {
+			return err
+		}
+
+		bookCopy, err := takeCopy(tx, book.ID, user.ID)
+		if err != nil {
+			return err
+		}
+
+		bookCopy.Status = models.CopyStatusOnLoan
+		if err := tx.Save(&bookCopy).Error; err != nil {
+			return err
+		}
+
+		loan = models.Loan{
+			BookID:       book.ID,
+			CopyID:       bookCopy.ID,
+			UserID:       user.ID,
+			CheckedOutAt: now,
+			DueAt:        now.Add(config.LoanPeriod()),
+		}
+		return tx.Create(&loan).Error
+	})
+	if err != nil {
+		return models.Loan{}, err
+	}
+
+	loan.Status = loan.StatusAt(now)
+	return loan, nil
+}
+
+// ReturnLoan marks the loan as returned and charges the borrower for any days
+// it was overdue. Its copy goes to the next hold in the queue, or back on the
+// shelf when nobody is waiting.
+func ReturnLoan(db *gorm.DB, loanID uint, now time.Time) (models.Loan, error) {
+	var loan models.Loan
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+			return notFound(err, ErrLoanNotFound)
+		}
+		if loan.ReturnedAt != nil {
+			return ErrLoanAlreadyReturned
+		}
+
+		loan.ReturnedAt = &now
+		if err := tx.Save(&loan).Error; err != nil {
+			return err
+		}
+		if err := chargeOverdueFine(tx, loan); err != nil {
+			return err
+		}
+
+		var bookCopy models.Copy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, loan.CopyID).Error; err != nil {
+			return err
+		}
+		if bookCopy.Status != models.CopyStatusOnLoan {
+			return nil
+		}
+		return releaseCopy(tx, &bookCopy, now)
+	})
+	if err != nil {
+		return models.Loan{}, err
+	}
+
+	loan.Status = loan.StatusAt(now)
+	return loan, nil
+}
+
+// RenewLoan extends the due date of an active loan by another loan period.
+func RenewLoan(db *gorm.DB, loanID uint, now time.Time) (models.Loan, error) {
+	var loan models.Loan
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+			return notFound(err, ErrLoanNotFound)
+		}
+		switch {
+		case loan.ReturnedAt != nil:
+			return ErrLoanAlreadyReturned
+		case now.After(loan.DueAt):
+			return ErrLoanOverdue
+		case loan.Renewals >= config.MaxLoanRenewals():
+			return ErrRenewalLimitReached
+		}
+
+		loan.Renewals++
+		loan.DueAt = loan.DueAt.Add(config.LoanPeriod())
+		return tx.Save(&loan).Error
+	})
+	if err != nil {
+		return models.Loan{}, err
+	}
+
+	loan.Status = loan.StatusAt(now)
+	return loan, nil
+}
+
+// takeCopy locks the copy the user is about to borrow. A copy set aside for
+// one of the user's ready holds takes precedence and fulfils that hold. A
+// shelf copy fulfils the user's waiting hold on the book, if any, so it does
+// not stay in the queue.
+func takeCopy(tx *gorm.DB, bookID, userID uint) (models.Copy, error) {
+	var bookCopy models.Copy
+
+	var hold models.Hold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND user_id = ? AND status = ?", bookID, userID, models.HoldStatusReady).
+		First(&hold).Error
+	switch {
+	case err == nil:
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, *hold.CopyID).Error; err != nil {
+			return models.Copy{}, err
+		}
+		hold.Status = models.HoldStatusFulfilled
+		if err := tx.Save(&hold).Error; err != nil {
+			return models.Copy{}, err
+		}
+		return bookCopy, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return models.Copy{}, err
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("book_id = ? AND status = ?", bookID, models.CopyStatusAvailable).
+		Order("id ASC").
+		First(&bookCopy).Error
+	if err != nil {
+		return models.Copy{}, notFound(err, ErrNoCopyAvailable)
+	}
+
+	err = tx.Model(&models.Hold{}).
+		Where("book_id = ? AND user_id = ? AND status = ?", bookID, userID, models.HoldStatusWaiting).
+		Update("status", models.HoldStatusFulfilled).Error
+	if err != nil {
+		return models.Copy{}, err
+	}
+	return bookCopy, nil
+}
+
+// notFound translates gorm's record-not-found error into a domain specific one.
+func notFound(err error, target error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return target
+	}
+	return err
+}
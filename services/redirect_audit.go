@@ -0,0 +1,208 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RedirectChainPolicy decides how saving a redirect rule treats the chains it
+// takes part in. Loops are always rejected.
+type RedirectChainPolicy struct {
+	// MaxLength is the number of hops a chain may have before it is reported.
+	MaxLength int
+	// MaxHops bounds how far chains are followed.
+	MaxHops int
+	// Flatten re-points the exact rules of too long chains at the final target.
+	Flatten bool
+}
+
+// RedirectChain is a path through the redirect rules, starting at the source
+// of the first rule.
+type RedirectChain struct {
+	RuleIDs []uint `json:"rule_ids" example:"1,2"`
+	// URLs holds the start followed by the target of every hop.
+	URLs   []string `json:"urls" example:"/a,/b,/c"`
+	Length int      `json:"length" example:"2"`
+	// TooLong is set when the chain has more hops than the configured maximum.
+	TooLong bool `json:"too_long" example:"true"`
+}
+
+// RedirectAudit lists every chain of two or more hops and every loop among
+// the enabled redirect rules.
+type RedirectAudit struct {
+	MaxChainLength int             `json:"max_chain_length" example:"1"`
+	Chains         []RedirectChain `json:"chains"`
+	Loops          []RedirectChain `json:"loops"`
+}
+
+// AuditRedirects audits the enabled redirect rules.
+func AuditRedirects(db *gorm.DB, maxChainLength, maxHops int) (RedirectAudit, error) {
+	rules, err := EnabledRedirectRules(db)
+	if err != nil {
+		return RedirectAudit{}, err
+	}
+	return AuditRedirectRules(rules, maxChainLength, maxHops)
+}
+
+// AuditRedirectRules follows the rules, which must be in priority order, from
+// the source of every exact and prefix rule. Regex rules take part in chains
+// but are not used as starting points since their sources are patterns.
+// Chains contained in a longer chain are left out, and each loop is reported
+// once.
+func AuditRedirectRules(rules []models.RedirectRule, maxChainLength, maxHops int) (RedirectAudit, error) {
+	matchers := compileRedirectRules(rules)
+	audit := RedirectAudit{MaxChainLength: maxChainLength, Chains: []RedirectChain{}, Loops: []RedirectChain{}}
+	loops := map[string]bool{}
+
+	for _, rule := range rules {
+		if rule.MatchType == models.RedirectMatchRegex {
+			continue
+		}
+		start, err := url.Parse(rule.Source)
+		if err != nil {
+			continue
+		}
+
+		hops, target, err := followRedirects(matchers, start, maxHops)
+		if errors.Is(err, ErrRedirectLoop) {
+			hops = loopHops(hops, target)
+			loopStart, _ := url.Parse(hops[0].From)
+			loop := redirectChain(loopStart, hops, maxChainLength)
+			if key := loopKey(loop.RuleIDs); !loops[key] {
+				loops[key] = true
+				audit.Loops = append(audit.Loops, loop)
+			}
+			continue
+		}
+		if err != nil {
+			return RedirectAudit{}, err
+		}
+		if len(hops) > 1 {
+			audit.Chains = append(audit.Chains, redirectChain(start, hops, maxChainLength))
+		}
+	}
+
+	audit.Chains = longestRedirectChains(audit.Chains)
+	return audit, nil
+}
+
+// checkRedirectChains audits the enabled rules after rule has been saved in
+// tx. It fails with ErrRedirectRuleLoop when rule is part of a loop. Too long
+// chains through rule are flattened when the policy asks for it and reported
+// as warnings otherwise.
+func checkRedirectChains(tx *gorm.DB, rule models.RedirectRule, policy RedirectChainPolicy) ([]string, error) {
+	rules, err := EnabledRedirectRules(tx)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := AuditRedirectRules(rules, policy.MaxLength, policy.MaxHops)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, loop := range audit.Loops {
+		if containsRuleID(loop.RuleIDs, rule.ID) {
+			return nil, ErrRedirectRuleLoop
+		}
+	}
+
+	warnings := []string{}
+	byID := make(map[uint]models.RedirectRule, len(rules))
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+	for _, chain := range audit.Chains {
+		if !chain.TooLong || !containsRuleID(chain.RuleIDs, rule.ID) {
+			continue
+		}
+		if !policy.Flatten {
+			warnings = append(warnings, fmt.Sprintf("Redirect chain %s has %d hops, more than %d", strings.Join(chain.URLs, " -> "), chain.Length, policy.MaxLength))
+			continue
+		}
+
+		final := chain.URLs[len(chain.URLs)-1]
+		for i, id := range chain.RuleIDs[:len(chain.RuleIDs)-1] {
+			chained := byID[id]
+			if chained.MatchType != models.RedirectMatchExact || chained.Source != chain.URLs[i] {
+				warnings = append(warnings, fmt.Sprintf("Rule %d is part of the chain %s but only exact rules can be flattened", id, strings.Join(chain.URLs, " -> ")))
+				continue
+			}
+			if err := tx.Model(&chained).Update("target", final).Error; err != nil {
+				return nil, err
+			}
+			byID[id] = chained
+			warnings = append(warnings, fmt.Sprintf("Rule %d now redirects %s straight to %s", id, chained.Source, final))
+		}
+	}
+	return warnings, nil
+}
+
+// loopHops drops the hops leading into a loop, keeping those from the URL
+// the loop returns to.
+func loopHops(hops []RedirectHop, target *url.URL) []RedirectHop {
+	for i, hop := range hops {
+		if from, err := url.Parse(hop.From); err == nil && from.EscapedPath() == target.EscapedPath() {
+			return hops[i:]
+		}
+	}
+	return hops
+}
+
+func redirectChain(start *url.URL, hops []RedirectHop, maxChainLength int) RedirectChain {
+	chain := RedirectChain{URLs: []string{start.String()}, Length: len(hops), TooLong: len(hops) > maxChainLength}
+	for _, hop := range hops {
+		chain.RuleIDs = append(chain.RuleIDs, hop.RuleID)
+		chain.URLs = append(chain.URLs, hop.To)
+	}
+	return chain
+}
+
+// longestRedirectChains drops the chains whose rules are the tail of another
+// chain, e.g. B->C when A->B->C is reported.
+func longestRedirectChains(chains []RedirectChain) []RedirectChain {
+	longest := []RedirectChain{}
+	for i, chain := range chains {
+		contained := false
+		for j, other := range chains {
+			if i != j && len(other.RuleIDs) > len(chain.RuleIDs) && hasRuleIDSuffix(other.RuleIDs, chain.RuleIDs) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			longest = append(longest, chain)
+		}
+	}
+	return longest
+}
+
+func hasRuleIDSuffix(ids, suffix []uint) bool {
+	offset := len(ids) - len(suffix)
+	for i, id := range suffix {
+		if ids[offset+i] != id {
+			return false
+		}
+	}
+	return true
+}
+
+func containsRuleID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func loopKey(ids []uint) string {
+	sorted := append([]uint(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return fmt.Sprint(sorted)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// redirectMatcherCache holds the compiled enabled redirect rules, so that
+// resolving a request does not load and compile every rule from the database.
+// Saving or deleting a rule through the service invalidates it.
+type redirectMatcherCache struct {
+	mu       sync.RWMutex
+	matchers []redirectMatcher
+	loaded   bool
+	// generation counts invalidations, so that rules loaded before one are
+	// not kept afterwards.
+	generation uint64
+}
+
+var redirectMatchers redirectMatcherCache
+
+// InvalidateRedirectRules makes the next resolution load the redirect rules
+// from the database again. It is needed after changing rules without the
+// service.
+func InvalidateRedirectRules() {
+	redirectMatchers.invalidate()
+}
+
+// get returns the compiled enabled rules in priority order, loading them
+// from db when they are not cached.
+func (c *redirectMatcherCache) get(db *gorm.DB) ([]redirectMatcher, error) {
+	c.mu.RLock()
+	matchers, loaded, generation := c.matchers, c.loaded, c.generation
+	c.mu.RUnlock()
+	if loaded {
+		return matchers, nil
+	}
+
+	rules, err := EnabledRedirectRules(db)
+	if err != nil {
+		return nil, err
+	}
+	matchers = compileRedirectRules(rules)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.matchers, c.loaded = matchers, true
+	}
+	return matchers, nil
+}
+
+func (c *redirectMatcherCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.matchers, c.loaded = nil, false
+	c.generation++
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"bufio"
+	"byfood-test-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RedirectFormatNginx  = "nginx"
+	RedirectFormatApache = "apache"
+	RedirectFormatVercel = "vercel"
+)
+
+var (
+	ErrUnknownRedirectFormat = errors.New("redirect format must be nginx, apache or vercel")
+	ErrInvalidRedirectConfig = errors.New("redirect configuration cannot be read")
+)
+
+// ParsedRedirectRule is a rule read from a server configuration.
+type ParsedRedirectRule struct {
+	Line int                 `json:"line" example:"3"`
+	Rule RedirectRuleChanges `json:"rule"`
+}
+
+// RedirectImportIssue is a line of an imported configuration that did not
+// become a rule.
+type RedirectImportIssue struct {
+	Line   int    `json:"line" example:"7"`
+	Text   string `json:"text" example:"RewriteCond %{HTTP_HOST} ^byfood.com$"`
+	Reason string `json:"reason" example:"RewriteCond is not supported"`
+}
+
+// RedirectImport is the outcome of importing a server configuration.
+type RedirectImport struct {
+	Imported []models.RedirectRule `json:"imported"`
+	Unparsed []RedirectImportIssue `json:"unparsed"`
+	Warnings []string              `json:"warnings,omitempty"`
+}
+
+// RedirectExport is a server configuration holding exported rules, with a
+// warning for every rule the format cannot express.
+type RedirectExport struct {
+	Config   string
+	Warnings []string
+}
+
+// ExportRedirectRules renders rules, in priority order, as an nginx map
+// block, Apache RewriteRules or the redirects of a vercel.json.
+func ExportRedirectRules(rules []models.RedirectRule, format string) (RedirectExport, error) {
+	switch format {
+	case RedirectFormatNginx:
+		return RedirectExport{Config: exportNginx(rules)}, nil
+	case RedirectFormatApache:
+		return RedirectExport{Config: exportApache(rules)}, nil
+	case RedirectFormatVercel:
+		return exportVercel(rules)
+	default:
+		return RedirectExport{}, ErrUnknownRedirectFormat
+	}
+}
+
+// ParseRedirectRules reads rules from a configuration in the given format.
+// Lines that are not redirects are reported as issues; structural lines such
+// as comments, braces and RewriteEngine are skipped silently.
+func ParseRedirectRules(data string, format string) ([]ParsedRedirectRule, []RedirectImportIssue, error) {
+	switch format {
+	case RedirectFormatNginx:
+		rules, issues := parseNginx(data)
+		return rules, issues, nil
+	case RedirectFormatApache:
+		rules, issues := parseApache(data)
+		return rules, issues, nil
+	case RedirectFormatVercel:
+		return parseVercel(data)
+	default:
+		return nil, nil, ErrUnknownRedirectFormat
+	}
+}
+
+// ImportRedirectRules parses data and stores every rule it holds, checking
+// each one like CreateRedirectRule. Rules that cannot be stored are reported
+// with the lines that could not be parsed. With dryRun nothing is stored and
+// Imported holds the parsed rules without IDs.
+func ImportRedirectRules(db *gorm.DB, data string, format string, policy RedirectChainPolicy, dryRun bool) (RedirectImport, error) {
+	parsed, issues, err := ParseRedirectRules(data, format)
+	if err != nil {
+		return RedirectImport{}, err
+	}
+
+	result := RedirectImport{Imported: []models.RedirectRule{}, Unparsed: issues}
+	lines := strings.Split(data, "\n")
+	for _, p := range parsed {
+		if dryRun {
+			rule := models.RedirectRule{StatusCode: 301, MatchType: models.RedirectMatchExact, Enabled: true}
+			p.Rule.apply(&rule)
+			if err := validateRedirectRule(rule); err != nil {
+				result.Unparsed = append(result.Unparsed, importIssue(lines, format, p.Line, err.Error()))
+				continue
+			}
+			result.Imported = append(result.Imported, rule)
+			continue
+		}
+
+		rule, warnings, err := CreateRedirectRule(db, p.Rule, policy)
+		if err != nil {
+			result.Unparsed = append(result.Unparsed, importIssue(lines, format, p.Line, err.Error()))
+			continue
+		}
+		result.Imported = append(result.Imported, rule)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	sort.SliceStable(result.Unparsed, func(i, j int) bool { return result.Unparsed[i].Line < result.Unparsed[j].Line })
+	return result, nil
+}
+
+func importIssue(lines []string, format string, line int, reason string) RedirectImportIssue {
+	issue := RedirectImportIssue{Line: line, Reason: reason}
+	if format != RedirectFormatVercel && line >= 1 && line <= len(lines) {
+		issue.Text = strings.TrimSpace(lines[line-1])
+	}
+	return issue
+}
+
+// redirectPattern returns the regular expression matching the same paths as
+// an exact or prefix rule, and the source of a regex rule unchanged. The
+// prefix pattern captures the rest of the path as $1.
+func redirectPattern(rule models.RedirectRule) string {
+	switch rule.MatchType {
+	case models.RedirectMatchExact:
+		return "^" + regexp.QuoteMeta(rule.Source) + "$"
+	case models.RedirectMatchPrefix:
+		return "^" + regexp.QuoteMeta(strings.TrimSuffix(rule.Source, "/")) + "(/.*)?$"
+	default:
+		return rule.Source
+	}
+}
+
+// redirectPatternTarget returns the target to use with redirectPattern.
+func redirectPatternTarget(rule models.RedirectRule) string {
+	if rule.MatchType == models.RedirectMatchPrefix {
+		return strings.TrimSuffix(rule.Target, "/") + "$1"
+	}
+	return rule.Target
+}
+
+// ruleFromPattern turns a regular expression and target back into the
+// simplest rule: exact for a quoted literal, prefix for the pattern written
+// by redirectPattern and regex otherwise.
+func ruleFromPattern(pattern, target string, statusCode int) RedirectRuleChanges {
+	rule := RedirectRuleChanges{StatusCode: statusCode}
+	if literal, ok := literalPattern(strings.TrimSuffix(strings.TrimPrefix(pattern, "^"), "$")); ok && strings.HasPrefix(pattern, "^") && strings.HasSuffix(pattern, "$") {
+		rule.Source, rule.Target, rule.MatchType = literal, target, models.RedirectMatchExact
+		return rule
+	}
+	if inner, ok := strings.CutSuffix(pattern, "(/.*)?$"); ok && strings.HasPrefix(inner, "^") && strings.HasSuffix(target, "$1") {
+		if literal, ok := literalPattern(inner[1:]); ok {
+			targetPrefix := strings.TrimSuffix(target, "$1")
+			if targetPrefix == "" {
+				targetPrefix = "/"
+			}
+			rule.Source, rule.Target, rule.MatchType = literal, targetPrefix, models.RedirectMatchPrefix
+			return rule
+		}
+	}
+	rule.Source, rule.Target, rule.MatchType = pattern, target, models.RedirectMatchRegex
+	return rule
+}
+
+// literalPattern reports whether a regular expression only matches one
+// literal string, returning it.
+func literalPattern(pattern string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == '\\' && i+1 < len(pattern) {
+			i++
+			b.WriteByte(pattern[i])
+			continue
+		}
+		if strings.IndexByte(`.+*?()|[]{}^$`, c) >= 0 {
+			return "", false
+		}
+		b.WriteByte(c)
+	}
+	literal := b.String()
+	return literal, regexp.QuoteMeta(literal) == pattern
+}
+
+func sortedStatusCodes(rules []models.RedirectRule) []int {
+	seen := map[int]bool{}
+	var codes []int
+	for _, rule := range rules {
+		if !seen[rule.StatusCode] {
+			seen[rule.StatusCode] = true
+			codes = append(codes, rule.StatusCode)
+		}
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+// exportNginx writes one map per status code from $uri to the target, and
+// the if/return statements to put in the server block. Like the redirects
+// API, the request's query string is kept unless the target has its own.
+func exportNginx(rules []models.RedirectRule) string {
+	var b strings.Builder
+	b.WriteString("# Redirect rules exported from the redirects API.\n")
+	b.WriteString("# Put the map blocks in the http block and the if blocks in the server block.\n")
+	codes := sortedStatusCodes(rules)
+	for _, code := range codes {
+		fmt.Fprintf(&b, "\nmap $uri $redirect_%d {\n    default \"\";\n", code)
+		for _, rule := range rules {
+			if rule.StatusCode != code {
+				continue
+			}
+			if rule.MatchType == models.RedirectMatchExact {
+				fmt.Fprintf(&b, "    %s %s;\n", nginxQuote(rule.Source), nginxQuote(nginxTarget(rule.Target)))
+			} else {
+				fmt.Fprintf(&b, "    %s %s;\n", nginxQuote("~"+redirectPattern(rule)), nginxQuote(nginxTarget(redirectPatternTarget(rule))))
+			}
+		}
+		b.WriteString("}\n")
+	}
+	for _, code := range codes {
+		fmt.Fprintf(&b, "\nif ($redirect_%d) {\n    return %d $redirect_%d;\n}\n", code, code, code)
+	}
+	return b.String()
+}
+
+// nginxKeepArgs appends the request's query string to a target.
+const nginxKeepArgs = "$is_args$args"
+
+// nginxTarget returns the map value redirecting to target, which carries the
+// request's query string over when target has no query of its own.
+func nginxTarget(target string) string {
+	if strings.Contains(target, "?") {
+		return target
+	}
+	return target + nginxKeepArgs
+}
+
+func nginxQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+var (
+	nginxMapStart  = regexp.MustCompile(`^map\s+\$(?:uri|request_uri)\s+\$redirect_(\d{3})\s*\{$`)
+	nginxMapEntry  = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|\S+)\s+("(?:[^"\\]|\\.)*"|\S+)\s*;$`)
+	nginxRewrite   = regexp.MustCompile(`^rewrite\s+(\S+)\s+(\S+)(?:\s+(permanent|redirect))?\s*;$`)
+	nginxLocation  = regexp.MustCompile(`^location\s+(=|~|~\*)?\s*(\S+)\s*\{\s*return\s+(\d{3})\s+(\S+)\s*;\s*\}$`)
+	nginxStructure = regexp.MustCompile(`^(?:\}|default\s+.*;|if\s*\(\$redirect_\d{3}\)\s*\{|return\s+\d{3}\s+\$redirect_\d{3}\S*\s*;|server\s*\{|http\s*\{)$`)
+)
+
+func parseNginx(data string) ([]ParsedRedirectRule, []RedirectImportIssue) {
+	var rules []ParsedRedirectRule
+	var issues []RedirectImportIssue
+	mapStatus := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		issue := RedirectImportIssue{Line: line, Text: text}
+
+		if match := nginxMapStart.FindStringSubmatch(text); match != nil {
+			mapStatus, _ = strconv.Atoi(match[1])
+			continue
+		}
+		if mapStatus != 0 && text == "}" {
+			mapStatus = 0
+			continue
+		}
+		if nginxStructure.MatchString(text) {
+			continue
+		}
+
+		switch {
+		case mapStatus != 0:
+			match := nginxMapEntry.FindStringSubmatch(text)
+			if match == nil {
+				issue.Reason = "not a map entry"
+				issues = append(issues, issue)
+				continue
+			}
+			source, target := nginxUnquote(match[1]), strings.TrimSuffix(nginxUnquote(match[2]), nginxKeepArgs)
+			if pattern, ok := strings.CutPrefix(source, "~*"); ok {
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: ruleFromPattern("(?i)"+pattern, target, mapStatus)})
+			} else if pattern, ok := strings.CutPrefix(source, "~"); ok {
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: ruleFromPattern(pattern, target, mapStatus)})
+			} else {
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: RedirectRuleChanges{Source: source, Target: target, StatusCode: mapStatus, MatchType: models.RedirectMatchExact}})
+			}
+		case nginxRewrite.MatchString(text):
+			match := nginxRewrite.FindStringSubmatch(text)
+			statusCode := 302
+			if match[3] == "permanent" {
+				statusCode = 301
+			} else if match[3] == "" {
+				issue.Reason = "rewrite without permanent or redirect is an internal rewrite"
+				issues = append(issues, issue)
+				continue
+			}
+			rules = append(rules, ParsedRedirectRule{Line: line, Rule: ruleFromPattern(match[1], match[2], statusCode)})
+		case nginxLocation.MatchString(text):
+			match := nginxLocation.FindStringSubmatch(text)
+			statusCode, _ := strconv.Atoi(match[3])
+			switch match[1] {
+			case "=":
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: RedirectRuleChanges{Source: match[2], Target: match[4], StatusCode: statusCode, MatchType: models.RedirectMatchExact}})
+			case "~":
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: ruleFromPattern(match[2], match[4], statusCode)})
+			case "~*":
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: ruleFromPattern("(?i)"+match[2], match[4], statusCode)})
+			default:
+				rules = append(rules, ParsedRedirectRule{Line: line, Rule: RedirectRuleChanges{Source: match[2], Target: match[4], StatusCode: statusCode, MatchType: models.RedirectMatchPrefix}})
+			}
+		default:
+			issue.Reason = "not a redirect"
+			issues = append(issues, issue)
+		}
+	}
+	return rules, issues
+}
+
+func nginxUnquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s)
+	}
+	return s
+}
+
+// exportApache writes mod_rewrite rules. The patterns accept paths with and
+// without the leading slash so they work in both the server configuration
+// and .htaccess files.
+func exportApache(rules []models.RedirectRule) string {
+	var b strings.Builder
+	b.WriteString("# Redirect rules exported from the redirects API.\n")
+	b.WriteString("RewriteEngine On\n")
+	for _, rule := range rules {
+		pattern := redirectPattern(rule)
+		if rest, ok := strings.CutPrefix(pattern, "^/"); ok {
+			pattern = "^/?" + rest
+		}
+		fmt.Fprintf(&b, "RewriteRule %s %s [R=%d,L]\n", apacheQuote(pattern), apacheQuote(redirectPatternTarget(rule)), rule.StatusCode)
+	}
+	return b.String()
+}
+
+func apacheQuote(s string) string {
+	if strings.ContainsAny(s, " \t\"") {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
+
+var (
+	apacheRewriteRule   = regexp.MustCompile(`^RewriteRule\s+("(?:[^"\\]|\\.)*"|\S+)\s+("(?:[^"\\]|\\.)*"|\S+)(?:\s+\[([^\]]*)\])?$`)
+	apacheRedirect      = regexp.MustCompile(`^Redirect(Match|Permanent|Temp)?\s+(?:(permanent|temp|\d{3})\s+)?(\S+)\s+(\S+)$`)
+	apacheStructure     = regexp.MustCompile(`^(?:RewriteEngine\s+\w+|RewriteBase\s+\S+|Options\s+.*|</?(?:IfModule|VirtualHost|Directory)[^>]*>)$`)
+	apacheRedirectFlags = regexp.MustCompile(`^R(?:=(\d{3}))?$`)
+)
+
+func parseApache(data string) ([]ParsedRedirectRule, []RedirectImportIssue) {
+	var rules []ParsedRedirectRule
+	var issues []RedirectImportIssue
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || apacheStructure.MatchString(text) {
+			continue
+		}
+		issue := RedirectImportIssue{Line: line, Text: text}
+
+		if match := apacheRewriteRule.FindStringSubmatch(text); match != nil {
+			statusCode := 0
+			for _, flag := range strings.Split(match[3], ",") {
+				if flagMatch := apacheRedirectFlags.FindStringSubmatch(strings.TrimSpace(flag)); flagMatch != nil {
+					statusCode = 302
+					if flagMatch[1] != "" {
+						statusCode, _ = strconv.Atoi(flagMatch[1])
+					}
+				}
+			}
+			if statusCode == 0 {
+				issue.Reason = "RewriteRule without the R flag is an internal rewrite"
+				issues = append(issues, issue)
+				continue
+			}
+			pattern := nginxUnquote(match[1])
+			if rest, ok := strings.CutPrefix(pattern, "^/?"); ok {
+				pattern = "^/" + rest
+			} else if rest, ok := strings.CutPrefix(pattern, "^"); ok && !strings.HasPrefix(rest, "/") {
+				pattern = "^/" + rest
+			}
+			rules = append(rules, ParsedRedirectRule{Line: line, Rule: ruleFromPattern(pattern, nginxUnquote(match[2]), statusCode)})
+			continue
+		}
+
+		if match := apacheRedirect.FindStringSubmatch(text); match != nil {
+			statusCode := 302
+			switch {
+			case match[1] == "Permanent" || match[2] == "permanent":
+				statusCode = 301
+			case match[2] != "" && match[2] != "temp":
+				statusCode, _ = strconv.Atoi(match[2])
+			}
+			rule := RedirectRuleChanges{Source: match[3], Target: match[4], StatusCode: statusCode, MatchType: models.RedirectMatchPrefix}
+			if match[1] == "Match" {
+				rule.MatchType = models.RedirectMatchRegex
+			}
+			rules = append(rules, ParsedRedirectRule{Line: line, Rule: rule})
+			continue
+		}
+
+		issue.Reason = "not a redirect"
+		if strings.HasPrefix(text, "RewriteCond") {
+			issue.Reason = "RewriteCond is not supported"
+		}
+		issues = append(issues, issue)
+	}
+	return rules, issues
+}
+
+type vercelRedirect struct {
+	Source      string          `json:"source"`
+	Destination string          `json:"destination"`
+	StatusCode  int             `json:"statusCode,omitempty"`
+	Permanent   *bool           `json:"permanent,omitempty"`
+	Has         json.RawMessage `json:"has,omitempty"`
+	Missing     json.RawMessage `json:"missing,omitempty"`
+}
+
+type vercelConfig struct {
+	Redirects []vercelRedirect `json:"redirects"`
+}
+
+var vercelPrefixParam = regexp.MustCompile(`/:(\w+)\*$`)
+
+// exportVercel writes a vercel.json document holding the redirects. Prefix
+// rules use a ":path*" parameter and regex rules are translated to the
+// path-to-regexp syntax of vercel sources; rules that cannot be translated
+// are left out with a warning.
+func exportVercel(rules []models.RedirectRule) (RedirectExport, error) {
+	var export RedirectExport
+	config := vercelConfig{Redirects: []vercelRedirect{}}
+	for _, rule := range rules {
+		redirect := vercelRedirect{Source: vercelEscape(rule.Source), Destination: rule.Target, StatusCode: rule.StatusCode}
+		switch rule.MatchType {
+		case models.RedirectMatchPrefix:
+			redirect.Source = vercelEscape(strings.TrimSuffix(rule.Source, "/")) + "/:path*"
+			redirect.Destination = strings.TrimSuffix(rule.Target, "/") + "/:path*"
+		case models.RedirectMatchRegex:
+			source, groups, err := vercelSource(rule.Source)
+			if err != nil {
+				export.Warnings = append(export.Warnings, fmt.Sprintf("rule %d (%s) was skipped: %v", rule.ID, rule.Source, err))
+				continue
+			}
+			redirect.Source = source
+			redirect.Destination = renumberGroupReferences(rule.Target, groups)
+		}
+		config.Redirects = append(config.Redirects, redirect)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return RedirectExport{}, err
+	}
+	export.Config = string(data) + "\n"
+	return export, nil
+}
+
+var groupReference = regexp.MustCompile(`\$(\d+)`)
+
+// renumberGroupReferences rewrites the $n references of target to the group
+// numbers of a translated pattern.
+func renumberGroupReferences(target string, groups map[int]int) string {
+	return groupReference.ReplaceAllStringFunc(target, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1:])
+		if renumbered, ok := groups[n]; ok {
+			return "$" + strconv.Itoa(renumbered)
+		}
+		return ref
+	})
+}
+
+// parseVercel reads the redirects of a vercel.json document. Line numbers
+// count redirects from 1 since JSON has no meaningful lines.
+func parseVercel(data string) ([]ParsedRedirectRule, []RedirectImportIssue, error) {
+	var config vercelConfig
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRedirectConfig, err)
+	}
+
+	var rules []ParsedRedirectRule
+	var issues []RedirectImportIssue
+	for i, redirect := range config.Redirects {
+		text, _ := json.Marshal(redirect)
+		issue := RedirectImportIssue{Line: i + 1, Text: string(text)}
+
+		if len(redirect.Has) > 0 || len(redirect.Missing) > 0 {
+			issue.Reason = "has and missing conditions are not supported"
+			issues = append(issues, issue)
+			continue
+		}
+
+		statusCode := redirect.StatusCode
+		if statusCode == 0 {
+			statusCode = 308
+			if redirect.Permanent != nil && !*redirect.Permanent {
+				statusCode = 302
+			}
+		}
+
+		rule := RedirectRuleChanges{Source: redirect.Source, Target: redirect.Destination, StatusCode: statusCode, MatchType: models.RedirectMatchExact}
+		sourceParam := vercelPrefixParam.FindStringSubmatch(redirect.Source)
+		switch {
+		case sourceParam != nil && strings.HasSuffix(redirect.Destination, "/:"+sourceParam[1]+"*"):
+			rule.Source = vercelPrefixParam.ReplaceAllString(redirect.Source, "")
+			rule.Target = strings.TrimSuffix(redirect.Destination, "/:"+sourceParam[1]+"*")
+			if rule.Source == "" {
+				rule.Source = "/"
+			}
+			if rule.Target == "" {
+				rule.Target = "/"
+			}
+			rule.MatchType = models.RedirectMatchPrefix
+		case strings.Contains(redirect.Source, ":"):
+			issue.Reason = "named parameters are only supported as a trailing :path*"
+			issues = append(issues, issue)
+			continue
+		case strings.ContainsAny(redirect.Source, "()[]*+?"):
+			rule.Source = "^" + redirect.Source + "$"
+			rule.MatchType = models.RedirectMatchRegex
+		}
+		rules = append(rules, ParsedRedirectRule{Line: i + 1, Rule: rule})
+	}
+	return rules, issues, nil
+}
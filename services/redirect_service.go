@@ -0,0 +1,362 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRedirectRuleNotFound  = errors.New("redirect rule not found")
+	ErrInvalidRedirectSource = errors.New("redirect source must be a path starting with a slash or a valid regular expression")
+	ErrInvalidRedirectTarget = errors.New("redirect target must be a path starting with a slash or an absolute http(s) url")
+	ErrInvalidRedirectStatus = errors.New("redirect status code must be 301, 302 or 308")
+	ErrInvalidRedirectMatch  = errors.New("redirect match type must be exact, prefix or regex")
+	ErrInvalidRedirectURL    = errors.New("url to resolve is invalid")
+	ErrNoRedirect            = errors.New("no redirect rule matches the url")
+	ErrRedirectLoop          = errors.New("redirect rules form a loop")
+	ErrRedirectRuleLoop      = errors.New("redirect rule would create a loop")
+)
+
+// redirectRulesLock is the key of the transaction-level advisory lock that
+// serializes saving redirect rules, so two rules cannot each pass the loop
+// check and then close a loop together.
+const redirectRulesLock = 7042002
+
+// RedirectRuleChanges holds the fields of a redirect rule that may be set.
+// Empty fields are left untouched on update.
+type RedirectRuleChanges struct {
+	Source     string `json:"source" example:"/experiences"`
+	Target     string `json:"target" example:"/food-experiences"`
+	StatusCode int    `json:"status_code" example:"301"`
+	MatchType  string `json:"match_type" example:"prefix"`
+	Priority   *int   `json:"priority" example:"0"`
+	Enabled    *bool  `json:"enabled" example:"true"`
+}
+
+// RedirectResolution is where a URL ends up after following every matching
+// redirect rule.
+type RedirectResolution struct {
+	URL    string `json:"url" example:"https://www.byfood.com/experiences/tokyo"`
+	Target string `json:"target" example:"https://www.byfood.com/food-experiences/tokyo"`
+	// StatusCode is 302 when any hop is temporary and the first hop's status
+	// code otherwise.
+	StatusCode int           `json:"status_code" example:"301"`
+	Hops       []RedirectHop `json:"hops"`
+}
+
+// RedirectHop is one rule applied while resolving a URL.
+type RedirectHop struct {
+	RuleID     uint   `json:"rule_id" example:"1"`
+	From       string `json:"from" example:"https://www.byfood.com/experiences/tokyo"`
+	To         string `json:"to" example:"https://www.byfood.com/food-experiences/tokyo"`
+	StatusCode int    `json:"status_code" example:"301"`
+}
+
+// CreateRedirectRule validates and stores a new rule. New rules are enabled
+// with a 301 exact match unless told otherwise. Rules closing a loop are
+// rejected; too long chains are handled as the policy says and the returned
+// warnings describe them.
+func CreateRedirectRule(db *gorm.DB, changes RedirectRuleChanges, policy RedirectChainPolicy) (models.RedirectRule, []string, error) {
+	rule := models.RedirectRule{StatusCode: 301, MatchType: models.RedirectMatchExact, Enabled: true}
+	changes.apply(&rule)
+	if err := validateRedirectRule(rule); err != nil {
+		return models.RedirectRule{}, nil, err
+	}
+	return saveRedirectRule(db, rule, policy)
+}
+
+// UpdateRedirectRule applies changes to a stored rule, checking chains and
+// loops like CreateRedirectRule.
+func UpdateRedirectRule(db *gorm.DB, ruleID uint, changes RedirectRuleChanges, policy RedirectChainPolicy) (models.RedirectRule, []string, error) {
+	var rule models.RedirectRule
+	if err := db.First(&rule, ruleID).Error; err != nil {
+		return models.RedirectRule{}, nil, notFound(err, ErrRedirectRuleNotFound)
+	}
+
+	changes.apply(&rule)
+	if err := validateRedirectRule(rule); err != nil {
+		return models.RedirectRule{}, nil, err
+	}
+	return saveRedirectRule(db, rule, policy)
+}
+
+// saveRedirectRule saves rule and checks the resulting redirect graph in one
+// transaction, so a rule closing a loop is never stored.
+func saveRedirectRule(db *gorm.DB, rule models.RedirectRule, policy RedirectChainPolicy) (models.RedirectRule, []string, error) {
+	var warnings []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", redirectRulesLock).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&rule).Error; err != nil {
+			return err
+		}
+		if !rule.Enabled {
+			return nil
+		}
+
+		var err error
+		if warnings, err = checkRedirectChains(tx, rule, policy); err != nil {
+			return err
+		}
+		return tx.First(&rule, rule.ID).Error
+	})
+	if err != nil {
+		return models.RedirectRule{}, nil, err
+	}
+	redirectMatchers.invalidate()
+	return rule, warnings, nil
+}
+
+// DeleteRedirectRule removes a rule.
+func DeleteRedirectRule(db *gorm.DB, ruleID uint) error {
+	var rule models.RedirectRule
+	if err := db.First(&rule, ruleID).Error; err != nil {
+		return notFound(err, ErrRedirectRuleNotFound)
+	}
+	if err := db.Delete(&rule).Error; err != nil {
+		return err
+	}
+	redirectMatchers.invalidate()
+	return nil
+}
+
+// EnabledRedirectRules returns the enabled rules in the order they are tried.
+func EnabledRedirectRules(db *gorm.DB) ([]models.RedirectRule, error) {
+	var rules []models.RedirectRule
+	err := db.Where("enabled = ?", true).Order("priority DESC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// ResolveRedirect follows the enabled rules from rawURL until no rule
+// matches, the target leaves the site or maxHops rules have been applied.
+// The compiled rules are cached until a rule is saved or deleted.
+func ResolveRedirect(db *gorm.DB, rawURL string, maxHops int) (RedirectResolution, error) {
+	matchers, err := redirectMatchers.get(db)
+	if err != nil {
+		return RedirectResolution{}, err
+	}
+	return resolveRedirect(matchers, rawURL, maxHops)
+}
+
+// ResolveRedirectWith resolves rawURL against rules, which must be in
+// priority order. It fails with ErrNoRedirect when no rule matches and with
+// ErrRedirectLoop when the rules lead back to a URL already seen.
+func ResolveRedirectWith(rules []models.RedirectRule, rawURL string, maxHops int) (RedirectResolution, error) {
+	return resolveRedirect(compileRedirectRules(rules), rawURL, maxHops)
+}
+
+func resolveRedirect(matchers []redirectMatcher, rawURL string, maxHops int) (RedirectResolution, error) {
+	start, err := url.Parse(rawURL)
+	if err != nil {
+		return RedirectResolution{}, ErrInvalidRedirectURL
+	}
+
+	hops, target, err := followRedirects(matchers, start, maxHops)
+	if err != nil {
+		return RedirectResolution{}, err
+	}
+	if len(hops) == 0 {
+		return RedirectResolution{}, ErrNoRedirect
+	}
+
+	return newRedirectResolution(rawURL, hops, target), nil
+}
+
+// newRedirectResolution sums up hops taken from rawURL. The status code is
+// that of the first hop, or 302 when any hop is temporary.
+func newRedirectResolution(rawURL string, hops []RedirectHop, target *url.URL) RedirectResolution {
+	resolution := RedirectResolution{URL: rawURL, Target: target.String(), StatusCode: hops[0].StatusCode, Hops: hops}
+	for _, hop := range hops {
+		if hop.StatusCode == 302 {
+			resolution.StatusCode = 302
+		}
+	}
+	return resolution
+}
+
+// followRedirects applies matching rules from start until none matches, the
+// target leaves the site or maxHops rules have been applied. On
+// ErrRedirectLoop the hops up to and including the one closing the loop are
+// returned.
+func followRedirects(matchers []redirectMatcher, start *url.URL, maxHops int) ([]RedirectHop, *url.URL, error) {
+	current := start
+	hops := []RedirectHop{}
+	seen := map[string]bool{current.EscapedPath(): true}
+	for len(hops) < maxHops {
+		rule, target, ok := matchRedirect(matchers, current.EscapedPath())
+		if !ok {
+			break
+		}
+
+		next, err := redirectTarget(current, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		hops = append(hops, RedirectHop{
+			RuleID:     rule.ID,
+			From:       current.String(),
+			To:         next.String(),
+			StatusCode: rule.StatusCode,
+		})
+		if leavesSite(current, next) {
+			return hops, next, nil
+		}
+		if seen[next.EscapedPath()] {
+			return hops, next, ErrRedirectLoop
+		}
+		seen[next.EscapedPath()] = true
+		current = next
+	}
+	return hops, current, nil
+}
+
+// leavesSite reports whether a redirect from current to next goes to another
+// site. URLs without a host, such as rule sources and request URIs, and URLs
+// on one of the configured site hosts are all on this site.
+func leavesSite(current, next *url.URL) bool {
+	if strings.EqualFold(next.Host, current.Host) {
+		return false
+	}
+	return !onSite(current) || !onSite(next)
+}
+
+// onSite reports whether u is a path or a URL on one of the site hosts.
+func onSite(u *url.URL) bool {
+	if u.Host == "" {
+		return true
+	}
+	for _, host := range config.RedirectSiteHosts() {
+		if strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c RedirectRuleChanges) apply(rule *models.RedirectRule) {
+	if c.Source != "" {
+		rule.Source = c.Source
+	}
+	if c.Target != "" {
+		rule.Target = c.Target
+	}
+	if c.StatusCode != 0 {
+		rule.StatusCode = c.StatusCode
+	}
+	if c.MatchType != "" {
+		rule.MatchType = c.MatchType
+	}
+	if c.Priority != nil {
+		rule.Priority = *c.Priority
+	}
+	if c.Enabled != nil {
+		rule.Enabled = *c.Enabled
+	}
+}
+
+func validateRedirectRule(rule models.RedirectRule) error {
+	if !models.ValidRedirectMatch(rule.MatchType) {
+		return ErrInvalidRedirectMatch
+	}
+	if !models.ValidRedirectStatus(rule.StatusCode) {
+		return ErrInvalidRedirectStatus
+	}
+	if rule.MatchType == models.RedirectMatchRegex {
+		if _, err := regexp.Compile(rule.Source); err != nil || rule.Source == "" {
+			return ErrInvalidRedirectSource
+		}
+	} else if !strings.HasPrefix(rule.Source, "/") {
+		return ErrInvalidRedirectSource
+	}
+	if !validRedirectTarget(rule.Target) {
+		return ErrInvalidRedirectTarget
+	}
+	return nil
+}
+
+func validRedirectTarget(target string) bool {
+	if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") {
+		return true
+	}
+	u, err := url.Parse(target)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+type redirectMatcher struct {
+	rule    models.RedirectRule
+	pattern *regexp.Regexp
+}
+
+// compileRedirectRules prepares the rules for matching. Regex rules that do
+// not compile are skipped; they cannot be stored through the service.
+func compileRedirectRules(rules []models.RedirectRule) []redirectMatcher {
+	matchers := make([]redirectMatcher, 0, len(rules))
+	for _, rule := range rules {
+		matcher := redirectMatcher{rule: rule}
+		if rule.MatchType == models.RedirectMatchRegex {
+			pattern, err := regexp.Compile(rule.Source)
+			if err != nil {
+				continue
+			}
+			matcher.pattern = pattern
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers
+}
+
+// matchRedirect returns the first rule matching path and the target it sends
+// path to.
+func matchRedirect(matchers []redirectMatcher, path string) (models.RedirectRule, string, bool) {
+	for _, matcher := range matchers {
+		if target, ok := matcher.match(path); ok {
+			return matcher.rule, target, true
+		}
+	}
+	return models.RedirectRule{}, "", false
+}
+
+func (m redirectMatcher) match(path string) (string, bool) {
+	rule := m.rule
+	switch rule.MatchType {
+	case models.RedirectMatchExact:
+		if path == rule.Source {
+			return rule.Target, true
+		}
+	case models.RedirectMatchPrefix:
+		source := strings.TrimSuffix(rule.Source, "/")
+		if path == source || strings.HasPrefix(path, source+"/") {
+			target := strings.TrimSuffix(rule.Target, "/") + strings.TrimPrefix(path, source)
+			if strings.HasPrefix(rule.Target, "/") && !strings.HasPrefix(target, "/") {
+				target = "/" + target
+			}
+			return target, true
+		}
+	case models.RedirectMatchRegex:
+		if match := m.pattern.FindStringSubmatchIndex(path); match != nil {
+			return string(m.pattern.ExpandString(nil, rule.Target, path, match)), true
+		}
+	}
+	return "", false
+}
+
+// redirectTarget resolves target against the current URL. The query of the
+// current URL is carried over when the target has none.
+func redirectTarget(current *url.URL, target string) (*url.URL, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	next := current.ResolveReference(targetURL)
+	if targetURL.RawQuery == "" {
+		next.RawQuery = current.RawQuery
+	}
+	return next, nil
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+var (
+	errVercelUnanchored   = errors.New("vercel sources must match from the start of the path")
+	errVercelNoSlash      = errors.New("vercel sources must start with a literal /")
+	errVercelNestedGroups = errors.New("capturing groups inside other groups are not supported by vercel")
+	errVercelCaseFolding  = errors.New("case-insensitive matching is not supported by vercel")
+)
+
+// vercelEscape escapes the characters path-to-regexp gives a meaning to.
+func vercelEscape(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`\:*+?(){}`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// vercelSource translates the RE2 pattern of a regex rule into a vercel
+// source. path-to-regexp only takes regular expressions inside unnested
+// groups, so literal parts are escaped, groups are kept and every other part
+// becomes a group of its own. groups maps the group numbers of pattern to
+// those of the source.
+func vercelSource(pattern string) (string, map[int]int, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+	}
+	if len(parts) == 0 || parts[0].Op != syntax.OpBeginText {
+		return "", nil, errVercelUnanchored
+	}
+	parts = parts[1:]
+	anchoredEnd := len(parts) > 0 && parts[len(parts)-1].Op == syntax.OpEndText
+	if anchoredEnd {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 || parts[0].Op != syntax.OpLiteral || parts[0].Rune[0] != '/' {
+		return "", nil, errVercelNoSlash
+	}
+
+	var b strings.Builder
+	groups := map[int]int{}
+	group := 0
+	for _, part := range parts {
+		switch {
+		case part.Op == syntax.OpLiteral:
+			if part.Flags&syntax.FoldCase != 0 {
+				return "", nil, errVercelCaseFolding
+			}
+			b.WriteString(vercelEscape(string(part.Rune)))
+		case part.Op == syntax.OpCapture:
+			inner, err := jsPattern(part.Sub[0])
+			if err != nil {
+				return "", nil, err
+			}
+			group++
+			groups[part.Cap] = group
+			b.WriteString("(" + inner + ")")
+		case isOptionalCapture(part):
+			// A group with a modifier right after a / or . makes that
+			// character optional too in path-to-regexp.
+			if written := b.String(); strings.HasSuffix(written, "/") || strings.HasSuffix(written, ".") {
+				return "", nil, fmt.Errorf("an optional group after %q is not supported by vercel", written[len(written)-1:])
+			}
+			inner, err := jsPattern(part.Sub[0].Sub[0])
+			if err != nil {
+				return "", nil, err
+			}
+			group++
+			groups[part.Sub[0].Cap] = group
+			b.WriteString("(" + inner + ")" + map[syntax.Op]string{syntax.OpQuest: "?", syntax.OpStar: "*", syntax.OpPlus: "+"}[part.Op])
+		default:
+			inner, err := jsPattern(part)
+			if err != nil {
+				return "", nil, err
+			}
+			group++
+			b.WriteString("(" + inner + ")")
+		}
+	}
+	if !anchoredEnd {
+		b.WriteString("(.*)")
+	}
+	return b.String(), groups, nil
+}
+
+// isOptionalCapture reports whether re is a group with a greedy ?, * or +.
+func isOptionalCapture(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpQuest, syntax.OpStar, syntax.OpPlus:
+		return re.Flags&syntax.NonGreedy == 0 && re.Sub[0].Op == syntax.OpCapture
+	}
+	return false
+}
+
+// jsPattern writes re in the JavaScript regular expression syntax used
+// inside path-to-regexp groups, which allows no capturing groups.
+func jsPattern(re *syntax.Regexp) (string, error) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return "", nil
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return "", errVercelCaseFolding
+		}
+		var b strings.Builder
+		for _, r := range re.Rune {
+			b.WriteString(jsEscape(r, `\.+*?()|[]{}^$/`))
+		}
+		return b.String(), nil
+	case syntax.OpCharClass:
+		return jsCharClass(re.Rune), nil
+	case syntax.OpAnyCharNotNL:
+		return ".", nil
+	case syntax.OpAnyChar:
+		return `[\s\S]`, nil
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub, err := jsPattern(re.Sub[0])
+		if err != nil {
+			return "", err
+		}
+		if needsGroup(re.Sub[0]) {
+			sub = "(?:" + sub + ")"
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			sub += "*"
+		case syntax.OpPlus:
+			sub += "+"
+		case syntax.OpQuest:
+			sub += "?"
+		default:
+			switch {
+			case re.Max == -1:
+				sub += fmt.Sprintf("{%d,}", re.Min)
+			case re.Min == re.Max:
+				sub += fmt.Sprintf("{%d}", re.Min)
+			default:
+				sub += fmt.Sprintf("{%d,%d}", re.Min, re.Max)
+			}
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			sub += "?"
+		}
+		return sub, nil
+	case syntax.OpConcat, syntax.OpAlternate:
+		subs := make([]string, len(re.Sub))
+		for i, sub := range re.Sub {
+			written, err := jsPattern(sub)
+			if err != nil {
+				return "", err
+			}
+			if re.Op == syntax.OpConcat && sub.Op == syntax.OpAlternate {
+				written = "(?:" + written + ")"
+			}
+			subs[i] = written
+		}
+		if re.Op == syntax.OpAlternate {
+			return strings.Join(subs, "|"), nil
+		}
+		return strings.Join(subs, ""), nil
+	case syntax.OpCapture:
+		return "", errVercelNestedGroups
+	default:
+		return "", fmt.Errorf("%s is not supported by vercel", re)
+	}
+}
+
+// needsGroup reports whether re must be wrapped before a repetition applies
+// to all of it.
+func needsGroup(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpConcat, syntax.OpAlternate, syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return true
+	case syntax.OpLiteral:
+		return len(re.Rune) > 1
+	}
+	return false
+}
+
+// jsCharClass writes the rune ranges of a character class. Classes reaching
+// the last code point are written negated.
+func jsCharClass(ranges []rune) string {
+	switch string(ranges) {
+	case "09":
+		return `\d`
+	case "09AZ__az":
+		return `\w`
+	}
+
+	negated := len(ranges) > 0 && ranges[len(ranges)-1] == unicode.MaxRune
+	if negated {
+		var complement []rune
+		next := rune(0)
+		for i := 0; i < len(ranges); i += 2 {
+			if ranges[i] > next {
+				complement = append(complement, next, ranges[i]-1)
+			}
+			next = ranges[i+1] + 1
+		}
+		ranges = complement
+	}
+
+	var b strings.Builder
+	b.WriteString("[")
+	if negated {
+		b.WriteString("^")
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		b.WriteString(jsEscape(ranges[i], `\]-^[()`))
+		if ranges[i+1] != ranges[i] {
+			b.WriteString("-" + jsEscape(ranges[i+1], `\]-^[()`))
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// jsEscape writes r, escaping it when it is one of special or not printable.
+func jsEscape(r rune, special string) string {
+	switch {
+	case strings.ContainsRune(special, r):
+		return `\` + string(r)
+	case r > 0xFFFF:
+		return string(r)
+	case !unicode.IsPrint(r):
+		return `\u` + fmt.Sprintf("%04X", r)
+	}
+	return string(r)
+}
@@ -1,6 +1,9 @@
 package services
 
-import "byfood-test-backend/models"
+import (
+	"byfood-test-backend/models"
+	"time"
+)
 
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -27,5 +30,138 @@ type SuccessMessage struct {
 }
 
 type SuccessProcessURL struct {
-	ProcessedUrl string `json:"processed_url"`
+	ProcessedUrl string              `json:"processed_url"`
+	Alternates   []HreflangAlternate `json:"alternates,omitempty"`
+}
+
+type UserResponse struct {
+	Message string      `json:"message"`
+	Data    models.User `json:"data"`
+}
+
+type UserListResponse struct {
+	Data       []models.User `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+type LoanResponse struct {
+	Message string      `json:"message"`
+	Data    models.Loan `json:"data"`
+}
+
+type LoanListResponse struct {
+	Data       []models.Loan `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+type CopyResponse struct {
+	Message string      `json:"message"`
+	Data    models.Copy `json:"data"`
+}
+
+type CopyListResponse struct {
+	Data []models.Copy `json:"data"`
+}
+
+type HoldResponse struct {
+	Message string      `json:"message"`
+	Data    models.Hold `json:"data"`
+}
+
+type HoldListResponse struct {
+	Data       []models.Hold `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+type FineSummaryResponse struct {
+	Data FineSummary `json:"data"`
+}
+
+type FineEntryResponse struct {
+	Message string           `json:"message"`
+	Data    models.FineEntry `json:"data"`
+}
+
+type BranchResponse struct {
+	Message string        `json:"message"`
+	Data    models.Branch `json:"data"`
+}
+
+type BranchListResponse struct {
+	Data []models.Branch `json:"data"`
+}
+
+type TransferResponse struct {
+	Message string          `json:"message"`
+	Data    models.Transfer `json:"data"`
+}
+
+type TransferListResponse struct {
+	Data       []models.Transfer `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+type URLRuleSetsResponse struct {
+	Data     []RuleSet `json:"data"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+type URLOperationsResponse struct {
+	Data []URLOperationInfo `json:"data"`
+}
+
+type URLBatchResponse struct {
+	Data []URLBatchResult `json:"data"`
+}
+
+type URLCompareResponse struct {
+	Data URLComparison `json:"data"`
+}
+
+type SitemapAuditResponse struct {
+	Data SitemapAudit `json:"data"`
+}
+
+type CanonicalCheckResponse struct {
+	Data []CanonicalCheck `json:"data"`
+}
+
+type LinkCheckJobResponse struct {
+	Message string              `json:"message"`
+	Data    models.LinkCheckJob `json:"data"`
+}
+
+type LinkCheckResultsResponse struct {
+	Data       models.LinkCheckJob      `json:"data"`
+	Results    []models.LinkCheckResult `json:"results"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+type ShortLinkResponse struct {
+	Message string           `json:"message"`
+	Data    models.ShortLink `json:"data"`
+}
+
+type ShortLinkListResponse struct {
+	Data       []models.ShortLink `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+type RedirectRuleResponse struct {
+	Message  string              `json:"message"`
+	Data     models.RedirectRule `json:"data"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+type RedirectRuleListResponse struct {
+	Data       []models.RedirectRule `json:"data"`
+	Pagination Pagination            `json:"pagination"`
+}
+
+type RedirectAuditResponse struct {
+	Data RedirectAudit `json:"data"`
+}
+
+type RedirectImportResponse struct {
+	Data RedirectImport `json:"data"`
 }
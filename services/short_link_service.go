@@ -0,0 +1,249 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrShortLinkNotFound   = errors.New("short link not found")
+	ErrShortLinkDisabled   = errors.New("short link is disabled")
+	ErrShortLinkExpired    = errors.New("short link has expired")
+	ErrInvalidShortCode    = errors.New("short code must be 3 to 64 letters, digits, dashes or underscores")
+	ErrShortCodeTaken      = errors.New("short code is already used for another url")
+	ErrShortLinkExpiryPast = errors.New("short link expiry must be in the future")
+	ErrShortLinkHost       = errors.New("short links may only point at the hosts of this site")
+)
+
+// shortCodeAlphabet is what generated codes are made of; custom codes may
+// also use dashes and underscores.
+const shortCodeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// shortCodeAttempts is how many generated codes are tried before giving up
+// on finding a free one.
+const shortCodeAttempts = 5
+
+var shortCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,64}$`)
+
+// ShortLinkRequest asks for a short link to URL, with Code as its code when
+// set and expiring at ExpiresAt when set.
+type ShortLinkRequest struct {
+	URL       string     `json:"url" example:"https://byfood.com/books/the-great-gatsby-f-scott-fitzgerald/?utm_source=x"`
+	Code      string     `json:"code" example:"gatsby"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2030-01-01T00:00:00Z"`
+}
+
+// ShortLinkChanges holds the fields of a short link that may be changed.
+// NoExpiry removes the expiry date.
+type ShortLinkChanges struct {
+	Enabled   *bool      `json:"enabled" example:"false"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2030-01-01T00:00:00Z"`
+	NoExpiry  bool       `json:"no_expiry" example:"false"`
+}
+
+// ValidShortCode reports whether code may be used as a custom short code.
+func ValidShortCode(code string) bool {
+	return shortCodePattern.MatchString(code)
+}
+
+// GenerateShortCode returns a random code of length characters.
+func GenerateShortCode(length int) (string, error) {
+	max := big.NewInt(int64(len(shortCodeAlphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shortCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// CreateShortLink returns a short link to the URL of request, canonicalized
+// with operations, which must be on one of allowedHosts. Without a custom code an active link to the same
+// canonical URL is reused; a custom code already pointing at that URL is
+// reused as well. created reports whether a new link was stored. Requests for
+// the same URL are serialized, and a code taken by a concurrent request is
+// detected when inserting rather than by the earlier lookup.
+func CreateShortLink(db *gorm.DB, request ShortLinkRequest, operations, allowedHosts []string, codeLength int, now time.Time) (link models.ShortLink, created bool, err error) {
+	target, err := ProcessURL(request.URL, operations...)
+	if err != nil {
+		return link, false, err
+	}
+	if !shortLinkHostAllowed(target, allowedHosts) {
+		return link, false, ErrShortLinkHost
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return link, false, ErrShortLinkExpiryPast
+	}
+	if request.Code != "" && !ValidShortCode(request.Code) {
+		return link, false, ErrInvalidShortCode
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", target).Error; err != nil {
+			return err
+		}
+
+		if request.Code != "" {
+			existing, err := shortLinkWithCode(tx, request.Code, target)
+			if err == nil {
+				link = existing
+			}
+			if !errors.Is(err, ErrShortLinkNotFound) {
+				return err
+			}
+		} else {
+			err := activeShortLinks(tx, now).Where("target_url = ?", target).Order("id").First(&link).Error
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		for attempt := 0; attempt < shortCodeAttempts; attempt++ {
+			code := request.Code
+			if code == "" {
+				if code, err = GenerateShortCode(codeLength); err != nil {
+					return err
+				}
+			}
+
+			link = models.ShortLink{Code: code, TargetURL: target, Enabled: true, ExpiresAt: request.ExpiresAt}
+			result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(&link)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				created = true
+				return nil
+			}
+
+			if request.Code != "" {
+				// Another request took the custom code in the meantime.
+				existing, err := shortLinkWithCode(tx, request.Code, target)
+				link = existing
+				return err
+			}
+		}
+		return ErrShortCodeTaken
+	})
+	if err != nil {
+		return models.ShortLink{}, false, err
+	}
+	return link, created, nil
+}
+
+// shortLinkHostAllowed reports whether target is on one of allowedHosts.
+func shortLinkHostAllowed(target string, allowedHosts []string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	for _, host := range allowedHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortLinkWithCode returns the link with code when it points at target,
+// ErrShortCodeTaken when it points elsewhere and ErrShortLinkNotFound when
+// there is none.
+func shortLinkWithCode(db *gorm.DB, code, target string) (models.ShortLink, error) {
+	var link models.ShortLink
+	if err := db.Where("code = ?", code).First(&link).Error; err != nil {
+		return link, notFound(err, ErrShortLinkNotFound)
+	}
+	if link.TargetURL != target {
+		return models.ShortLink{}, ErrShortCodeTaken
+	}
+	return link, nil
+}
+
+// GetShortLink returns a short link by ID.
+func GetShortLink(db *gorm.DB, linkID uint) (models.ShortLink, error) {
+	var link models.ShortLink
+	if err := db.First(&link, linkID).Error; err != nil {
+		return link, notFound(err, ErrShortLinkNotFound)
+	}
+	return link, nil
+}
+
+// UpdateShortLink enables or disables a short link or changes its expiry.
+func UpdateShortLink(db *gorm.DB, linkID uint, changes ShortLinkChanges, now time.Time) (models.ShortLink, error) {
+	link, err := GetShortLink(db, linkID)
+	if err != nil {
+		return link, err
+	}
+
+	if changes.Enabled != nil {
+		link.Enabled = *changes.Enabled
+	}
+	if changes.NoExpiry {
+		link.ExpiresAt = nil
+	} else if changes.ExpiresAt != nil {
+		if !changes.ExpiresAt.After(now) {
+			return link, ErrShortLinkExpiryPast
+		}
+		link.ExpiresAt = changes.ExpiresAt
+	}
+
+	if err := db.Save(&link).Error; err != nil {
+		return link, err
+	}
+	return link, nil
+}
+
+// DeleteShortLink removes a short link.
+func DeleteShortLink(db *gorm.DB, linkID uint) error {
+	result := db.Delete(&models.ShortLink{}, linkID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShortLinkNotFound
+	}
+	return nil
+}
+
+// FollowShortLink returns the link with code and counts the click. Disabled
+// and expired links are not followed.
+func FollowShortLink(db *gorm.DB, code string, now time.Time) (models.ShortLink, error) {
+	var link models.ShortLink
+	if err := db.Where("code = ?", code).First(&link).Error; err != nil {
+		return link, notFound(err, ErrShortLinkNotFound)
+	}
+	if !link.Enabled {
+		return link, ErrShortLinkDisabled
+	}
+	if !link.Active(now) {
+		return link, ErrShortLinkExpired
+	}
+
+	err := db.Model(&link).UpdateColumns(map[string]interface{}{
+		"clicks":          gorm.Expr("clicks + 1"),
+		"last_clicked_at": now,
+	}).Error
+	if err != nil {
+		return link, err
+	}
+	link.Clicks++
+	link.LastClickedAt = &now
+	return link, nil
+}
+
+// activeShortLinks scopes a query to enabled links that have not expired.
+func activeShortLinks(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Model(&models.ShortLink{}).Where("enabled AND (expires_at IS NULL OR expires_at > ?)", now)
+}
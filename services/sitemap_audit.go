@@ -0,0 +1,181 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+const (
+	SitemapIssueInvalid      = "invalid"
+	SitemapIssueNotCanonical = "not_canonical"
+	SitemapIssueDuplicate    = "duplicate"
+	SitemapIssueRedirect     = "redirect"
+)
+
+var (
+	ErrInvalidSitemap      = errors.New("file is not a sitemap or sitemap index")
+	ErrSitemapTooLarge     = errors.New("sitemap lists too many urls")
+	ErrSitemapFileTooLarge = errors.New("sitemap file is too large")
+)
+
+// SitemapAuditEntry reports the problems of one <loc>. Index is its position
+// in the sitemap.
+type SitemapAuditEntry struct {
+	Index        int    `json:"index" example:"3"`
+	Loc          string `json:"loc" example:"https://byfood.com/Books/3/"`
+	CanonicalURL string `json:"canonical_url,omitempty" example:"https://www.byfood.com/books/3"`
+	// Issues holds invalid, not_canonical, duplicate and redirect.
+	Issues []string `json:"issues" example:"not_canonical,redirect"`
+	// DuplicateOf is the index of the first <loc> with the same canonical URL.
+	DuplicateOf *int                `json:"duplicate_of,omitempty" example:"1"`
+	Redirect    *RedirectResolution `json:"redirect,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// SitemapAudit sums up the audit of a sitemap. For a sitemap index only the
+// URLs of the sitemap files it lists are checked, not the pages in those
+// files. Entries only holds the <loc>s with an issue.
+type SitemapAudit struct {
+	Type         string              `json:"type" example:"urlset"`
+	Total        int                 `json:"total" example:"120"`
+	NotCanonical int                 `json:"not_canonical" example:"4"`
+	Duplicates   int                 `json:"duplicates" example:"1"`
+	Redirected   int                 `json:"redirected" example:"2"`
+	Invalid      int                 `json:"invalid" example:"0"`
+	Entries      []SitemapAuditEntry `json:"entries"`
+}
+
+// ParseSitemap returns whether data, optionally gzipped, is a urlset or a
+// sitemapindex and the <loc> values it lists. Documents larger than maxBytes
+// once decompressed are rejected with ErrSitemapFileTooLarge; zero means no
+// limit.
+func ParseSitemap(data []byte, maxBytes int64) (string, []string, error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, ErrInvalidSitemap
+		}
+		var reader io.Reader = gzipReader
+		if maxBytes > 0 {
+			reader = io.LimitReader(gzipReader, maxBytes+1)
+		}
+		if data, err = io.ReadAll(reader); err != nil {
+			return "", nil, ErrInvalidSitemap
+		}
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return "", nil, ErrSitemapFileTooLarge
+	}
+
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return "", nil, ErrInvalidSitemap
+	}
+
+	var locs []string
+	switch root.XMLName.Local {
+	case "urlset":
+		var urlSet SitemapURLSet
+		if err := xml.Unmarshal(data, &urlSet); err != nil {
+			return "", nil, ErrInvalidSitemap
+		}
+		for _, entry := range urlSet.URLs {
+			locs = append(locs, strings.TrimSpace(entry.Loc))
+		}
+	case "sitemapindex":
+		var index SitemapIndex
+		if err := xml.Unmarshal(data, &index); err != nil {
+			return "", nil, ErrInvalidSitemap
+		}
+		for _, entry := range index.Sitemaps {
+			locs = append(locs, strings.TrimSpace(entry.Loc))
+		}
+	default:
+		return "", nil, ErrInvalidSitemap
+	}
+	return root.XMLName.Local, locs, nil
+}
+
+// AuditSitemap runs every <loc> of a sitemap through operations and the
+// redirect rules, which must be in priority order, and reports the ones that
+// are not canonical, that share a canonical URL with an earlier <loc> or that
+// a rule redirects. A sitemap listing more than maxURLs URLs, or larger than
+// maxBytes, is rejected.
+func AuditSitemap(data []byte, operations []string, rules []models.RedirectRule, maxHops, maxURLs int, maxBytes int64) (SitemapAudit, error) {
+	resolved, err := URLOperations.Resolve(operations)
+	if err != nil {
+		return SitemapAudit{}, err
+	}
+	kind, locs, err := ParseSitemap(data, maxBytes)
+	if err != nil {
+		return SitemapAudit{}, err
+	}
+	if maxURLs > 0 && len(locs) > maxURLs {
+		return SitemapAudit{}, ErrSitemapTooLarge
+	}
+
+	matchers := compileRedirectRules(rules)
+	audit := SitemapAudit{Type: kind, Total: len(locs), Entries: []SitemapAuditEntry{}}
+	firstIndex := make(map[string]int)
+	for i, loc := range locs {
+		entry := SitemapAuditEntry{Index: i, Loc: loc, Issues: []string{}}
+
+		canonical, err := applyURLOperations(loc, resolved)
+		if err != nil {
+			entry.Issues = append(entry.Issues, SitemapIssueInvalid)
+			entry.Error = err.Error()
+			audit.Invalid++
+			audit.Entries = append(audit.Entries, entry)
+			continue
+		}
+		entry.CanonicalURL = canonical
+
+		if canonical != loc {
+			entry.Issues = append(entry.Issues, SitemapIssueNotCanonical)
+			audit.NotCanonical++
+		}
+		if first, ok := firstIndex[canonical]; ok {
+			entry.Issues = append(entry.Issues, SitemapIssueDuplicate)
+			entry.DuplicateOf = &first
+			audit.Duplicates++
+		} else {
+			firstIndex[canonical] = i
+		}
+		if resolution, err := resolveSitemapRedirect(matchers, loc, maxHops); resolution != nil {
+			entry.Issues = append(entry.Issues, SitemapIssueRedirect)
+			entry.Redirect = resolution
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			audit.Redirected++
+		}
+
+		if len(entry.Issues) > 0 {
+			audit.Entries = append(audit.Entries, entry)
+		}
+	}
+	return audit, nil
+}
+
+// resolveSitemapRedirect returns where the rules send loc, or nil when no
+// rule matches. A loop is returned along with ErrRedirectLoop.
+func resolveSitemapRedirect(matchers []redirectMatcher, loc string, maxHops int) (*RedirectResolution, error) {
+	start, err := url.Parse(loc)
+	if err != nil {
+		return nil, nil
+	}
+	hops, target, err := followRedirects(matchers, start, maxHops)
+	if len(hops) == 0 {
+		return nil, nil
+	}
+	resolution := newRedirectResolution(loc, hops, target)
+	return &resolution, err
+}
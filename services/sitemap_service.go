@@ -0,0 +1,202 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const SitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+var (
+	ErrSitemapNotFound        = errors.New("sitemap not found")
+	ErrInvalidSitemapTemplate = errors.New("sitemap url template must contain {id} or {slug}")
+	ErrBookWithoutSlug        = errors.New("book has no slug")
+	ErrSitemapURLNotCanonical = errors.New("sitemap url cannot be canonicalized")
+)
+
+// SitemapURLSet is a sitemap file listing page URLs.
+type SitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset" swaggerignore:"true"`
+	Xmlns   string       `xml:"xmlns,attr,omitempty"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// SitemapIndex lists sitemap files when one file would hold too many URLs.
+type SitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex" swaggerignore:"true"`
+	Xmlns    string         `xml:"xmlns,attr,omitempty"`
+	Sitemaps []SitemapEntry `xml:"sitemap"`
+}
+
+type SitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// BookSitemap builds book sitemaps from the catalog. Book URLs come from
+// Template and are canonicalized with Operations; MaxURLs is the size of one
+// sitemap file.
+type BookSitemap struct {
+	Template   string
+	Operations []string
+	MaxURLs    int
+}
+
+// DefaultBookSitemap returns the book sitemap configured in the environment.
+func DefaultBookSitemap() BookSitemap {
+	return BookSitemap{
+		Template:   config.SitemapBookURLTemplate(),
+		Operations: config.SitemapURLOperations(),
+		MaxURLs:    config.SitemapMaxURLs(),
+	}
+}
+
+// Pages returns how many sitemap files the catalog needs. An empty catalog
+// still has one, empty, file.
+func (s BookSitemap) Pages(db *gorm.DB) (int, error) {
+	var count int64
+	if err := db.Model(&models.Book{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 1, nil
+	}
+	return int((count + int64(s.MaxURLs) - 1) / int64(s.MaxURLs)), nil
+}
+
+// URLSet returns sitemap file page, counted from 1, with books in ID order.
+func (s BookSitemap) URLSet(db *gorm.DB, page int) (SitemapURLSet, error) {
+	if page < 1 {
+		return SitemapURLSet{}, ErrSitemapNotFound
+	}
+
+	var books []models.Book
+	err := db.Select("id", "slug", "updated_at").Order("id").
+		Offset((page - 1) * s.MaxURLs).Limit(s.MaxURLs).
+		Find(&books).Error
+	if err != nil {
+		return SitemapURLSet{}, err
+	}
+	if len(books) == 0 && page > 1 {
+		return SitemapURLSet{}, ErrSitemapNotFound
+	}
+	return s.BuildURLSet(books)
+}
+
+// Index returns a sitemap index listing every sitemap file, located with
+// pageURL, with the latest book update of each file as its lastmod. The files
+// are numbered and dated by the database, so no book is loaded.
+func (s BookSitemap) Index(db *gorm.DB, pageURL func(page int) string) (SitemapIndex, error) {
+	var pages []struct {
+		Page    int
+		LastMod time.Time
+	}
+	numbered := db.Model(&models.Book{}).
+		Select("(ROW_NUMBER() OVER (ORDER BY id) - 1) / ? + 1 AS page, updated_at", s.MaxURLs)
+	err := db.Table("(?) AS numbered", numbered).
+		Select("page, MAX(updated_at) AS last_mod").
+		Group("page").Order("page").
+		Scan(&pages).Error
+	if err != nil {
+		return SitemapIndex{}, err
+	}
+
+	index := SitemapIndex{Xmlns: SitemapNamespace}
+	for _, page := range pages {
+		index.Sitemaps = append(index.Sitemaps, SitemapEntry{
+			Loc:     pageURL(page.Page),
+			LastMod: sitemapTime(page.LastMod),
+		})
+	}
+	return index, nil
+}
+
+// BuildURLSet lists the canonical URL of every book.
+func (s BookSitemap) BuildURLSet(books []models.Book) (SitemapURLSet, error) {
+	resolved, err := URLOperations.Resolve(s.Operations)
+	if err != nil {
+		return SitemapURLSet{}, err
+	}
+
+	urlSet := SitemapURLSet{Xmlns: SitemapNamespace, URLs: make([]SitemapURL, 0, len(books))}
+	for _, book := range books {
+		canonical, err := s.canonicalURL(book, resolved)
+		if err != nil {
+			return SitemapURLSet{}, err
+		}
+		urlSet.URLs = append(urlSet.URLs, SitemapURL{Loc: canonical, LastMod: sitemapTime(book.UpdatedAt)})
+	}
+	return urlSet, nil
+}
+
+// CanonicalURL returns the canonical page URL of book, as listed in the
+// sitemap.
+func (s BookSitemap) CanonicalURL(book models.Book) (string, error) {
+	resolved, err := URLOperations.Resolve(s.Operations)
+	if err != nil {
+		return "", err
+	}
+	return s.canonicalURL(book, resolved)
+}
+
+// AttachURLs sets the canonical page URL of books. Books whose URL cannot be
+// built, such as books without a slug yet, are left without one.
+func (s BookSitemap) AttachURLs(books []models.Book) {
+	resolved, err := URLOperations.Resolve(s.Operations)
+	if err != nil {
+		return
+	}
+	for i := range books {
+		books[i].URL, _ = s.canonicalURL(books[i], resolved)
+	}
+}
+
+// BookURL fills the template in for book.
+func (s BookSitemap) BookURL(book models.Book) (string, error) {
+	hasID := strings.Contains(s.Template, "{id}")
+	hasSlug := strings.Contains(s.Template, "{slug}")
+	if !hasID && !hasSlug {
+		return "", ErrInvalidSitemapTemplate
+	}
+	if hasSlug && book.Slug == "" {
+		return "", fmt.Errorf("%w: book %d", ErrBookWithoutSlug, book.ID)
+	}
+	return strings.NewReplacer(
+		"{id}", strconv.FormatUint(uint64(book.ID), 10),
+		"{slug}", url.PathEscape(book.Slug),
+	).Replace(s.Template), nil
+}
+
+func (s BookSitemap) canonicalURL(book models.Book, operations []URLOperation) (string, error) {
+	bookURL, err := s.BookURL(book)
+	if err != nil {
+		return "", err
+	}
+	canonical, err := applyURLOperations(bookURL, operations)
+	if err != nil {
+		return "", fmt.Errorf("%w: book %d: %v", ErrSitemapURLNotCanonical, book.ID, err)
+	}
+	return canonical, nil
+}
+
+// sitemapTime formats a lastmod in the W3C datetime format sitemaps use.
+func sitemapTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
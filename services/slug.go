@@ -0,0 +1,226 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength caps slugs, cut at a word boundary.
+const maxSlugLength = 80
+
+// latinSpecials are Latin letters that do not decompose into an ASCII letter
+// and a combining mark.
+var latinSpecials = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i", '&': " and ",
+}
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+var greek = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// hiragana maps hiragana to Hepburn romaji. Katakana is looked up through the
+// matching hiragana.
+var hiragana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+}
+
+var smallKanaVowels = map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o"}
+
+// Hangul syllables are romanized jamo by jamo with the Revised Romanization,
+// without the sound changes between syllables.
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+// Slugify turns text into a lowercase, hyphen separated slug. Accents are
+// dropped and Cyrillic, Greek, kana and Hangul are transliterated. Han
+// characters have no reading without context, so they are kept as they are,
+// as words of their own, and are percent-encoded in URLs. Other scripts
+// without a transliteration are left out, so the slug can be empty.
+func Slugify(text string) string {
+	runes := []rune(strings.ToLower(norm.NFKC.String(text)))
+
+	var b strings.Builder
+	double := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		latin := transliterate(r)
+
+		if kana, ok := toHiragana(r); ok {
+			switch {
+			case kana == 'っ':
+				double = true
+				continue
+			case kana == 'ー':
+				continue
+			}
+			latin = hiragana[kana]
+			if i+1 < len(runes) {
+				if small, ok := toHiragana(runes[i+1]); ok && smallKanaVowels[small] != "" && strings.HasSuffix(latin, "i") {
+					latin = yoon(latin, smallKanaVowels[small])
+					i++
+				}
+			}
+			if double && latin != "" {
+				if strings.HasPrefix(latin, "ch") {
+					latin = "t" + latin
+				} else if !strings.ContainsRune("aeiou", rune(latin[0])) {
+					latin = latin[:1] + latin
+				}
+			}
+		}
+		double = false
+
+		b.WriteString(latin)
+	}
+
+	return joinSlugWords(b.String())
+}
+
+// transliterate returns the ASCII spelling of a rune, or " " when it has none
+// so that it separates words.
+func transliterate(r rune) string {
+	if latin, ok := latinSpecials[r]; ok {
+		return latin
+	}
+	switch {
+	case r < unicode.MaxASCII:
+		return string(r)
+	case unicode.Is(unicode.Han, r):
+		return string(r)
+	case r >= 0xAC00 && r <= 0xD7A3:
+		index := int(r - 0xAC00)
+		return hangulInitials[index/588] + hangulMedials[index%588/28] + hangulFinals[index%28]
+	}
+	for _, table := range []map[rune]string{cyrillic, greek} {
+		if latin, ok := table[r]; ok {
+			return latin
+		}
+	}
+
+	var b strings.Builder
+	for _, part := range norm.NFD.String(string(r)) {
+		if part < unicode.MaxASCII {
+			b.WriteRune(part)
+		} else if latin, ok := greek[part]; ok {
+			b.WriteString(latin)
+		} else if latin, ok := cyrillic[part]; ok {
+			b.WriteString(latin)
+		}
+	}
+	if b.Len() == 0 {
+		return " "
+	}
+	return b.String()
+}
+
+// toHiragana maps katakana onto hiragana and reports whether r is kana.
+func toHiragana(r rune) (rune, bool) {
+	switch {
+	case r == 'ー' || r == 'ッ':
+		if r == 'ッ' {
+			return 'っ', true
+		}
+		return r, true
+	case r >= 'ァ' && r <= 'ヶ':
+		return r - 0x60, true
+	case r >= 'ぁ' && r <= 'ゖ':
+		return r, true
+	}
+	return r, false
+}
+
+// yoon combines a syllable ending in i with a small ya, yu or yo: kya, sha,
+// cho.
+func yoon(syllable, vowel string) string {
+	stem := strings.TrimSuffix(syllable, "i")
+	switch stem {
+	case "sh", "ch", "j":
+		return stem + vowel
+	}
+	return stem + "y" + vowel
+}
+
+// joinSlugWords keeps ASCII letters, digits and Han characters, joins the
+// words with single hyphens and cuts the slug at a word boundary. A run of
+// Han characters is a word of its own.
+func joinSlugWords(text string) string {
+	var words []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || unicode.Is(unicode.Han, r))
+	}) {
+		words = append(words, splitHanRuns(field)...)
+	}
+
+	slug := ""
+	for _, word := range words {
+		next := word
+		if slug != "" {
+			next = slug + "-" + word
+		}
+		if len(next) > maxSlugLength {
+			if slug == "" {
+				slug = truncateRunes(word, maxSlugLength)
+			}
+			break
+		}
+		slug = next
+	}
+	return slug
+}
+
+// splitHanRuns splits a word where it changes between Han characters and
+// ASCII letters or digits.
+func splitHanRuns(word string) []string {
+	var parts []string
+	start, wasHan := 0, false
+	for i, r := range word {
+		isHan := unicode.Is(unicode.Han, r)
+		if i > 0 && isHan != wasHan {
+			parts = append(parts, word[start:i])
+			start = i
+		}
+		wasHan = isHan
+	}
+	return append(parts, word[start:])
+}
+
+// truncateRunes cuts s to at most max bytes without splitting a character.
+func truncateRunes(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
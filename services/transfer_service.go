@@ -0,0 +1,131 @@
+package services
+
+import (
+	"byfood-test-backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferNotFound   = errors.New("transfer not found")
+	ErrTransferSameBranch = errors.New("copy is already held by the destination branch")
+	ErrTransferPending    = errors.New("copy already has a pending transfer")
+	ErrTransferStatus     = errors.New("transfer cannot move to this status")
+	ErrCopyWithoutBranch  = errors.New("copy is not assigned to a branch")
+	ErrCopyNotOnShelf     = errors.New("copy must be on the shelf to be shipped")
+)
+
+var pendingTransferStatuses = []string{models.TransferStatusRequested, models.TransferStatusInTransit}
+
+// RequestTransfer asks for a copy to be moved to another branch.
+func RequestTransfer(db *gorm.DB, copyID, toBranchID uint) (models.Transfer, error) {
+	var transfer models.Transfer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var bookCopy models.Copy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, copyID).Error; err != nil {
+			return notFound(err, ErrCopyNotFound)
+		}
+		if err := checkBranch(tx, toBranchID); err != nil {
+			return err
+		}
+		if bookCopy.BranchID == nil {
+			return ErrCopyWithoutBranch
+		}
+		if *bookCopy.BranchID == toBranchID {
+			return ErrTransferSameBranch
+		}
+
+		var pending int64
+		if err := tx.Model(&models.Transfer{}).Where("copy_id = ? AND status IN ?", bookCopy.ID, pendingTransferStatuses).Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrTransferPending
+		}
+
+		transfer = models.Transfer{
+			CopyID:       bookCopy.ID,
+			FromBranchID: *bookCopy.BranchID,
+			ToBranchID:   toBranchID,
+			Status:       models.TransferStatusRequested,
+		}
+		return tx.Create(&transfer).Error
+	})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+// ShipTransfer sends the copy on its way. The copy has to be on the shelf and
+// is taken out of circulation until it is received.
+func ShipTransfer(db *gorm.DB, transferID uint, now time.Time) (models.Transfer, error) {
+	return advanceTransfer(db, transferID, models.TransferStatusRequested, func(tx *gorm.DB, transfer *models.Transfer, bookCopy *models.Copy) error {
+		if bookCopy.Status != models.CopyStatusAvailable {
+			return ErrCopyNotOnShelf
+		}
+		bookCopy.Status = models.CopyStatusInTransit
+		if err := tx.Save(bookCopy).Error; err != nil {
+			return err
+		}
+
+		transfer.Status = models.TransferStatusInTransit
+		transfer.ShippedAt = &now
+		return nil
+	})
+}
+
+// ReceiveTransfer records the arrival of the copy at its new branch and puts
+// it back into circulation, serving the hold queue first.
+func ReceiveTransfer(db *gorm.DB, transferID uint, location string, now time.Time) (models.Transfer, error) {
+	return advanceTransfer(db, transferID, models.TransferStatusInTransit, func(tx *gorm.DB, transfer *models.Transfer, bookCopy *models.Copy) error {
+		bookCopy.BranchID = &transfer.ToBranchID
+		bookCopy.Location = location
+		if err := releaseCopy(tx, bookCopy, now); err != nil {
+			return err
+		}
+
+		transfer.Status = models.TransferStatusReceived
+		transfer.ReceivedAt = &now
+		return nil
+	})
+}
+
+// CancelTransfer withdraws a transfer that has not been shipped yet.
+func CancelTransfer(db *gorm.DB, transferID uint) (models.Transfer, error) {
+	return advanceTransfer(db, transferID, models.TransferStatusRequested, func(tx *gorm.DB, transfer *models.Transfer, bookCopy *models.Copy) error {
+		transfer.Status = models.TransferStatusCancelled
+		return nil
+	})
+}
+
+// advanceTransfer locks a transfer and its copy, checks the transfer is in the
+// expected status and saves it after apply has moved it along.
+func advanceTransfer(db *gorm.DB, transferID uint, from string, apply func(tx *gorm.DB, transfer *models.Transfer, bookCopy *models.Copy) error) (models.Transfer, error) {
+	var transfer models.Transfer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, transferID).Error; err != nil {
+			return notFound(err, ErrTransferNotFound)
+		}
+		if transfer.Status != from {
+			return ErrTransferStatus
+		}
+
+		var bookCopy models.Copy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, transfer.CopyID).Error; err != nil {
+			return notFound(err, ErrCopyNotFound)
+		}
+
+		if err := apply(tx, &transfer, &bookCopy); err != nil {
+			return err
+		}
+		return tx.Save(&transfer).Error
+	})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
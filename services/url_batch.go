@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrEmptyURLBatch    = errors.New("batch holds no urls")
+	ErrURLBatchTooLarge = errors.New("batch holds too many urls")
+)
+
+// URLBatchResult is the outcome for one URL of a batch. Exactly one of
+// ProcessedURL and Error is set.
+type URLBatchResult struct {
+	Index        int    `json:"index" example:"0"`
+	URL          string `json:"url" example:"https://BYFOOD.com/food-EXPeriences?utm_source=x"`
+	ProcessedURL string `json:"processed_url,omitempty" example:"https://www.byfood.com/food-experiences"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ProcessURLBatch runs every URL through the named operations with at most
+// workers URLs in flight and returns the results in input order. Unknown
+// operations fail the whole batch; a URL that cannot be processed only fails
+// its own result.
+func ProcessURLBatch(urls []string, operations []string, maxURLs, workers int) ([]URLBatchResult, error) {
+	if len(urls) == 0 {
+		return nil, ErrEmptyURLBatch
+	}
+	if maxURLs > 0 && len(urls) > maxURLs {
+		return nil, ErrURLBatchTooLarge
+	}
+
+	results := make([]URLBatchResult, 0, len(urls))
+	next := 0
+	source := func() (string, error) {
+		if next == len(urls) {
+			return "", io.EOF
+		}
+		next++
+		return urls[next-1], nil
+	}
+	err := ProcessURLStream(source, operations, workers, func(result URLBatchResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// URLSource returns the URLs of a list one at a time, and io.EOF after the
+// last one.
+type URLSource func() (string, error)
+
+// ProcessURLStream runs the URLs of source through the named operations with
+// workers goroutines and hands the results to emit in input order. Only a
+// bounded number of URLs is read ahead, so lists of any length can be
+// processed. Processing stops at the first error of source or emit, which is
+// returned; a URL that cannot be processed only fails its own result.
+func ProcessURLStream(source URLSource, operations []string, workers int, emit func(URLBatchResult) error) error {
+	resolved, err := URLOperations.Resolve(operations)
+	if err != nil {
+		return err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		result URLBatchResult
+		done   chan URLBatchResult
+	}
+	jobs := make(chan job)
+	// pending holds the jobs in input order until their result is emitted.
+	pending := make(chan chan URLBatchResult, 4*workers)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if processed, err := applyURLOperations(j.result.URL, resolved); err != nil {
+					j.result.Error = err.Error()
+				} else {
+					j.result.ProcessedURL = processed
+				}
+				j.done <- j.result
+			}
+		}()
+	}
+
+	var sourceErr error
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for index := 0; ; index++ {
+			rawURL, err := source()
+			if err != nil {
+				if err != io.EOF {
+					sourceErr = err
+				}
+				return
+			}
+
+			j := job{result: URLBatchResult{Index: index, URL: rawURL}, done: make(chan URLBatchResult, 1)}
+			select {
+			case pending <- j.done:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var emitErr error
+	for done := range pending {
+		if emitErr != nil {
+			// The job may never have been handed to a worker.
+			continue
+		}
+		if emitErr = emit(<-done); emitErr != nil {
+			close(stop)
+		}
+	}
+	wg.Wait()
+
+	if emitErr != nil {
+		return emitErr
+	}
+	return sourceErr
+}
+
+// ReadURLList reads URLs from an uploaded file. CSV files use the column
+// headed "url", or the first column when there is no such header; any other
+// file is read as one URL per line. Blank lines are skipped. Reading stops
+// with ErrURLBatchTooLarge as soon as there are more than maxURLs URLs, unless
+// maxURLs is zero.
+func ReadURLList(r io.Reader, csvFormat bool, maxURLs int) ([]string, error) {
+	source, err := NewURLSource(r, csvFormat, "url")
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for {
+		rawURL, err := source()
+		if err == io.EOF {
+			return urls, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if maxURLs > 0 && len(urls) == maxURLs {
+			return nil, ErrURLBatchTooLarge
+		}
+		urls = append(urls, rawURL)
+	}
+}
+
+// NewURLSource reads URLs from r one at a time, like ReadURLList. CSV input
+// uses the column headed column; when there is no such header the first
+// column is used if column is "url", and it is an error otherwise.
+func NewURLSource(r io.Reader, csvFormat bool, column string) (URLSource, error) {
+	if csvFormat {
+		return newCSVURLSource(r, column)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return func() (string, error) {
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				return line, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}, nil
+}
+
+func newCSVURLSource(r io.Reader, column string) (URLSource, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return func() (string, error) { return "", io.EOF }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			index = i
+			break
+		}
+	}
+	// Without a header the first record is data.
+	var first []string
+	if index < 0 {
+		if !strings.EqualFold(column, "url") {
+			return nil, fmt.Errorf("csv has no %q column", column)
+		}
+		index = 0
+		first = append(first, header...)
+	}
+
+	return func() (string, error) {
+		for {
+			record := first
+			if record != nil {
+				first = nil
+			} else if record, err = reader.Read(); err != nil {
+				return "", err
+			}
+			if index < len(record) {
+				if value := strings.TrimSpace(record[index]); value != "" {
+					return value, nil
+				}
+			}
+		}
+	}, nil
+}
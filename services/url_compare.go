@@ -0,0 +1,55 @@
+package services
+
+import "errors"
+
+var ErrTooFewURLsToCompare = errors.New("at least two urls are needed to compare")
+
+// URLCluster groups the URLs that share a processed URL, listed by their
+// position in the request.
+type URLCluster struct {
+	ProcessedURL string   `json:"processed_url" example:"https://www.byfood.com/food-experiences"`
+	Indexes      []int    `json:"indexes" example:"0,2"`
+	URLs         []string `json:"urls"`
+}
+
+// URLComparison tells whether URLs point at the same page once processed.
+// Equivalent is true when every URL was processed and all of them ended up
+// identical. URLs that could not be processed appear in Results with their
+// error but in no cluster.
+type URLComparison struct {
+	Equivalent bool             `json:"equivalent" example:"false"`
+	Results    []URLBatchResult `json:"results"`
+	Clusters   []URLCluster     `json:"clusters"`
+}
+
+// CompareURLs processes urls like ProcessURLBatch and groups them into
+// clusters of equivalent URLs, ordered by the first URL of each cluster.
+func CompareURLs(urls []string, operations []string, maxURLs, workers int) (URLComparison, error) {
+	if len(urls) < 2 {
+		return URLComparison{}, ErrTooFewURLsToCompare
+	}
+	results, err := ProcessURLBatch(urls, operations, maxURLs, workers)
+	if err != nil {
+		return URLComparison{}, err
+	}
+
+	comparison := URLComparison{Results: results, Clusters: []URLCluster{}}
+	clusters := make(map[string]int)
+	failed := false
+	for _, result := range results {
+		if result.Error != "" {
+			failed = true
+			continue
+		}
+		i, ok := clusters[result.ProcessedURL]
+		if !ok {
+			i = len(comparison.Clusters)
+			clusters[result.ProcessedURL] = i
+			comparison.Clusters = append(comparison.Clusters, URLCluster{ProcessedURL: result.ProcessedURL})
+		}
+		comparison.Clusters[i].Indexes = append(comparison.Clusters[i].Indexes, result.Index)
+		comparison.Clusters[i].URLs = append(comparison.Clusters[i].URLs, result.URL)
+	}
+	comparison.Equivalent = !failed && len(comparison.Clusters) == 1
+	return comparison, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"net/url"
+)
+
+const (
+	URLPartScheme   = "scheme"
+	URLPartHost     = "host"
+	URLPartPath     = "path"
+	URLPartQuery    = "query"
+	URLPartFragment = "fragment"
+)
+
+// URLExplanation tells how ProcessURL arrived at its result.
+type URLExplanation struct {
+	OriginalURL  string `json:"original_url" example:"https://BYFOOD.com/food-EXPeriences/?utm_source=x"`
+	ProcessedURL string `json:"processed_url" example:"https://www.byfood.com/food-experiences"`
+	// AlreadyCanonical is true when no rule changed the URL.
+	AlreadyCanonical bool `json:"already_canonical" example:"false"`
+	// ChangedParts lists the URL parts that differ between the original and
+	// the processed URL.
+	ChangedParts []string  `json:"changed_parts" example:"host,path,query"`
+	Steps        []URLStep `json:"steps"`
+	// Alternates holds the hreflang alternates when they were asked for.
+	Alternates []HreflangAlternate `json:"alternates,omitempty"`
+}
+
+// URLStep is a rule that changed the URL.
+type URLStep struct {
+	Operation string `json:"operation" example:"all"`
+	Rule      string `json:"rule" example:"host_mappings"`
+	Before    string `json:"before" example:"https://BYFOOD.com/food-EXPeriences/?utm_source=x"`
+	After     string `json:"after" example:"https://www.byfood.com/food-EXPeriences/?utm_source=x"`
+}
+
+// ExplainURL processes a URL like ProcessURL and records every rule that
+// changed it. Rule sets are reported rule by rule; other operations are
+// reported as a single rule named after the operation.
+func ExplainURL(originalURL string, operations ...string) (URLExplanation, error) {
+	resolved, err := URLOperations.Resolve(operations)
+	if err != nil {
+		return URLExplanation{}, err
+	}
+
+	parsedURL, err := DefaultURLPolicy().ValidateURL(originalURL)
+	if err != nil {
+		return URLExplanation{}, err
+	}
+	original := *parsedURL
+
+	explanation := URLExplanation{OriginalURL: originalURL, Steps: []URLStep{}}
+	for _, operation := range resolved {
+		steps := []urlStep{{rule: operation.Name(), apply: operation.Apply}}
+		if stepped, ok := operation.(steppedURLOperation); ok {
+			steps = stepped.steps()
+		}
+
+		for _, step := range steps {
+			before := parsedURL.String()
+			if err := step.apply(parsedURL); err != nil {
+				return URLExplanation{}, err
+			}
+			if after := parsedURL.String(); after != before {
+				explanation.Steps = append(explanation.Steps, URLStep{
+					Operation: operation.Name(),
+					Rule:      step.rule,
+					Before:    before,
+					After:     after,
+				})
+			}
+		}
+	}
+
+	explanation.ProcessedURL = parsedURL.String()
+	explanation.ChangedParts = changedURLParts(&original, parsedURL)
+	explanation.AlreadyCanonical = explanation.ProcessedURL == original.String()
+	return explanation, nil
+}
+
+func changedURLParts(before, after *url.URL) []string {
+	parts := []string{}
+	if before.Scheme != after.Scheme {
+		parts = append(parts, URLPartScheme)
+	}
+	if before.Host != after.Host || before.User.String() != after.User.String() {
+		parts = append(parts, URLPartHost)
+	}
+	if before.EscapedPath() != after.EscapedPath() {
+		parts = append(parts, URLPartPath)
+	}
+	if before.RawQuery != after.RawQuery || before.ForceQuery != after.ForceQuery {
+		parts = append(parts, URLPartQuery)
+	}
+	if before.EscapedFragment() != after.EscapedFragment() {
+		parts = append(parts, URLPartFragment)
+	}
+	return parts
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// LocaleRules describe the locales served under a path prefix such as /ja/
+// or /zh-tw/. Prefixes are matched case-insensitively with "_" and "-"
+// treated alike, so /JA/ and /zh_TW/ are rewritten to /ja/ and /zh-tw/.
+type LocaleRules struct {
+	Locales []string `json:"locales" yaml:"locales"`
+	// Default is the locale of paths without a prefix. Its prefix is removed
+	// unless PrefixDefault is set, in which case unprefixed paths get it.
+	Default       string `json:"default,omitempty" yaml:"default"`
+	PrefixDefault bool   `json:"prefix_default,omitempty" yaml:"prefix_default"`
+	// QueryParameter names a parameter such as lang whose value, when it is a
+	// configured locale, is moved into the path prefix. A prefix already in the
+	// path wins over the parameter.
+	QueryParameter string `json:"query_parameter,omitempty" yaml:"query_parameter"`
+}
+
+// HreflangAlternate is the URL of a page in one locale.
+type HreflangAlternate struct {
+	Hreflang string `json:"hreflang" example:"zh-TW"`
+	URL      string `json:"url" example:"https://www.byfood.com/zh-tw/food-experiences"`
+}
+
+// DefaultLocaleRules returns the locale rules from the configuration, used by
+// the locale operation and for hreflang alternates when no rule set defines
+// its own.
+func DefaultLocaleRules() LocaleRules {
+	return LocaleRules{
+		Locales:        config.URLLocales(),
+		Default:        config.URLDefaultLocale(),
+		QueryParameter: config.URLLocaleQueryParameter(),
+	}
+}
+
+// Match returns the configured locale a tag such as "JA" or "zh_TW" stands
+// for.
+func (l LocaleRules) Match(tag string) (string, bool) {
+	tag = normalizeLocale(tag)
+	for _, locale := range l.Locales {
+		if normalizeLocale(locale) == tag {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// normalizeLocale writes a locale the way it appears in path prefixes, so
+// "zh_TW" becomes "zh-tw".
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(locale), "_", "-")
+}
+
+// Split separates the locale prefix from a path. Paths without a configured
+// prefix are returned unchanged with ok=false.
+func (l LocaleRules) Split(path string) (locale string, rest string, ok bool) {
+	if !strings.HasPrefix(path, "/") {
+		return "", path, false
+	}
+	segment, rest, _ := strings.Cut(path[1:], "/")
+	locale, ok = l.Match(segment)
+	if !ok {
+		return "", path, false
+	}
+	return locale, "/" + rest, true
+}
+
+// Path returns rest, a path without locale prefix, served in locale.
+func (l LocaleRules) Path(locale, rest string) string {
+	if rest == "" {
+		rest = "/"
+	}
+	if locale == "" || (locale == normalizeLocale(l.Default) && !l.PrefixDefault) {
+		return rest
+	}
+	return "/" + locale + rest
+}
+
+// apply works on the escaped path so escapes such as %2F in the rest of the
+// path are kept.
+func (l LocaleRules) apply(u *url.URL) {
+	locale, rest, found := l.Split(u.EscapedPath())
+
+	if l.QueryParameter != "" {
+		value, remaining, present := removeQueryParameter(u.RawQuery, l.QueryParameter)
+		if fromQuery, ok := l.Match(value); present && ok {
+			u.RawQuery = remaining
+			if !found {
+				locale, found = fromQuery, true
+			}
+		}
+	}
+
+	if !found {
+		if !l.PrefixDefault || l.Default == "" {
+			return
+		}
+		locale = normalizeLocale(l.Default)
+	}
+	// rest comes from EscapedPath, so it is always a valid escaped path.
+	_ = setEscapedPath(u, l.Path(locale, rest))
+}
+
+// Alternates returns the URL of the page u shows in every configured locale,
+// followed by an x-default entry for the default locale when there is one.
+func (l LocaleRules) Alternates(u *url.URL) []HreflangAlternate {
+	_, rest, _ := l.Split(u.EscapedPath())
+
+	alternates := make([]HreflangAlternate, 0, len(l.Locales)+1)
+	for _, locale := range l.Locales {
+		alternates = append(alternates, HreflangAlternate{Hreflang: hreflangTag(locale), URL: l.localeURL(u, normalizeLocale(locale), rest)})
+	}
+	if l.Default != "" {
+		alternates = append(alternates, HreflangAlternate{Hreflang: "x-default", URL: l.localeURL(u, normalizeLocale(l.Default), rest)})
+	}
+	return alternates
+}
+
+func (l LocaleRules) localeURL(u *url.URL, locale, rest string) string {
+	alternate := *u
+	_ = setEscapedPath(&alternate, l.Path(locale, rest))
+	return alternate.String()
+}
+
+func (l LocaleRules) validate() error {
+	if len(l.Locales) == 0 {
+		return fmt.Errorf("locale rules need at least one locale")
+	}
+	if l.Default != "" {
+		if _, ok := l.Match(l.Default); !ok {
+			return fmt.Errorf("default locale %q is not one of the locales", l.Default)
+		}
+	}
+	return nil
+}
+
+// LocaleAlternates returns the hreflang alternates of a processed URL, using
+// the locale rules of the last rule set among operations that has them and
+// the configured locales otherwise.
+func LocaleAlternates(processedURL string, operations ...string) ([]HreflangAlternate, error) {
+	resolved, err := URLOperations.Resolve(operations)
+	if err != nil {
+		return nil, err
+	}
+	parsedURL, err := url.Parse(processedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := DefaultLocaleRules()
+	for _, operation := range resolved {
+		if ruleSet, ok := operation.(ruleSetOperation); ok && ruleSet.ruleSet.Locale != nil {
+			rules = *ruleSet.ruleSet.Locale
+		}
+	}
+	return rules.Alternates(parsedURL), nil
+}
+
+// hreflangTag formats a locale the way hreflang attributes usually spell it:
+// lowercase language, uppercase region and title case script, e.g. zh-TW.
+func hreflangTag(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// removeQueryParameter removes every occurrence of name from rawQuery and
+// returns the value of the first one.
+func removeQueryParameter(rawQuery, name string) (value string, rest string, present bool) {
+	var kept []string
+	for _, parameter := range parseQueryParameters(rawQuery) {
+		if parameter.key == name {
+			if !present {
+				value, present = parameter.value, true
+			}
+			continue
+		}
+		kept = append(kept, parameter.raw)
+	}
+	return value, strings.Join(kept, "&"), present
+}
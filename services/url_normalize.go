@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// defaultPorts are dropped from normalized URLs of the matching scheme.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// normalizeURL applies the RFC 3986 section 6 normalizations that do not
+// change what a URL points to: lowercase scheme and host, punycode for
+// internationalized hosts, no default port, uppercase percent-encoding with
+// unreserved characters decoded, and no "." or ".." path segments.
+func normalizeURL(u *url.URL) error {
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	if u.Host != "" {
+		host, err := normalizeHost(u.Hostname())
+		if err != nil {
+			return err
+		}
+		port := u.Port()
+		if port == defaultPorts[u.Scheme] {
+			port = ""
+		}
+		if port != "" {
+			u.Host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			u.Host = "[" + host + "]"
+		} else {
+			u.Host = host
+		}
+	}
+
+	path := normalizePercentEncoding(u.EscapedPath())
+	path = removeDotSegments(path)
+	if path == "" && u.Host != "" && defaultPorts[u.Scheme] != "" {
+		path = "/"
+	}
+	if err := setEscapedPath(u, path); err != nil {
+		return err
+	}
+
+	u.RawQuery = normalizePercentEncoding(u.RawQuery)
+
+	if u.Fragment != "" {
+		fragment := normalizePercentEncoding(u.EscapedFragment())
+		unescaped, err := url.PathUnescape(fragment)
+		if err != nil {
+			return fmt.Errorf("invalid fragment: %w", err)
+		}
+		u.Fragment = unescaped
+		u.RawFragment = fragment
+	}
+	return nil
+}
+
+// normalizeHost lowercases a host and converts internationalized names to
+// their punycode form. IP literals are returned as they are.
+func normalizeHost(host string) (string, error) {
+	host = strings.ToLower(host)
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	if isASCII(host) {
+		return host, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	return ascii, nil
+}
+
+// normalizePercentEncoding uppercases the hex digits of every percent-encoded
+// octet and decodes the octets that are unreserved characters.
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		octet := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(octet) {
+			b.WriteByte(octet)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// removeDotSegments implements the algorithm of RFC 3986 section 5.2.4.
+func removeDotSegments(path string) string {
+	var output []string
+	for path != "" {
+		switch {
+		case strings.HasPrefix(path, "../"):
+			path = path[3:]
+		case strings.HasPrefix(path, "./"):
+			path = path[2:]
+		case strings.HasPrefix(path, "/./"):
+			path = path[2:]
+		case path == "/.":
+			path = "/"
+		case strings.HasPrefix(path, "/../"):
+			path = path[3:]
+			output = dropLastSegment(output)
+		case path == "/..":
+			path = "/"
+			output = dropLastSegment(output)
+		case path == "." || path == "..":
+			path = ""
+		default:
+			end := strings.IndexByte(path[1:], '/') + 1
+			if end == 0 {
+				end = len(path)
+			}
+			output = append(output, path[:end])
+			path = path[end:]
+		}
+	}
+	return strings.Join(output, "")
+}
+
+func dropLastSegment(segments []string) []string {
+	if len(segments) == 0 {
+		return segments
+	}
+	return segments[:len(segments)-1]
+}
+
+func setEscapedPath(u *url.URL, escaped string) error {
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	u.Path = path
+	u.RawPath = escaped
+	return nil
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
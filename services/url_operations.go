@@ -0,0 +1,209 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownURLOperation = errors.New("unknown url operation")
+
+const (
+	URLOperationKindBuiltin = "builtin"
+	URLOperationKindRuleSet = "rule_set"
+)
+
+// URLOperation is a named rewrite step of /api/process_url. Operations can be
+// chained; each one receives the URL as left by the previous one.
+type URLOperation interface {
+	Name() string
+	Description() string
+	Apply(u *url.URL) error
+}
+
+// URLOperationInfo describes an operation for discovery.
+type URLOperationInfo struct {
+	Name        string `json:"name" example:"strip_tracking"`
+	Description string `json:"description" example:"Remove utm_*, gclid, fbclid and other tracking parameters"`
+	Kind        string `json:"kind" example:"builtin"`
+}
+
+type urlOperationFunc struct {
+	name        string
+	description string
+	apply       func(u *url.URL) error
+}
+
+func (o urlOperationFunc) Name() string           { return o.name }
+func (o urlOperationFunc) Description() string    { return o.description }
+func (o urlOperationFunc) Apply(u *url.URL) error { return o.apply(u) }
+
+// ruleSetOperation exposes a rule set of the rule engine as an operation.
+type ruleSetOperation struct {
+	ruleSet RuleSet
+}
+
+func (o ruleSetOperation) Name() string { return o.ruleSet.Name }
+
+func (o ruleSetOperation) Description() string {
+	if o.ruleSet.Description != "" {
+		return o.ruleSet.Description
+	}
+	return "Rule set " + o.ruleSet.Name
+}
+
+func (o ruleSetOperation) Apply(u *url.URL) error { return o.ruleSet.Apply(u) }
+
+func (o ruleSetOperation) steps() []urlStep { return o.ruleSet.steps() }
+
+// steppedURLOperation is implemented by operations made of several rules, so
+// ExplainURL can report each rule on its own.
+type steppedURLOperation interface {
+	steps() []urlStep
+}
+
+// URLOperationRegistry resolves operation names to operations. It serves the
+// registered built-in operations and every rule set of its rule engine; a
+// rule set takes precedence over a built-in operation of the same name so the
+// rules file can override it.
+type URLOperationRegistry struct {
+	mu         sync.RWMutex
+	operations map[string]URLOperation
+	rules      *URLRuleEngine
+}
+
+// URLOperations is the registry used by the URL processing endpoints.
+var URLOperations = NewURLOperationRegistry(URLRules, BuiltinURLOperations()...)
+
+// NewURLOperationRegistry returns a registry holding operations and the rule
+// sets of rules. rules may be nil.
+func NewURLOperationRegistry(rules *URLRuleEngine, operations ...URLOperation) *URLOperationRegistry {
+	registry := &URLOperationRegistry{operations: map[string]URLOperation{}, rules: rules}
+	for _, operation := range operations {
+		registry.Register(operation)
+	}
+	return registry
+}
+
+// Register adds an operation, replacing any operation with the same name.
+func (r *URLOperationRegistry) Register(operation URLOperation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations[operation.Name()] = operation
+}
+
+// Lookup returns the named operation.
+func (r *URLOperationRegistry) Lookup(name string) (URLOperation, bool) {
+	if r.rules != nil {
+		if ruleSet, ok := r.rules.RuleSet(name); ok {
+			return ruleSetOperation{ruleSet: ruleSet}, true
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	operation, ok := r.operations[name]
+	return operation, ok
+}
+
+// Resolve looks up every name in order. It fails with ErrUnknownURLOperation
+// on the first name that is not registered.
+func (r *URLOperationRegistry) Resolve(names []string) ([]URLOperation, error) {
+	operations := make([]URLOperation, 0, len(names))
+	for _, name := range names {
+		operation, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownURLOperation, name)
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
+
+// Operations describes every available operation sorted by name.
+func (r *URLOperationRegistry) Operations() []URLOperationInfo {
+	byName := map[string]URLOperationInfo{}
+
+	r.mu.RLock()
+	for name, operation := range r.operations {
+		byName[name] = URLOperationInfo{Name: name, Description: operation.Description(), Kind: URLOperationKindBuiltin}
+	}
+	r.mu.RUnlock()
+
+	if r.rules != nil {
+		for _, ruleSet := range r.rules.RuleSets() {
+			operation := ruleSetOperation{ruleSet: ruleSet}
+			byName[ruleSet.Name] = URLOperationInfo{Name: ruleSet.Name, Description: operation.Description(), Kind: URLOperationKindRuleSet}
+		}
+	}
+
+	infos := make([]URLOperationInfo, 0, len(byName))
+	for _, info := range byName {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Names returns the names of every available operation, sorted.
+func (r *URLOperationRegistry) Names() []string {
+	infos := r.Operations()
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+	return names
+}
+
+// BuiltinURLOperations returns the operations available without a rules file.
+func BuiltinURLOperations() []URLOperation {
+	return []URLOperation{
+		urlOperationFunc{"normalize", "Apply RFC 3986 normalization", normalizeURL},
+		urlOperationFunc{"locale", "Canonicalize the locale prefix of the path from URL_LOCALES, moving ?lang= into it", func(u *url.URL) error {
+			DefaultLocaleRules().apply(u)
+			return nil
+		}},
+		urlOperationFunc{"strip_tracking", "Remove utm_*, gclid, fbclid and other tracking parameters", func(u *url.URL) error {
+			u.RawQuery = QueryRules{Mode: QueryModeClean}.Apply(u.RawQuery)
+			return nil
+		}},
+		urlOperationFunc{"sort_query", "Sort query parameters by name", func(u *url.URL) error {
+			u.RawQuery = QueryRules{Sort: true}.Apply(u.RawQuery)
+			return nil
+		}},
+		urlOperationFunc{"drop_query", "Remove the query string", func(u *url.URL) error {
+			u.RawQuery = ""
+			u.ForceQuery = false
+			return nil
+		}},
+		urlOperationFunc{"strip_fragment", "Remove the fragment", func(u *url.URL) error {
+			u.Fragment = ""
+			u.RawFragment = ""
+			return nil
+		}},
+		urlOperationFunc{"lowercase_host", "Lowercase the host", func(u *url.URL) error {
+			u.Host = strings.ToLower(u.Host)
+			return nil
+		}},
+		urlOperationFunc{"lowercase_path", "Lowercase the path", func(u *url.URL) error {
+			RuleSet{PathCase: PathCaseLower}.applyPathCase(u)
+			return nil
+		}},
+		urlOperationFunc{"force_host", "Replace the host with URL_FORCE_HOST", func(u *url.URL) error {
+			u.Host = config.URLForceHost()
+			return nil
+		}},
+		urlOperationFunc{"strip_trailing_slash", "Remove the trailing slash of the path", func(u *url.URL) error {
+			RuleSet{TrailingSlash: TrailingSlashStrip}.applyTrailingSlash(u)
+			return nil
+		}},
+		urlOperationFunc{"add_trailing_slash", "Add a trailing slash to the path", func(u *url.URL) error {
+			RuleSet{TrailingSlash: TrailingSlashAdd}.applyTrailingSlash(u)
+			return nil
+		}},
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	QueryModeKeep  = "keep"
+	QueryModeDrop  = "drop"
+	QueryModeAllow = "allow"
+	QueryModeDeny  = "deny"
+	QueryModeClean = "clean"
+)
+
+// DefaultTrackingParameters are removed by the "clean" query mode. A trailing
+// "*" matches any parameter starting with the prefix.
+var DefaultTrackingParameters = []string{
+	"utm_*",
+	"gclid",
+	"gclsrc",
+	"dclid",
+	"fbclid",
+	"msclkid",
+	"yclid",
+	"twclid",
+	"mc_cid",
+	"mc_eid",
+	"_ga",
+	"_gl",
+	"_hsenc",
+	"_hsmi",
+}
+
+// QueryRules decides which query parameters survive.
+//
+// Mode "keep" leaves the parameters alone, "drop" removes them all, "allow"
+// keeps only the Allow parameters and "deny" removes the Deny parameters.
+// Mode "clean" removes the DefaultTrackingParameters and the Deny parameters
+// while keeping any parameter listed in Allow. Parameter names are matched
+// case-insensitively and a trailing "*" matches a prefix.
+//
+// DropEmpty removes parameters without a value and Sort orders the remaining
+// ones by name, then value, so equivalent URLs end up identical.
+type QueryRules struct {
+	Mode      string   `json:"mode,omitempty" yaml:"mode"`
+	Allow     []string `json:"allow,omitempty" yaml:"allow"`
+	Deny      []string `json:"deny,omitempty" yaml:"deny"`
+	DropEmpty bool     `json:"drop_empty,omitempty" yaml:"drop_empty"`
+	Sort      bool     `json:"sort,omitempty" yaml:"sort"`
+}
+
+type queryParameter struct {
+	raw   string
+	key   string
+	value string
+}
+
+func (r RuleSet) applyQuery(u *url.URL) {
+	rules := r.Query
+	if hostRules, ok := r.HostQuery[strings.ToLower(u.Hostname())]; ok {
+		rules = hostRules
+	}
+	u.RawQuery = rules.Apply(u.RawQuery)
+	u.ForceQuery = false
+}
+
+// Apply returns rawQuery with the rules applied. Surviving parameters keep
+// their original encoding.
+func (q QueryRules) Apply(rawQuery string) string {
+	if q.Mode == QueryModeDrop {
+		return ""
+	}
+	if (q.Mode == "" || q.Mode == QueryModeKeep) && !q.DropEmpty && !q.Sort {
+		return rawQuery
+	}
+
+	parameters := parseQueryParameters(rawQuery)
+	kept := parameters[:0]
+	for _, parameter := range parameters {
+		if q.keeps(parameter) {
+			kept = append(kept, parameter)
+		}
+	}
+
+	if q.Sort {
+		sort.SliceStable(kept, func(i, j int) bool {
+			if kept[i].key != kept[j].key {
+				return kept[i].key < kept[j].key
+			}
+			return kept[i].value < kept[j].value
+		})
+	}
+
+	raw := make([]string, len(kept))
+	for i, parameter := range kept {
+		raw[i] = parameter.raw
+	}
+	return strings.Join(raw, "&")
+}
+
+func (q QueryRules) keeps(parameter queryParameter) bool {
+	if q.DropEmpty && parameter.value == "" {
+		return false
+	}
+
+	switch q.Mode {
+	case QueryModeAllow:
+		return matchesParameter(q.Allow, parameter.key)
+	case QueryModeDeny:
+		return !matchesParameter(q.Deny, parameter.key)
+	case QueryModeClean:
+		if matchesParameter(q.Allow, parameter.key) {
+			return true
+		}
+		return !matchesParameter(DefaultTrackingParameters, parameter.key) && !matchesParameter(q.Deny, parameter.key)
+	default:
+		return true
+	}
+}
+
+func (q QueryRules) validate() error {
+	switch q.Mode {
+	case "", QueryModeKeep, QueryModeDrop, QueryModeAllow, QueryModeDeny, QueryModeClean:
+		return nil
+	default:
+		return fmt.Errorf("unknown query mode %q", q.Mode)
+	}
+}
+
+func parseQueryParameters(rawQuery string) []queryParameter {
+	var parameters []queryParameter
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(raw, "=")
+		parameters = append(parameters, queryParameter{
+			raw:   raw,
+			key:   unescapeQueryComponent(rawKey),
+			value: unescapeQueryComponent(rawValue),
+		})
+	}
+	return parameters
+}
+
+func unescapeQueryComponent(raw string) string {
+	unescaped, err := url.QueryUnescape(raw)
+	if err != nil {
+		return raw
+	}
+	return unescaped
+}
+
+func matchesParameter(patterns []string, key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if pattern == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PathCasePreserve = "preserve"
+	PathCaseLower    = "lower"
+
+	TrailingSlashPreserve = "preserve"
+	TrailingSlashStrip    = "strip"
+	TrailingSlashAdd      = "add"
+
+	FragmentPreserve = "preserve"
+	FragmentStrip    = "strip"
+)
+
+// RuleSet is a named group of URL rewriting rules. The rules are applied in
+// the order RFC 3986 normalization, host mapping, locale prefix, path case,
+// path prefix rewrites, trailing slash, query parameters and fragment.
+type RuleSet struct {
+	Name        string `json:"name" yaml:"-"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	// Normalize applies the RFC 3986 normalizations before any other rule.
+	Normalize bool `json:"normalize,omitempty" yaml:"normalize"`
+	// HostMappings maps lowercase source hosts to target hosts. The "*" key
+	// applies to every host without an explicit mapping.
+	HostMappings   map[string]string `json:"host_mappings,omitempty" yaml:"host_mappings"`
+	Locale         *LocaleRules      `json:"locale,omitempty" yaml:"locale"`
+	PathCase       string            `json:"path_case,omitempty" yaml:"path_case"`
+	PrefixRewrites []PrefixRewrite   `json:"prefix_rewrites,omitempty" yaml:"prefix_rewrites"`
+	TrailingSlash  string            `json:"trailing_slash,omitempty" yaml:"trailing_slash"`
+	Query          QueryRules        `json:"query" yaml:"query"`
+	// HostQuery replaces Query for the listed hosts, matched after host mapping.
+	HostQuery map[string]QueryRules `json:"host_query,omitempty" yaml:"host_query"`
+	Fragment  string                `json:"fragment,omitempty" yaml:"fragment"`
+}
+
+// PrefixRewrite replaces a leading path prefix. Prefixes match whole path
+// segments, so "/books" matches "/books/1" but not "/bookshelf".
+type PrefixRewrite struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+type urlRulesFile struct {
+	RuleSets map[string]RuleSet `json:"rule_sets" yaml:"rule_sets"`
+}
+
+// DefaultRuleSets are used when no rules file is configured. They are the
+// rule sets of config/url_rules.yaml, built into the binary, so the output
+// does not depend on whether URL_RULES_FILE points at that file.
+func DefaultRuleSets() map[string]RuleSet {
+	ruleSets, err := parseRuleSets(config.DefaultURLRules, false)
+	if err != nil {
+		panic(fmt.Sprintf("built-in url rules: %v", err))
+	}
+	return ruleSets
+}
+
+// Apply rewrites u in place according to the rule set. It only fails when
+// normalization finds an invalid host or escape sequence.
+func (r RuleSet) Apply(u *url.URL) error {
+	for _, step := range r.steps() {
+		if err := step.apply(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// urlStep is one named rule of a rule set, the unit reported by ExplainURL.
+type urlStep struct {
+	rule  string
+	apply func(u *url.URL) error
+}
+
+// steps returns the rules of the rule set in the order they are applied.
+func (r RuleSet) steps() []urlStep {
+	var steps []urlStep
+	if r.Normalize {
+		steps = append(steps, urlStep{"normalize", normalizeURL})
+	}
+	return append(steps,
+		urlStep{"host_mappings", infallible(r.applyHost)},
+		urlStep{"locale", infallible(r.applyLocale)},
+		urlStep{"path_case", infallible(r.applyPathCase)},
+		urlStep{"prefix_rewrites", infallible(r.applyPrefixRewrites)},
+		urlStep{"trailing_slash", infallible(r.applyTrailingSlash)},
+		urlStep{"query", infallible(r.applyQuery)},
+		urlStep{"fragment", infallible(r.applyFragment)},
+	)
+}
+
+func infallible(apply func(u *url.URL)) func(u *url.URL) error {
+	return func(u *url.URL) error {
+		apply(u)
+		return nil
+	}
+}
+
+func (r RuleSet) applyHost(u *url.URL) {
+	if len(r.HostMappings) == 0 {
+		return
+	}
+	if target, ok := r.HostMappings[strings.ToLower(u.Hostname())]; ok {
+		u.Host = target
+	} else if target, ok := r.HostMappings["*"]; ok {
+		u.Host = target
+	}
+}
+
+func (r RuleSet) applyLocale(u *url.URL) {
+	if r.Locale != nil {
+		r.Locale.apply(u)
+	}
+}
+
+func (r RuleSet) applyPrefixRewrites(u *url.URL) {
+	for _, rewrite := range r.PrefixRewrites {
+		from := strings.TrimSuffix(rewrite.From, "/")
+		if u.Path != from && !strings.HasPrefix(u.Path, from+"/") {
+			continue
+		}
+		u.Path = rewrite.To + strings.TrimPrefix(u.Path, from)
+		u.RawPath = ""
+		return
+	}
+}
+
+func (r RuleSet) applyPathCase(u *url.URL) {
+	if r.PathCase == PathCaseLower {
+		u.Path = strings.ToLower(u.Path)
+		u.RawPath = ""
+	}
+}
+
+func (r RuleSet) applyTrailingSlash(u *url.URL) {
+	switch r.TrailingSlash {
+	case TrailingSlashStrip:
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	case TrailingSlashAdd:
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+	default:
+		return
+	}
+	u.RawPath = ""
+}
+
+func (r RuleSet) applyFragment(u *url.URL) {
+	if r.Fragment == FragmentStrip {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+}
+
+// lowercaseHosts lowercases the hosts of the host mappings and host query
+// rules, which are matched against lowercased hosts.
+func (r *RuleSet) lowercaseHosts() {
+	if r.HostMappings != nil {
+		mappings := make(map[string]string, len(r.HostMappings))
+		for host, target := range r.HostMappings {
+			mappings[strings.ToLower(host)] = target
+		}
+		r.HostMappings = mappings
+	}
+	if r.HostQuery != nil {
+		queries := make(map[string]QueryRules, len(r.HostQuery))
+		for host, query := range r.HostQuery {
+			queries[strings.ToLower(host)] = query
+		}
+		r.HostQuery = queries
+	}
+}
+
+func (r RuleSet) validate() error {
+	switch r.PathCase {
+	case "", PathCasePreserve, PathCaseLower:
+	default:
+		return fmt.Errorf("rule set %q: unknown path_case %q", r.Name, r.PathCase)
+	}
+	switch r.TrailingSlash {
+	case "", TrailingSlashPreserve, TrailingSlashStrip, TrailingSlashAdd:
+	default:
+		return fmt.Errorf("rule set %q: unknown trailing_slash %q", r.Name, r.TrailingSlash)
+	}
+	switch r.Fragment {
+	case "", FragmentPreserve, FragmentStrip:
+	default:
+		return fmt.Errorf("rule set %q: unknown fragment %q", r.Name, r.Fragment)
+	}
+	if err := r.Query.validate(); err != nil {
+		return fmt.Errorf("rule set %q: %w", r.Name, err)
+	}
+	for host, query := range r.HostQuery {
+		if err := query.validate(); err != nil {
+			return fmt.Errorf("rule set %q, host %q: %w", r.Name, host, err)
+		}
+	}
+	if r.Locale != nil {
+		if err := r.Locale.validate(); err != nil {
+			return fmt.Errorf("rule set %q: %w", r.Name, err)
+		}
+	}
+	for _, rewrite := range r.PrefixRewrites {
+		if !strings.HasPrefix(rewrite.From, "/") || !strings.HasPrefix(rewrite.To, "/") {
+			return fmt.Errorf("rule set %q: prefix rewrites must start with a slash", r.Name)
+		}
+	}
+	return nil
+}
+
+// URLRuleEngine holds the rule sets used by ProcessURL. It is safe for
+// concurrent use and can be reloaded from its rules file at any time.
+type URLRuleEngine struct {
+	mu       sync.RWMutex
+	path     string
+	ruleSets map[string]RuleSet
+	modTime  time.Time
+	loadedAt time.Time
+}
+
+// URLRules is the rule engine used by the URL processing endpoints.
+var URLRules = NewURLRuleEngine("")
+
+// NewURLRuleEngine returns an engine serving the default rule sets until it is
+// loaded from path. An empty path keeps the defaults.
+func NewURLRuleEngine(path string) *URLRuleEngine {
+	return &URLRuleEngine{path: path, ruleSets: DefaultRuleSets(), loadedAt: time.Now()}
+}
+
+// SetPath changes the rules file the engine reloads from.
+func (e *URLRuleEngine) SetPath(path string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.path = path
+}
+
+// Reload reads the rules file again. On error the current rule sets stay in
+// place. Without a rules file the engine goes back to the default rule sets.
+func (e *URLRuleEngine) Reload() error {
+	e.mu.RLock()
+	path := e.path
+	e.mu.RUnlock()
+
+	if path == "" {
+		e.replace(DefaultRuleSets(), time.Time{})
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("reading url rules: %w", err)
+	}
+	ruleSets, err := LoadRuleSets(path)
+	if err != nil {
+		return err
+	}
+	e.replace(ruleSets, info.ModTime())
+	return nil
+}
+
+// ReloadIfChanged reloads the rules file when its modification time changed
+// since the last load.
+func (e *URLRuleEngine) ReloadIfChanged() error {
+	e.mu.RLock()
+	path, modTime := e.path, e.modTime
+	e.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("reading url rules: %w", err)
+	}
+	if info.ModTime().Equal(modTime) {
+		return nil
+	}
+	return e.Reload()
+}
+
+// Watch reloads the rules file whenever it changes, checking every interval.
+// It blocks, so it is meant to be started in its own goroutine.
+func (e *URLRuleEngine) Watch(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := e.ReloadIfChanged(); err != nil {
+			config.Log.WithError(err).Error("Error reloading url rules")
+		}
+	}
+}
+
+// RuleSet returns the named rule set.
+func (e *URLRuleEngine) RuleSet(name string) (RuleSet, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	ruleSet, ok := e.ruleSets[name]
+	return ruleSet, ok
+}
+
+// RuleSets returns every rule set sorted by name.
+func (e *URLRuleEngine) RuleSets() []RuleSet {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	ruleSets := make([]RuleSet, 0, len(e.ruleSets))
+	for _, ruleSet := range e.ruleSets {
+		ruleSets = append(ruleSets, ruleSet)
+	}
+	sort.Slice(ruleSets, func(i, j int) bool { return ruleSets[i].Name < ruleSets[j].Name })
+	return ruleSets
+}
+
+// LoadedAt returns when the rule sets were last replaced.
+func (e *URLRuleEngine) LoadedAt() time.Time {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.loadedAt
+}
+
+func (e *URLRuleEngine) replace(ruleSets map[string]RuleSet, modTime time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ruleSets = ruleSets
+	e.modTime = modTime
+	e.loadedAt = time.Now()
+}
+
+// LoadRuleSets reads rule sets from a JSON or YAML file, chosen by extension.
+func LoadRuleSets(path string) (map[string]RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading url rules: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("url rules file %s must be .json, .yaml or .yml", path)
+	}
+	ruleSets, err := parseRuleSets(data, ext == ".json")
+	if err != nil {
+		return nil, fmt.Errorf("url rules file %s: %w", path, err)
+	}
+	return ruleSets, nil
+}
+
+// parseRuleSets decodes and validates JSON or YAML rule sets. Unknown keys
+// are rejected, so a misspelt rule fails to load instead of being ignored.
+// Hosts are lowercased to match the hosts of processed URLs.
+func parseRuleSets(data []byte, jsonFormat bool) (map[string]RuleSet, error) {
+	var file urlRulesFile
+	var err error
+	if jsonFormat {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing url rules: %w", err)
+	}
+	if len(file.RuleSets) == 0 {
+		return nil, errors.New("no rule sets defined")
+	}
+
+	ruleSets := make(map[string]RuleSet, len(file.RuleSets))
+	for name, ruleSet := range file.RuleSets {
+		ruleSet.Name = name
+		ruleSet.lowercaseHosts()
+		if err := ruleSet.validate(); err != nil {
+			return nil, err
+		}
+		ruleSets[name] = ruleSet
+	}
+	return ruleSets, nil
+}
@@ -1,35 +1,27 @@
 package services
 
-import (
-	"net/url"
-	"strings"
-)
-
-func ProcessURL(originalURL string, operation string) (string, error) {
-	parsedURL, err := url.Parse(originalURL)
+// ProcessURL rewrites a URL with the named operations, applied in order. It
+// fails with ErrUnknownURLOperation before parsing the URL when an operation
+// is not registered, and with a URLValidationError when the URL does not pass
+// DefaultURLPolicy.
+func ProcessURL(originalURL string, operations ...string) (string, error) {
+	resolved, err := URLOperations.Resolve(operations)
 	if err != nil {
 		return "", err
 	}
+	return applyURLOperations(originalURL, resolved)
+}
 
-	switch operation {
-	case "canonical":
-		parsedURL.RawQuery = ""
-		parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
-		return parsedURL.String(), nil
-
-	case "redirection":
-		parsedURL.Host = "www.byfood.com"
-		parsedURL.Path = strings.ToLower(parsedURL.Path)
-		return parsedURL.String(), nil
-
-	case "all":
-		parsedURL.Host = "www.byfood.com"
-		parsedURL.Path = strings.ToLower(parsedURL.Path)
-		parsedURL.RawQuery = ""
-		parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
-		return parsedURL.String(), nil
+func applyURLOperations(originalURL string, operations []URLOperation) (string, error) {
+	parsedURL, err := DefaultURLPolicy().ValidateURL(originalURL)
+	if err != nil {
+		return "", err
+	}
 
-	default:
-		return "", nil
+	for _, operation := range operations {
+		if err := operation.Apply(parsedURL); err != nil {
+			return "", err
+		}
 	}
+	return parsedURL.String(), nil
 }
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	URLStatsBucketHour = "hour"
+	URLStatsBucketDay  = "day"
+	URLStatsBucketWeek = "week"
+)
+
+var (
+	ErrInvalidURLStatsBucket = errors.New("url stats bucket must be hour, day or week")
+	ErrInvalidURLStatsRange  = errors.New("url stats range must end after it starts")
+)
+
+// URLStatsQuery selects the recorded requests made in [From, To), counted per
+// Bucket, with at most Limit entries in every top list.
+type URLStatsQuery struct {
+	From   time.Time
+	To     time.Time
+	Bucket string
+	Limit  int
+}
+
+// URLStats summarizes the requests made to /api/process_url.
+type URLStats struct {
+	From    time.Time `json:"from" example:"2024-05-01T00:00:00Z"`
+	To      time.Time `json:"to" example:"2024-05-08T00:00:00Z"`
+	Bucket  string    `json:"bucket" example:"day"`
+	Total   int64     `json:"total" example:"120"`
+	Changed int64     `json:"changed" example:"90"`
+	// AlreadyCanonicalPercent is the share of requests whose URL came back
+	// unchanged, from 0 to 100.
+	AlreadyCanonicalPercent float64          `json:"already_canonical_percent" example:"25"`
+	TopHosts                []URLStatsCount  `json:"top_hosts"`
+	TopOperations           []URLStatsCount  `json:"top_operations"`
+	TopTransformations      []URLStatsCount  `json:"top_transformations"`
+	Buckets                 []URLStatsBucket `json:"buckets"`
+}
+
+type URLStatsCount struct {
+	Value string `json:"value" example:"www.byfood.com"`
+	Count int64  `json:"count" example:"42"`
+}
+
+// URLStatsBucket counts the requests of one hour, day or week. Buckets
+// without requests are left out.
+type URLStatsBucket struct {
+	Start   time.Time `json:"start" example:"2024-05-01T00:00:00Z"`
+	Count   int64     `json:"count" example:"17"`
+	Changed int64     `json:"changed" example:"12"`
+}
+
+// NewURLRecord describes a processed URL for the statistics. The host is the
+// one of the processed URL, and every step of explanation becomes part of the
+// transformation, named operation.rule for the rules of a rule set.
+func NewURLRecord(inputURL string, operations []string, explanation URLExplanation) models.URLRecord {
+	record := models.URLRecord{
+		InputURL:  inputURL,
+		OutputURL: explanation.ProcessedURL,
+		Operation: strings.Join(operations, ","),
+		Changed:   !explanation.AlreadyCanonical,
+	}
+	if parsed, err := url.Parse(explanation.ProcessedURL); err == nil {
+		record.Host = strings.ToLower(parsed.Hostname())
+	}
+
+	rules := make([]string, len(explanation.Steps))
+	for i, step := range explanation.Steps {
+		rules[i] = step.Operation
+		if step.Rule != step.Operation {
+			rules[i] += "." + step.Rule
+		}
+	}
+	record.Transformation = strings.Join(rules, ",")
+	return record
+}
+
+// URLRecords records the requests to /api/process_url. It is nil, and nothing
+// is recorded, unless the URL statistics are enabled.
+var URLRecords *URLRecorder
+
+const (
+	// urlRecordQueueSize is how many records may wait to be stored; records
+	// arriving while the queue is full are dropped.
+	urlRecordQueueSize = 1024
+	// urlRecordBatchSize is how many records are stored with one insert.
+	urlRecordBatchSize = 100
+	// urlRecordFlushInterval is how long a record may wait for its batch to
+	// fill up.
+	urlRecordFlushInterval = time.Second
+)
+
+// URLRecorder stores processed URLs for the statistics in the background, in
+// batches, so that recording them does not slow requests down.
+type URLRecorder struct {
+	// mu guards closed, so that nothing is sent on records once Stop closed it.
+	mu      sync.RWMutex
+	closed  bool
+	records chan models.URLRecord
+	stopped chan struct{}
+}
+
+// StartURLRecorder starts storing the records it is given in db.
+func StartURLRecorder(db *gorm.DB) *URLRecorder {
+	recorder := &URLRecorder{
+		records: make(chan models.URLRecord, urlRecordQueueSize),
+		stopped: make(chan struct{}),
+	}
+	go recorder.run(db)
+	return recorder
+}
+
+// Record queues record to be stored. It never blocks: when the queue is full
+// or the recorder has been stopped the record is dropped.
+func (r *URLRecorder) Record(record models.URLRecord) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.records <- record:
+	default:
+		config.Log.Warn("Url record queue is full, dropping record")
+	}
+}
+
+// Stop stores the queued records and stops the recorder. Records arriving
+// afterwards, such as from requests still running when the server shutdown
+// timed out, are dropped.
+func (r *URLRecorder) Stop() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.records)
+	}
+	r.mu.Unlock()
+	<-r.stopped
+}
+
+// run stores the queued records until the recorder is stopped.
+func (r *URLRecorder) run(db *gorm.DB) {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(urlRecordFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.URLRecord, 0, urlRecordBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := db.Create(&batch).Error; err != nil {
+			config.Log.WithError(err).WithField("records", len(batch)).Error("Error recording processed urls")
+		}
+		batch = make([]models.URLRecord, 0, urlRecordBatchSize)
+	}
+
+	for {
+		select {
+		case record, ok := <-r.records:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) == urlRecordBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// GetURLStats summarizes the requests recorded in the range of query.
+func GetURLStats(db *gorm.DB, query URLStatsQuery) (URLStats, error) {
+	switch query.Bucket {
+	case URLStatsBucketHour, URLStatsBucketDay, URLStatsBucketWeek:
+	default:
+		return URLStats{}, ErrInvalidURLStatsBucket
+	}
+	if !query.To.After(query.From) {
+		return URLStats{}, ErrInvalidURLStatsRange
+	}
+
+	records := func() *gorm.DB {
+		return db.Model(&models.URLRecord{}).Where("created_at >= ? AND created_at < ?", query.From, query.To)
+	}
+
+	stats := URLStats{From: query.From, To: query.To, Bucket: query.Bucket}
+	var totals struct {
+		Total   int64
+		Changed int64
+	}
+	if err := records().Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE changed) AS changed").Scan(&totals).Error; err != nil {
+		return URLStats{}, err
+	}
+	stats.Total, stats.Changed = totals.Total, totals.Changed
+	if stats.Total > 0 {
+		stats.AlreadyCanonicalPercent = math.Round(float64(stats.Total-stats.Changed)/float64(stats.Total)*10000) / 100
+	}
+
+	top := func(column string, scope *gorm.DB) ([]URLStatsCount, error) {
+		counts := []URLStatsCount{}
+		err := scope.Select(column + " AS value, COUNT(*) AS count").
+			Group(column).Order("count DESC, value").Limit(query.Limit).
+			Scan(&counts).Error
+		return counts, err
+	}
+	var err error
+	if stats.TopHosts, err = top("host", records().Where("host <> ''")); err != nil {
+		return URLStats{}, err
+	}
+	if stats.TopOperations, err = top("operation", records()); err != nil {
+		return URLStats{}, err
+	}
+	if stats.TopTransformations, err = top("transformation", records().Where("changed")); err != nil {
+		return URLStats{}, err
+	}
+
+	// The bucket is one of the constants above, so it can be written into
+	// the query.
+	start := fmt.Sprintf("date_trunc('%s', created_at)", query.Bucket)
+	stats.Buckets = []URLStatsBucket{}
+	err = records().
+		Select(start + " AS start, COUNT(*) AS count, COUNT(*) FILTER (WHERE changed) AS changed").
+		Group("start").Order("start").
+		Scan(&stats.Buckets).Error
+	if err != nil {
+		return URLStats{}, err
+	}
+	return stats, nil
+}
+
+// PruneURLRecords deletes the requests recorded before before and returns how
+// many there were.
+func PruneURLRecords(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("created_at < ?", before).Delete(&models.URLRecord{})
+	return result.RowsAffected, result.Error
+}
+
+// RunURLRecordPruning deletes recorded requests older than retention right
+// away and then every interval. It blocks, so it is meant to be started in
+// its own goroutine.
+func RunURLRecordPruning(db *gorm.DB, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		return
+	}
+
+	pruneURLRecords(db, time.Now().Add(-retention))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		pruneURLRecords(db, now.Add(-retention))
+	}
+}
+
+// pruneURLRecords deletes the requests recorded before before, logging the
+// outcome.
+func pruneURLRecords(db *gorm.DB, before time.Time) {
+	pruned, err := PruneURLRecords(db, before)
+	if err != nil {
+		config.Log.WithError(err).Error("Error pruning url records")
+		return
+	}
+	if pruned > 0 {
+		config.Log.WithField("pruned", pruned).Info("Pruned url records")
+	}
+}
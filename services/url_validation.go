@@ -0,0 +1,223 @@
+package services
+
+import (
+	"byfood-test-backend/config"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidURL = errors.New("invalid url")
+
+// URLValidationError explains why a URL was rejected. It matches
+// ErrInvalidURL with errors.Is.
+type URLValidationError struct {
+	Reason string
+}
+
+func (e *URLValidationError) Error() string {
+	return e.Reason
+}
+
+func (e *URLValidationError) Is(target error) bool {
+	return target == ErrInvalidURL
+}
+
+func invalidURL(format string, args ...interface{}) error {
+	return &URLValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// URLPolicy decides which URLs the API accepts. It keeps URLs that could make
+// the server reach internal services, such as http://169.254.169.254/ or
+// file:///etc/passwd, out of processing and link fetching.
+//
+// Host patterns match a host exactly, or with a "*." prefix the domain and
+// every subdomain. An empty AllowedHosts allows every host that is not
+// denied. MaxLength zero means no limit.
+type URLPolicy struct {
+	Schemes       []string
+	AllowedHosts  []string
+	DeniedHosts   []string
+	AllowPrivate  bool
+	AllowUserinfo bool
+	MaxLength     int
+}
+
+// DefaultURLPolicy returns the policy from the configuration.
+func DefaultURLPolicy() URLPolicy {
+	return URLPolicy{
+		Schemes:      config.URLAllowedSchemes(),
+		AllowedHosts: config.URLAllowedHosts(),
+		DeniedHosts:  config.URLDeniedHosts(),
+		AllowPrivate: config.URLAllowPrivateHosts(),
+		MaxLength:    config.URLMaxLength(),
+	}
+}
+
+// ValidateURL parses rawURL and checks it against the policy. Failures are
+// URLValidationErrors carrying the reason.
+func (p URLPolicy) ValidateURL(rawURL string) (*url.URL, error) {
+	if p.MaxLength > 0 && len(rawURL) > p.MaxLength {
+		return nil, invalidURL("url is longer than %d characters", p.MaxLength)
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, invalidURL("url cannot be parsed: %v", unwrapURLError(err))
+	}
+	if parsedURL.Scheme == "" {
+		return nil, invalidURL("url must be absolute")
+	}
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if !containsFold(p.Schemes, scheme) {
+		return nil, invalidURL("scheme %q is not allowed", scheme)
+	}
+	if parsedURL.Opaque != "" || parsedURL.Host == "" {
+		return nil, invalidURL("url must have a host")
+	}
+	if parsedURL.User != nil && !p.AllowUserinfo {
+		return nil, invalidURL("url must not contain user information")
+	}
+
+	host, err := normalizeHost(strings.TrimSuffix(parsedURL.Hostname(), "."))
+	if err != nil {
+		return nil, invalidURL("host %q is not valid", parsedURL.Hostname())
+	}
+	if host == "" {
+		return nil, invalidURL("url must have a host")
+	}
+	if matchesHost(p.DeniedHosts, host) {
+		return nil, invalidURL("host %q is denied", host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchesHost(p.AllowedHosts, host) {
+		return nil, invalidURL("host %q is not allowed", host)
+	}
+	if !p.AllowPrivate {
+		if reason := privateHost(host); reason != "" {
+			return nil, invalidURL("host %q is %s", host, reason)
+		}
+	}
+	return parsedURL, nil
+}
+
+// privateHost describes why host points inside the network, or returns ""
+// when it does not. Hosts are checked as written; names are not resolved.
+func privateHost(host string) string {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "a loopback address"
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = parseLooseIPv4(host)
+	}
+	switch {
+	case ip == nil:
+		return ""
+	case ip.IsLoopback():
+		return "a loopback address"
+	case ip.IsPrivate():
+		return "a private address"
+	case ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast():
+		return "a link-local address"
+	case ip.IsUnspecified():
+		return "an unspecified address"
+	case ip.IsMulticast(), ip.IsInterfaceLocalMulticast():
+		return "a multicast address"
+	}
+	for _, network := range internalNetworks {
+		if network.ipNet.Contains(ip) {
+			return network.reason
+		}
+	}
+	return ""
+}
+
+// internalNetworks are ranges the net.IP checks above do not cover but that
+// still reach internal services, such as cloud metadata endpoints.
+var internalNetworks = []struct {
+	ipNet  *net.IPNet
+	reason string
+}{
+	{mustParseCIDR("0.0.0.0/8"), "a this-network address"},
+	{mustParseCIDR("100.64.0.0/10"), "a shared (CGNAT) address"},
+	{mustParseCIDR("198.18.0.0/15"), "a benchmarking address"},
+	{mustParseCIDR("64:ff9b::/96"), "a NAT64 address"},
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// parseLooseIPv4 parses the IPv4 spellings that inet_aton and browsers
+// accept besides dotted decimal, such as 2130706433, 0x7f.1 or 0177.0.0.1,
+// which would otherwise slip past net.ParseIP.
+func parseLooseIPv4(host string) net.IP {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 0, 32)
+		if err != nil {
+			return nil
+		}
+		values[i] = value
+	}
+
+	var address uint64
+	for i, value := range values[:len(values)-1] {
+		if value > 0xff {
+			return nil
+		}
+		address |= value << (24 - 8*i)
+	}
+	last := values[len(values)-1]
+	if last >= 1<<(32-8*(len(values)-1)) {
+		return nil
+	}
+	address |= last
+	return net.IPv4(byte(address>>24), byte(address>>16), byte(address>>8), byte(address))
+}
+
+func matchesHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if pattern == host {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrapURLError drops the operation and URL that url.Parse repeats in its
+// errors, which would otherwise echo the whole input back.
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
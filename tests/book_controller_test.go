@@ -4,11 +4,13 @@ import (
 	"byfood-test-backend/config"
 	"byfood-test-backend/controllers"
 	"byfood-test-backend/models"
+	"byfood-test-backend/services"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +21,7 @@ func setupBookRouter() *gin.Engine {
 	router := gin.Default()
 	router.POST("/books", controllers.AddBook)
 	router.GET("/books", controllers.GetBooks)
+	router.GET("/books/by-slug/:slug", controllers.GetBookBySlug)
 	router.GET("/books/:id", controllers.GetBookByID)
 	router.PUT("/books/:id", controllers.UpdateBookByID)
 	router.DELETE("/books/:id", controllers.DeleteBookByID)
@@ -26,6 +29,12 @@ func setupBookRouter() *gin.Engine {
 }
 
 func initializeTestData() {
+	config.DB.Exec("DELETE FROM holds")
+	config.DB.Exec("DELETE FROM loans")
+	config.DB.Exec("DELETE FROM transfers")
+	config.DB.Exec("DELETE FROM copies")
+	config.DB.Exec("DELETE FROM branches")
+	config.DB.Exec("DELETE FROM book_slugs")
 	config.DB.Exec("DELETE FROM books")
 	config.DB.Exec("ALTER SEQUENCE books_id_seq RESTART WITH 1")
 
@@ -38,6 +47,7 @@ func initializeTestData() {
 	for _, book := range books {
 		config.DB.Create(&book)
 	}
+	services.BackfillBookSlugs(config.DB)
 }
 
 func TestGetBooks(t *testing.T) {
@@ -187,6 +197,110 @@ func TestDeleteBook(t *testing.T) {
 	assert.Equal(t, "Book successfully deleted", responseBody["message"])
 }
 
+func TestAddBookAssignsUniqueSlug(t *testing.T) {
+	initializeTestData()
+	router := setupBookRouter()
+
+	for _, expected := range []string{"war-and-peace-lev-tolstoy", "war-and-peace-lev-tolstoy-2"} {
+		requestJSON, _ := json.Marshal(models.Book{Title: "War & Peace", Author: "Лев Толстой", Year: 1869})
+		req, _ := http.NewRequest("POST", "/books", bytes.NewBuffer(requestJSON))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		var responseBody services.BookResponse
+		err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, responseBody.Data.Slug)
+		assert.Equal(t, "https://www.byfood.com/books/"+expected, responseBody.Data.URL)
+	}
+}
+
+func TestConcurrentAddBookGetsDistinctSlugs(t *testing.T) {
+	initializeTestData()
+	router := setupBookRouter()
+
+	var wg sync.WaitGroup
+	codes := make([]int, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			requestJSON, _ := json.Marshal(models.Book{Title: "Ramen", Author: "Ivan Orkin", Year: 2013})
+			req, _ := http.NewRequest("POST", "/books", bytes.NewBuffer(requestJSON))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			codes[i] = resp.Code
+		}(i)
+	}
+	wg.Wait()
+
+	for _, code := range codes {
+		assert.Equal(t, http.StatusCreated, code)
+	}
+	var slugs []string
+	config.DB.Model(&models.Book{}).Where("title = ?", "Ramen").Pluck("slug", &slugs)
+	assert.Len(t, slugs, len(codes))
+}
+
+func TestGetBookBySlug(t *testing.T) {
+	initializeTestData()
+	router := setupBookRouter()
+
+	req, _ := http.NewRequest("GET", "/books/by-slug/book-two-author-two", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var book models.Book
+	err := json.Unmarshal(resp.Body.Bytes(), &book)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), book.ID)
+	assert.Equal(t, "https://www.byfood.com/books/book-two-author-two", book.URL)
+
+	req, _ = http.NewRequest("GET", "/books/by-slug/no-such-book", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestOldBookSlugRedirects(t *testing.T) {
+	initializeTestData()
+	router := setupBookRouter()
+
+	requestJSON, _ := json.Marshal(models.Book{Title: "Book Uno"})
+	req, _ := http.NewRequest("PUT", "/books/1", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var updated services.BookResponse
+	json.Unmarshal(resp.Body.Bytes(), &updated)
+	assert.Equal(t, "book-uno-author-one", updated.Data.Slug)
+
+	req, _ = http.NewRequest("GET", "/books/by-slug/book-one-author-one?lang=ja", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusMovedPermanently, resp.Code)
+	assert.Equal(t, "/books/by-slug/book-uno-author-one?lang=ja", resp.Header().Get("Location"))
+
+	requestJSON, _ = json.Marshal(models.Book{Title: "Book One"})
+	req, _ = http.NewRequest("PUT", "/books/1", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	json.Unmarshal(resp.Body.Bytes(), &updated)
+	assert.Equal(t, "book-one-author-one", updated.Data.Slug)
+
+	req, _ = http.NewRequest("GET", "/books/by-slug/book-uno-author-one", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusMovedPermanently, resp.Code)
+	assert.Equal(t, "/books/by-slug/book-one-author-one", resp.Header().Get("Location"))
+}
+
 func TestMain(m *testing.M) {
 	os.Setenv("APP_ENV", "test")
 	config.LoadEnvVariables()
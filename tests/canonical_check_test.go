@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCanonicalTags(t *testing.T) {
+	page, _ := url.Parse("https://www.byfood.com/ja/tokyo?page=2")
+	document := `<!DOCTYPE html>
+<html><head>
+  <base href="https://www.byfood.com/">
+  <LINK REL="Canonical" href="/tokyo">
+  <link rel="alternate" hreflang="ja" href="ja/tokyo">
+  <link rel="alternate stylesheet" href="/print.css">
+  <link rel="alternate" hreflang="x-default" href="https://www.byfood.com/tokyo"/>
+  <meta property="og:url" content="https://www.byfood.com/tokyo">
+</head><body><p>Tokyo</p></body></html>`
+
+	tags, err := services.ExtractCanonicalTags(strings.NewReader(document), page)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://www.byfood.com/tokyo"}, tags.Canonicals)
+	assert.Equal(t, "https://www.byfood.com/tokyo", tags.OGURL)
+	assert.Equal(t, []services.HreflangAlternate{
+		{Hreflang: "ja", URL: "https://www.byfood.com/ja/tokyo"},
+		{Hreflang: "x-default", URL: "https://www.byfood.com/tokyo"},
+	}, tags.Hreflang)
+}
+
+func TestCheckCanonicalTags(t *testing.T) {
+	documents := []services.HTMLDocument{
+		{
+			URL:  "https://www.byfood.com/tokyo?utm_source=x",
+			HTML: `<link rel="canonical" href="https://www.byfood.com/tokyo"><meta property="og:url" content="https://www.byfood.com/tokyo">`,
+		},
+		{
+			URL:  "https://www.byfood.com/tokyo",
+			HTML: `<link rel="canonical" href="https://www.byfood.com/osaka"><meta property="og:url" content="https://WWW.byfood.com/tokyo/">`,
+		},
+		{
+			URL:  "https://www.byfood.com/kyoto",
+			HTML: `<title>Kyoto</title><link rel="alternate" hreflang="ja" href="https://www.byfood.com/ja/kyoto"><link rel="alternate" hreflang="ja" href="https://www.byfood.com/ja/kyoto/">`,
+		},
+		{URL: "file:///etc/passwd", HTML: ""},
+	}
+
+	checks, err := services.CheckCanonicalTags(documents, []string{"normalize", "canonical"}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, checks, 4)
+
+	assert.True(t, checks[0].Consistent)
+	assert.Equal(t, "https://www.byfood.com/tokyo", checks[0].ComputedCanonical)
+
+	assert.False(t, checks[1].Consistent)
+	assert.Equal(t, []services.CanonicalIssue{
+		{Issue: services.CanonicalIssueMismatch, URL: "https://www.byfood.com/osaka", Expected: "https://www.byfood.com/tokyo"},
+		{Issue: services.CanonicalIssueNotNormalized, URL: "https://WWW.byfood.com/tokyo/", Expected: "https://www.byfood.com/tokyo"},
+	}, checks[1].Issues)
+
+	issues := []string{}
+	for _, issue := range checks[2].Issues {
+		issues = append(issues, issue.Issue)
+	}
+	assert.Equal(t, []string{
+		services.CanonicalIssueMissing,
+		services.CanonicalIssueHreflangDuplicate,
+		services.CanonicalIssueHreflangNotCanonical,
+		services.CanonicalIssueHreflangMissingSelf,
+	}, issues)
+
+	assert.NotEmpty(t, checks[3].Error)
+	assert.False(t, checks[3].Consistent)
+}
+
+func TestCheckCanonicalTagsMultipleCanonicals(t *testing.T) {
+	checks, err := services.CheckCanonicalTags([]services.HTMLDocument{{
+		URL:  "https://www.byfood.com/tokyo",
+		HTML: `<link rel="canonical" href="/tokyo"><link rel="canonical" href="/osaka"><link rel="canonical" href="/tokyo">`,
+	}}, []string{"canonical"}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, checks[0].Issues, 2)
+	assert.Equal(t, services.CanonicalIssueMultiple, checks[0].Issues[0].Issue)
+}
+
+func TestCheckCanonicalTagsEndpoint(t *testing.T) {
+	router := setupRouter()
+
+	requestJSON, _ := json.Marshal(map[string]string{
+		"url":       "https://byfood.com/Tokyo/",
+		"html":      `<html><head><link rel="canonical" href="https://www.byfood.com/tokyo"></head></html>`,
+		"operation": "all",
+	})
+	req, _ := http.NewRequest("POST", "/process_url/canonical-check", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var response services.CanonicalCheckResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+	assert.True(t, response.Data[0].Consistent)
+
+	requestJSON, _ = json.Marshal(map[string]string{"operation": "all"})
+	req, _ = http.NewRequest("POST", "/process_url/canonical-check", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
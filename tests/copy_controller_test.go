@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCopyRouter() *gin.Engine {
+	router := gin.Default()
+	router.GET("/books", controllers.GetBooks)
+	router.GET("/books/:id", controllers.GetBookByID)
+	router.POST("/books/:id/checkout", controllers.CheckoutBook)
+	router.GET("/books/:id/copies", controllers.GetBookCopies)
+	router.POST("/books/:id/copies", controllers.AddBookCopy)
+	router.PUT("/books/:id/copies/:copyId", controllers.UpdateBookCopy)
+	router.DELETE("/books/:id/copies/:copyId", controllers.DeleteBookCopy)
+	return router
+}
+
+func TestAddBookCopy(t *testing.T) {
+	initializeLoanTestData()
+	router := setupCopyRouter()
+
+	requestJSON, _ := json.Marshal(map[string]string{"barcode": "BF-0003", "location": "Shelf B1"})
+	req, _ := http.NewRequest("POST", "/books/1/copies", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/books/1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var book models.Book
+	err := json.Unmarshal(resp.Body.Bytes(), &book)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, book.TotalCopies)
+	assert.Equal(t, 2, book.AvailableCopies)
+}
+
+func TestAddBookCopyEmptyBarcode(t *testing.T) {
+	initializeLoanTestData()
+	router := setupCopyRouter()
+
+	requestJSON, _ := json.Marshal(map[string]string{"location": "Shelf B1"})
+	req, _ := http.NewRequest("POST", "/books/1/copies", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var responseBody map[string]interface{}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Equal(t, "Barcode cannot be empty", responseBody["error"])
+}
+
+func TestGetBooksAvailableOnly(t *testing.T) {
+	initializeLoanTestData()
+	router := setupCopyRouter()
+	checkout(router, "1", 1)
+
+	req, _ := http.NewRequest("GET", "/books?available=true", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody struct {
+		Data []models.Book `json:"data"`
+	}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody.Data, 1)
+	assert.Equal(t, "Book Two", responseBody.Data[0].Title)
+	assert.Equal(t, 1, responseBody.Data[0].AvailableCopies)
+}
+
+func TestUpdateCopyOnLoanStatus(t *testing.T) {
+	initializeLoanTestData()
+	router := setupCopyRouter()
+	checkout(router, "1", 1)
+
+	requestJSON, _ := json.Marshal(map[string]string{"status": models.CopyStatusMaintenance})
+	req, _ := http.NewRequest("PUT", "/books/1/copies/1", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var bookCopy models.Copy
+	config.DB.First(&bookCopy, 1)
+	assert.Equal(t, models.CopyStatusOnLoan, bookCopy.Status)
+}
+
+func TestDeleteBookCopy(t *testing.T) {
+	initializeLoanTestData()
+	router := setupCopyRouter()
+
+	req, _ := http.NewRequest("DELETE", "/books/2/copies/2", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, http.StatusConflict, checkout(router, "2", 1).Code)
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFineRouter() *gin.Engine {
+	router := gin.Default()
+	router.POST("/books/:id/checkout", controllers.CheckoutBook)
+	router.POST("/loans/:id/return", controllers.ReturnLoan)
+	router.GET("/users/:id/fines", controllers.GetUserFines)
+	router.POST("/users/:id/fines/payments", controllers.AddUserFineEntry)
+	return router
+}
+
+func TestCalculateFine(t *testing.T) {
+	due := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	loan := models.Loan{DueAt: due}
+	rules := config.FineRules{DailyRate: 25, GraceDays: 1, MaxAmount: 100}
+
+	tests := []struct {
+		name       string
+		returnedAt time.Time
+		expected   int64
+	}{
+		{"on time", due.Add(-time.Hour), 0},
+		{"within grace period", due.Add(20 * time.Hour), 0},
+		{"partial day rounds up", due.Add(25 * time.Hour), 25},
+		{"three days late", due.Add(72 * time.Hour), 50},
+		{"capped", due.Add(30 * 24 * time.Hour), 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.CalculateFine(loan, rules, tt.returnedAt))
+		})
+	}
+}
+
+func TestFineRulesTierOverride(t *testing.T) {
+	os.Setenv("FINE_DAILY_RATE", "25")
+	os.Setenv("FINE_TIER_OVERRIDES", `{"premium": {"daily_rate": 10}}`)
+	defer os.Unsetenv("FINE_TIER_OVERRIDES")
+
+	assert.Equal(t, int64(25), config.FineRulesFor(models.MembershipTierStandard).DailyRate)
+	premium := config.FineRulesFor("premium")
+	assert.Equal(t, int64(10), premium.DailyRate)
+	assert.Equal(t, config.FineRulesFor(models.MembershipTierStandard).GraceDays, premium.GraceDays)
+}
+
+func TestValidMembershipTier(t *testing.T) {
+	os.Setenv("FINE_TIER_OVERRIDES", `{"premium": {"daily_rate": 10}}`)
+	defer os.Unsetenv("FINE_TIER_OVERRIDES")
+
+	assert.True(t, config.ValidMembershipTier(models.MembershipTierStandard))
+	assert.True(t, config.ValidMembershipTier("premium"))
+	assert.False(t, config.ValidMembershipTier("gold"))
+}
+
+func TestReturnOverdueLoanChargesFine(t *testing.T) {
+	initializeLoanTestData()
+	os.Setenv("FINE_DAILY_RATE", "25")
+	os.Setenv("FINE_GRACE_DAYS", "0")
+	os.Setenv("FINE_MAX_AMOUNT", "0")
+	os.Setenv("FINE_BLOCK_THRESHOLD", "100")
+	router := setupFineRouter()
+
+	checkout(router, "1", 1)
+	config.DB.Model(&models.Loan{}).Where("id = ?", 1).Update("due_at", time.Now().Add(-10*24*time.Hour))
+
+	req, _ := http.NewRequest("POST", "/loans/1/return", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "/users/1/fines", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody services.FineSummaryResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), responseBody.Data.Balance)
+	assert.True(t, responseBody.Data.CheckoutBlocked)
+
+	assert.Equal(t, http.StatusForbidden, checkout(router, "2", 1).Code)
+
+	requestJSON, _ := json.Marshal(controllers.FineEntryRequest{Kind: models.FineEntryPayment, Amount: 200})
+	req, _ = http.NewRequest("POST", "/users/1/fines/payments", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	assert.Equal(t, http.StatusCreated, checkout(router, "2", 1).Code)
+}
+
+func TestFinePaymentExceedingBalance(t *testing.T) {
+	initializeLoanTestData()
+	router := setupFineRouter()
+
+	requestJSON, _ := json.Marshal(controllers.FineEntryRequest{Kind: models.FineEntryWaiver, Amount: 100})
+	req, _ := http.NewRequest("POST", "/users/1/fines/payments", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestFineEntryForAnotherUsersLoan(t *testing.T) {
+	initializeLoanTestData()
+	router := setupFineRouter()
+	checkout(router, "1", 1)
+
+	loanID := uint(1)
+	requestJSON, _ := json.Marshal(controllers.FineEntryRequest{LoanID: &loanID, Kind: models.FineEntryWaiver, Amount: 100})
+	req, _ := http.NewRequest("POST", "/users/2/fines/payments", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHoldRouter() *gin.Engine {
+	router := gin.Default()
+	router.POST("/books/:id/checkout", controllers.CheckoutBook)
+	router.POST("/books/:id/holds", controllers.PlaceHold)
+	router.POST("/loans/:id/return", controllers.ReturnLoan)
+	router.GET("/holds", controllers.GetHolds)
+	router.DELETE("/holds/:id", controllers.CancelHold)
+	return router
+}
+
+func placeHold(router *gin.Engine, bookID string, userID uint) *httptest.ResponseRecorder {
+	requestJSON, _ := json.Marshal(controllers.HoldRequest{UserID: userID})
+	req, _ := http.NewRequest("POST", "/books/"+bookID+"/holds", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPlaceHoldWhileCopyAvailable(t *testing.T) {
+	initializeLoanTestData()
+	router := setupHoldRouter()
+
+	resp := placeHold(router, "1", 1)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestHoldQueuePositions(t *testing.T) {
+	initializeLoanTestData()
+	config.DB.Create(&models.User{Name: "User Three", Email: "three@byfood.com"})
+	router := setupHoldRouter()
+	checkout(router, "1", 1)
+
+	assert.Equal(t, http.StatusCreated, placeHold(router, "1", 2).Code)
+	assert.Equal(t, http.StatusCreated, placeHold(router, "1", 3).Code)
+	assert.Equal(t, http.StatusConflict, placeHold(router, "1", 3).Code)
+
+	req, _ := http.NewRequest("GET", "/holds?user_id=3", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody struct {
+		Data []models.Hold `json:"data"`
+	}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody.Data, 1)
+	assert.Equal(t, 2, responseBody.Data[0].Position)
+}
+
+func TestReturnAssignsCopyToNextHold(t *testing.T) {
+	initializeLoanTestData()
+	router := setupHoldRouter()
+	checkout(router, "1", 1)
+	placeHold(router, "1", 2)
+
+	req, _ := http.NewRequest("POST", "/loans/1/return", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var hold models.Hold
+	config.DB.First(&hold, 1)
+	assert.Equal(t, models.HoldStatusReady, hold.Status)
+	assert.NotNil(t, hold.PickupDeadline)
+
+	assert.Equal(t, http.StatusConflict, checkout(router, "1", 1).Code)
+	assert.Equal(t, http.StatusCreated, checkout(router, "1", 2).Code)
+
+	config.DB.First(&hold, 1)
+	assert.Equal(t, models.HoldStatusFulfilled, hold.Status)
+}
+
+func TestCheckoutFulfilsWaitingHold(t *testing.T) {
+	initializeLoanTestData()
+	router := setupHoldRouter()
+	checkout(router, "1", 1)
+	placeHold(router, "1", 2)
+	config.DB.Create(&models.Copy{BookID: 1, Barcode: "BF-0003", Status: models.CopyStatusAvailable})
+
+	assert.Equal(t, http.StatusCreated, checkout(router, "1", 2).Code)
+
+	var hold models.Hold
+	config.DB.First(&hold, 1)
+	assert.Equal(t, models.HoldStatusFulfilled, hold.Status)
+	assert.Nil(t, hold.CopyID)
+}
+
+func TestExpiredHoldMovesToNextInLine(t *testing.T) {
+	initializeLoanTestData()
+	config.DB.Create(&models.User{Name: "User Three", Email: "three@byfood.com"})
+	router := setupHoldRouter()
+	checkout(router, "1", 1)
+	placeHold(router, "1", 2)
+	placeHold(router, "1", 3)
+
+	req, _ := http.NewRequest("POST", "/loans/1/return", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	expired, err := services.ExpireHolds(config.DB, time.Now().Add(30*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	var holds []models.Hold
+	config.DB.Order("id ASC").Find(&holds)
+	assert.Equal(t, models.HoldStatusExpired, holds[0].Status)
+	assert.Equal(t, models.HoldStatusReady, holds[1].Status)
+}
@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLinkChecker() *services.LinkChecker {
+	return &services.LinkChecker{
+		Concurrency:  4,
+		Timeout:      time.Second,
+		MaxRedirects: 3,
+		Policy:       services.URLPolicy{Schemes: []string{"http", "https"}, AllowPrivate: true},
+	}
+}
+
+func newLinkTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved-again", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved-again", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop-back", http.StatusFound)
+	})
+	mux.HandleFunc("/loop-back", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body>
+			<a href="/ok">ok</a>
+			<a href="/missing#top">missing</a>
+			<a href="/ok">again</a>
+			<a href="mailto:info@byfood.com">mail</a>
+		</body></html>`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestLinkCheckerStatuses(t *testing.T) {
+	server := newLinkTestServer()
+	defer server.Close()
+	checker := testLinkChecker()
+
+	result := checker.Check(context.Background(), server.URL+"/ok")
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.False(t, result.Broken)
+
+	result = checker.Check(context.Background(), server.URL+"/missing")
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	assert.True(t, result.Broken)
+
+	result = checker.Check(context.Background(), server.URL+"/moved")
+	assert.False(t, result.Broken)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, server.URL+"/ok", result.FinalURL)
+	assert.Equal(t, []models.LinkHop{
+		{URL: server.URL + "/moved", StatusCode: http.StatusMovedPermanently, Location: server.URL + "/moved-again"},
+		{URL: server.URL + "/moved-again", StatusCode: http.StatusFound, Location: server.URL + "/ok"},
+	}, result.Redirects)
+
+	result = checker.Check(context.Background(), server.URL+"/get-only")
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+}
+
+func TestLinkCheckerRedirectProblems(t *testing.T) {
+	server := newLinkTestServer()
+	defer server.Close()
+	checker := testLinkChecker()
+
+	result := checker.Check(context.Background(), server.URL+"/loop")
+	assert.True(t, result.Broken)
+	assert.Equal(t, services.ErrRedirectCycle.Error(), result.Error)
+
+	checker.MaxRedirects = 1
+	result = checker.Check(context.Background(), server.URL+"/moved")
+	assert.True(t, result.Broken)
+	assert.Equal(t, services.ErrTooManyRedirects.Error(), result.Error)
+}
+
+func TestLinkCheckerTimeout(t *testing.T) {
+	server := newLinkTestServer()
+	defer server.Close()
+	checker := testLinkChecker()
+	checker.Timeout = 50 * time.Millisecond
+
+	result := checker.Check(context.Background(), server.URL+"/slow")
+	assert.True(t, result.Broken)
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestLinkCheckerRejectsPrivateHosts(t *testing.T) {
+	server := newLinkTestServer()
+	defer server.Close()
+	checker := testLinkChecker()
+	checker.Policy.AllowPrivate = false
+
+	result := checker.Check(context.Background(), server.URL+"/ok")
+	assert.True(t, result.Broken)
+	assert.Contains(t, result.Error, "loopback")
+	assert.Zero(t, result.StatusCode)
+}
+
+func TestLinkCheckerHostPoliteness(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	checker := testLinkChecker()
+	checker.HostDelay = 50 * time.Millisecond
+	urls := []string{server.URL + "/a", server.URL + "/b", server.URL + "/c", server.URL + "/d"}
+
+	started := time.Now()
+	var mu sync.Mutex
+	checked := 0
+	checker.CheckAll(context.Background(), urls, func(i int, result models.LinkCheckResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, urls[i], result.URL)
+		checked++
+	})
+
+	assert.Equal(t, 4, checked)
+	assert.Equal(t, int32(1), maxInFlight)
+	assert.GreaterOrEqual(t, time.Since(started), 150*time.Millisecond)
+}
+
+func TestLinkCheckerBoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+	})
+
+	var urls []string
+	for i := 0; i < 4; i++ {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		urls = append(urls, server.URL+"/")
+	}
+
+	checker := testLinkChecker()
+	checker.Concurrency = 2
+	checker.CheckAll(context.Background(), urls, func(int, models.LinkCheckResult) {})
+
+	assert.Equal(t, int32(2), maxInFlight)
+}
+
+func TestLinkCheckerCheckPage(t *testing.T) {
+	server := newLinkTestServer()
+	defer server.Close()
+
+	result, links := testLinkChecker().CheckPage(context.Background(), server.URL+"/page")
+	assert.False(t, result.Broken)
+	assert.Equal(t, []string{server.URL + "/ok", server.URL + "/missing"}, links)
+}
+
+func TestRedirectTargetLinks(t *testing.T) {
+	links := services.RedirectTargetLinks([]models.RedirectRule{
+		{ID: 1, Target: "/internal", MatchType: models.RedirectMatchExact},
+		{ID: 2, Target: "https://partner.example/menu", MatchType: models.RedirectMatchExact},
+		{ID: 3, Target: "https://blog.byfood.com/posts/$1", MatchType: models.RedirectMatchRegex},
+	})
+	assert.Equal(t, []services.LinkTarget{{URL: "https://partner.example/menu", Source: "redirect:2"}}, links)
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLinkCheckRouter() *gin.Engine {
+	router := gin.Default()
+	router.POST("/linkcheck", controllers.StartLinkCheck)
+	router.GET("/linkcheck/:job", controllers.GetLinkCheckJob)
+	return router
+}
+
+func initializeLinkCheckTestData() {
+	config.DB.Exec("DELETE FROM link_check_results")
+	config.DB.Exec("DELETE FROM link_check_jobs")
+}
+
+func TestRunLinkCheckJob(t *testing.T) {
+	initializeLinkCheckTestData()
+	server := newLinkTestServer()
+	defer server.Close()
+
+	job := models.LinkCheckJob{}
+	config.DB.Create(&job)
+	links := []services.LinkTarget{
+		{URL: server.URL + "/moved", Source: "redirect:1"},
+		{URL: server.URL + "/ok", Source: services.LinkSourceRequest},
+	}
+	pages := []services.LinkTarget{{URL: server.URL + "/page", Source: services.LinkSourcePage}}
+
+	err := services.RunLinkCheckJob(context.Background(), config.DB, testLinkChecker(), &job, links, pages, 0)
+	assert.NoError(t, err)
+
+	stored, err := services.GetLinkCheckJob(config.DB, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.LinkCheckStatusDone, stored.Status)
+	assert.Equal(t, 4, stored.Total)
+	assert.Equal(t, 4, stored.Checked)
+	assert.Equal(t, 1, stored.Broken)
+	assert.NotNil(t, stored.FinishedAt)
+
+	var broken models.LinkCheckResult
+	config.DB.Where("job_id = ? AND broken = ?", job.ID, true).First(&broken)
+	assert.Equal(t, server.URL+"/missing", broken.URL)
+	assert.Equal(t, server.URL+"/page", broken.Source)
+	assert.Equal(t, http.StatusNotFound, broken.StatusCode)
+
+	var moved models.LinkCheckResult
+	config.DB.Where("job_id = ? AND url = ?", job.ID, server.URL+"/moved").First(&moved)
+	assert.Len(t, moved.Redirects, 2)
+}
+
+func TestRunLinkCheckJobStopped(t *testing.T) {
+	initializeLinkCheckTestData()
+	server := newLinkTestServer()
+	defer server.Close()
+
+	job := models.LinkCheckJob{}
+	config.DB.Create(&job)
+	links := []services.LinkTarget{{URL: server.URL + "/ok", Source: services.LinkSourceRequest}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := services.RunLinkCheckJob(ctx, config.DB, testLinkChecker(), &job, links, nil, 0)
+	assert.ErrorIs(t, err, services.ErrLinkCheckStopped)
+
+	stored, err := services.GetLinkCheckJob(config.DB, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.LinkCheckStatusFailed, stored.Status)
+	assert.Equal(t, 0, stored.Checked)
+}
+
+func TestLinkCheckEndpoint(t *testing.T) {
+	initializeLinkCheckTestData()
+	t.Setenv("URL_ALLOW_PRIVATE_HOSTS", "true")
+	t.Setenv("LINKCHECK_HOST_DELAY_MS", "0")
+	server := newLinkTestServer()
+	defer server.Close()
+	router := setupLinkCheckRouter()
+
+	requestJSON, _ := json.Marshal(map[string]interface{}{
+		"urls": []string{server.URL + "/ok", server.URL + "/missing"},
+	})
+	req, _ := http.NewRequest("POST", "/linkcheck", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	var started services.LinkCheckJobResponse
+	json.Unmarshal(resp.Body.Bytes(), &started)
+
+	var response services.LinkCheckResultsResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/linkcheck/%d?broken=true", started.Data.ID), nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &response)
+		if response.Data.Status == models.LinkCheckStatusDone {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	assert.Equal(t, models.LinkCheckStatusDone, response.Data.Status)
+	assert.Equal(t, 2, response.Data.Checked)
+	assert.Len(t, response.Results, 1)
+	assert.Equal(t, server.URL+"/missing", response.Results[0].URL)
+}
+
+func TestLinkCheckEndpointErrors(t *testing.T) {
+	initializeLinkCheckTestData()
+	router := setupLinkCheckRouter()
+
+	req, _ := http.NewRequest("POST", "/linkcheck", bytes.NewBufferString(`{"urls": []}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/linkcheck/999", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLoanRouter() *gin.Engine {
+	router := gin.Default()
+	router.POST("/books/:id/checkout", controllers.CheckoutBook)
+	router.GET("/loans", controllers.GetLoans)
+	router.POST("/loans/:id/return", controllers.ReturnLoan)
+	router.POST("/loans/:id/renew", controllers.RenewLoan)
+	router.DELETE("/books/:id", controllers.DeleteBookByID)
+	return router
+}
+
+func initializeLoanTestData() {
+	initializeTestData()
+	config.DB.Exec("DELETE FROM fine_entries")
+	config.DB.Exec("DELETE FROM users")
+	config.DB.Exec("ALTER SEQUENCE users_id_seq RESTART WITH 1")
+	config.DB.Exec("ALTER SEQUENCE loans_id_seq RESTART WITH 1")
+	config.DB.Exec("ALTER SEQUENCE copies_id_seq RESTART WITH 1")
+	config.DB.Exec("ALTER SEQUENCE holds_id_seq RESTART WITH 1")
+
+	users := []models.User{
+		{Name: "User One", Email: "one@byfood.com"},
+		{Name: "User Two", Email: "two@byfood.com"},
+	}
+
+	for _, user := range users {
+		config.DB.Create(&user)
+	}
+
+	copies := []models.Copy{
+		{BookID: 1, Barcode: "BF-0001", Status: models.CopyStatusAvailable},
+		{BookID: 2, Barcode: "BF-0002", Status: models.CopyStatusAvailable},
+	}
+
+	for _, bookCopy := range copies {
+		config.DB.Create(&bookCopy)
+	}
+}
+
+func checkout(router *gin.Engine, bookID string, userID uint) *httptest.ResponseRecorder {
+	requestJSON, _ := json.Marshal(controllers.CheckoutRequest{UserID: userID})
+	req, _ := http.NewRequest("POST", "/books/"+bookID+"/checkout", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestCheckoutBook(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+
+	resp := checkout(router, "1", 1)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var responseBody map[string]interface{}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Equal(t, "Book checked out successfully", responseBody["message"])
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["book_id"])
+	assert.Equal(t, "active", data["status"])
+}
+
+func TestCheckoutBookNoCopyAvailable(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+
+	assert.Equal(t, http.StatusCreated, checkout(router, "1", 1).Code)
+	resp := checkout(router, "1", 2)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	var responseBody map[string]interface{}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Equal(t, "No copy of this book is available", responseBody["error"])
+}
+
+func TestCheckoutBookUnknownUser(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+
+	resp := checkout(router, "1", 99)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestReturnLoan(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+	checkout(router, "1", 1)
+
+	req, _ := http.NewRequest("POST", "/loans/1/return", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/loans/1/return", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	assert.Equal(t, http.StatusCreated, checkout(router, "1", 2).Code)
+}
+
+func TestDeleteBookWithOpenLoan(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+	checkout(router, "1", 1)
+
+	req, _ := http.NewRequest("DELETE", "/books/1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/loans/1/return", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("DELETE", "/books/1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var copies int64
+	config.DB.Model(&models.Copy{}).Where("book_id = ?", 1).Count(&copies)
+	assert.Equal(t, int64(0), copies)
+}
+
+func TestRenewLoan(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+	checkout(router, "1", 1)
+
+	req, _ := http.NewRequest("POST", "/loans/1/renew", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody map[string]interface{}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["renewals"])
+}
+
+func TestGetOverdueLoans(t *testing.T) {
+	initializeLoanTestData()
+	router := setupLoanRouter()
+	checkout(router, "1", 1)
+	checkout(router, "2", 2)
+	config.DB.Model(&models.Loan{}).Where("id = ?", 2).Update("due_at", time.Now().Add(-24*time.Hour))
+
+	req, _ := http.NewRequest("GET", "/loans?status=overdue", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody map[string]interface{}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody["data"], 1)
+
+	req, _ = http.NewRequest("GET", "/loans?status=lost", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
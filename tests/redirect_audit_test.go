@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exactRedirect(id uint, source, target string) models.RedirectRule {
+	return models.RedirectRule{ID: id, Source: source, Target: target, StatusCode: 301, MatchType: models.RedirectMatchExact, Enabled: true}
+}
+
+func TestAuditRedirectRules(t *testing.T) {
+	rules := []models.RedirectRule{
+		exactRedirect(1, "/a", "/b"),
+		exactRedirect(2, "/b", "/c"),
+		exactRedirect(3, "/c", "/d"),
+		exactRedirect(4, "/x", "/y"),
+		exactRedirect(5, "/y", "/x"),
+		exactRedirect(6, "/z", "/x"),
+		exactRedirect(7, "/single", "/done"),
+	}
+
+	audit, err := services.AuditRedirectRules(rules, 2, 10)
+	assert.NoError(t, err)
+
+	assert.Len(t, audit.Chains, 1)
+	assert.Equal(t, []uint{1, 2, 3}, audit.Chains[0].RuleIDs)
+	assert.Equal(t, []string{"/a", "/b", "/c", "/d"}, audit.Chains[0].URLs)
+	assert.Equal(t, 3, audit.Chains[0].Length)
+	assert.True(t, audit.Chains[0].TooLong)
+
+	assert.Len(t, audit.Loops, 1)
+	assert.Equal(t, []uint{4, 5}, audit.Loops[0].RuleIDs)
+	assert.Equal(t, []string{"/x", "/y", "/x"}, audit.Loops[0].URLs)
+}
+
+func TestAuditRedirectRulesWithPrefixAndRegex(t *testing.T) {
+	rules := []models.RedirectRule{
+		exactRedirect(1, "/old-tokyo", "/experiences/tokyo"),
+		{ID: 2, Source: "/experiences", Target: "/food-experiences", StatusCode: 301, MatchType: models.RedirectMatchPrefix, Enabled: true},
+		{ID: 3, Source: `^/food-experiences/(\w+)$`, Target: "/guides/$1", StatusCode: 301, MatchType: models.RedirectMatchRegex, Enabled: true},
+	}
+
+	audit, err := services.AuditRedirectRules(rules, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, audit.Loops, 0)
+	assert.Len(t, audit.Chains, 1)
+	assert.Equal(t, []string{"/old-tokyo", "/experiences/tokyo", "/food-experiences/tokyo", "/guides/tokyo"}, audit.Chains[0].URLs)
+}
+
+func TestAuditRedirectRulesWithAbsoluteSiteTargets(t *testing.T) {
+	t.Setenv("REDIRECT_SITE_HOSTS", "www.byfood.com,byfood.com")
+	rules := []models.RedirectRule{
+		exactRedirect(1, "/a", "https://www.byfood.com/b"),
+		exactRedirect(2, "/b", "https://byfood.com/a"),
+		exactRedirect(3, "/out", "https://example.com/b"),
+	}
+
+	audit, err := services.AuditRedirectRules(rules, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, audit.Chains, 0)
+	assert.Len(t, audit.Loops, 1)
+	assert.Equal(t, []uint{1, 2}, audit.Loops[0].RuleIDs)
+
+	_, err = services.ResolveRedirectWith(rules, "/a", 10)
+	assert.ErrorIs(t, err, services.ErrRedirectLoop)
+
+	resolution, err := services.ResolveRedirectWith(rules, "/out", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/b", resolution.Target)
+}
+
+func TestAddRedirectRuleRejectsLoop(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	assert.Equal(t, http.StatusCreated, addRedirectRule(router, services.RedirectRuleChanges{Source: "/a", Target: "/b"}).Code)
+	assert.Equal(t, http.StatusCreated, addRedirectRule(router, services.RedirectRuleChanges{Source: "/b", Target: "/c"}).Code)
+
+	resp := addRedirectRule(router, services.RedirectRuleChanges{Source: "/c", Target: "/a"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var count int64
+	config.DB.Model(&models.RedirectRule{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestConcurrentAddRedirectRulesRejectLoop(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i, rule := range []services.RedirectRuleChanges{{Source: "/a", Target: "/b"}, {Source: "/b", Target: "/a"}} {
+		wg.Add(1)
+		go func(i int, rule services.RedirectRuleChanges) {
+			defer wg.Done()
+			codes[i] = addRedirectRule(router, rule).Code
+		}(i, rule)
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []int{http.StatusCreated, http.StatusConflict}, codes)
+	var count int64
+	config.DB.Model(&models.RedirectRule{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAddRedirectRuleWarnsOnChain(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/a", Target: "/b"})
+	resp := addRedirectRule(router, services.RedirectRuleChanges{Source: "/b", Target: "/c"})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var responseBody services.RedirectRuleResponse
+	json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.Len(t, responseBody.Warnings, 1)
+	assert.Contains(t, responseBody.Warnings[0], "/a -> /b -> /c")
+}
+
+func TestAddRedirectRuleFlattensChain(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/a", Target: "/b"})
+
+	requestJSON, _ := json.Marshal(services.RedirectRuleChanges{Source: "/b", Target: "/c"})
+	req, _ := http.NewRequest("POST", "/redirects?flatten=true", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var first models.RedirectRule
+	config.DB.First(&first, 1)
+	assert.Equal(t, "/c", first.Target)
+
+	req, _ = http.NewRequest("GET", "/redirects/audit", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var audit services.RedirectAuditResponse
+	json.Unmarshal(resp.Body.Bytes(), &audit)
+	assert.Len(t, audit.Data.Chains, 0)
+	assert.Len(t, audit.Data.Loops, 0)
+}
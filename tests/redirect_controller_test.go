@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRedirectRouter() *gin.Engine {
+	router := gin.Default()
+	router.POST("/redirects", controllers.AddRedirectRule)
+	router.GET("/redirects", controllers.GetRedirectRules)
+	router.GET("/redirects/resolve", controllers.ResolveRedirect)
+	router.GET("/redirects/audit", controllers.AuditRedirectRules)
+	router.GET("/redirects/export", controllers.ExportRedirectRules)
+	router.POST("/redirects/import", controllers.ImportRedirectRules)
+	router.GET("/redirects/:id", controllers.GetRedirectRuleByID)
+	router.PUT("/redirects/:id", controllers.UpdateRedirectRule)
+	router.DELETE("/redirects/:id", controllers.DeleteRedirectRule)
+	router.NoRoute(controllers.HandleRedirect)
+	return router
+}
+
+func initializeRedirectTestData() {
+	config.DB.Exec("DELETE FROM redirect_rules")
+	config.DB.Exec("ALTER SEQUENCE redirect_rules_id_seq RESTART WITH 1")
+	services.InvalidateRedirectRules()
+}
+
+func addRedirectRule(router *gin.Engine, rule services.RedirectRuleChanges) *httptest.ResponseRecorder {
+	requestJSON, _ := json.Marshal(rule)
+	req, _ := http.NewRequest("POST", "/redirects", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestResolveRedirectWith(t *testing.T) {
+	rules := []models.RedirectRule{
+		{ID: 1, Source: "/old", Target: "/new", StatusCode: 301, MatchType: models.RedirectMatchExact},
+		{ID: 2, Source: "/experiences", Target: "/food-experiences", StatusCode: 308, MatchType: models.RedirectMatchPrefix},
+		{ID: 3, Source: `^/blog/(\d+)$`, Target: "https://blog.byfood.com/posts/$1", StatusCode: 302, MatchType: models.RedirectMatchRegex},
+		{ID: 4, Source: "/new", Target: "/newest", StatusCode: 301, MatchType: models.RedirectMatchExact},
+	}
+
+	tests := []struct {
+		input  string
+		target string
+		status int
+		hops   int
+	}{
+		{"https://www.byfood.com/old?page=2", "https://www.byfood.com/newest?page=2", 301, 2},
+		{"/experiences/tokyo", "/food-experiences/tokyo", 308, 1},
+		{"/experiences", "/food-experiences", 308, 1},
+		{"https://www.byfood.com/blog/42", "https://blog.byfood.com/posts/42", 302, 1},
+	}
+
+	for _, tt := range tests {
+		resolution, err := services.ResolveRedirectWith(rules, tt.input, 10)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.target, resolution.Target, tt.input)
+		assert.Equal(t, tt.status, resolution.StatusCode, tt.input)
+		assert.Len(t, resolution.Hops, tt.hops, tt.input)
+	}
+
+	_, err := services.ResolveRedirectWith(rules, "/experiencesX", 10)
+	assert.True(t, errors.Is(err, services.ErrNoRedirect))
+
+	resolution, err := services.ResolveRedirectWith(rules, "/old", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "/new", resolution.Target)
+}
+
+func TestResolveRedirectWithLoop(t *testing.T) {
+	rules := []models.RedirectRule{
+		{ID: 1, Source: "/a", Target: "/b", StatusCode: 301, MatchType: models.RedirectMatchExact},
+		{ID: 2, Source: "/b", Target: "/a", StatusCode: 301, MatchType: models.RedirectMatchExact},
+	}
+
+	_, err := services.ResolveRedirectWith(rules, "/a", 10)
+	assert.True(t, errors.Is(err, services.ErrRedirectLoop))
+}
+
+func TestRedirectRuleCRUD(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	resp := addRedirectRule(router, services.RedirectRuleChanges{Source: "/old", Target: "/new"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var created services.RedirectRuleResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	assert.Equal(t, 301, created.Data.StatusCode)
+	assert.Equal(t, models.RedirectMatchExact, created.Data.MatchType)
+	assert.True(t, created.Data.Enabled)
+
+	disabled := false
+	requestJSON, _ := json.Marshal(services.RedirectRuleChanges{StatusCode: 302, Enabled: &disabled})
+	req, _ := http.NewRequest("PUT", "/redirects/1", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var updated services.RedirectRuleResponse
+	json.Unmarshal(resp.Body.Bytes(), &updated)
+	assert.Equal(t, 302, updated.Data.StatusCode)
+	assert.False(t, updated.Data.Enabled)
+
+	req, _ = http.NewRequest("GET", "/redirects?enabled=false", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var list services.RedirectRuleListResponse
+	json.Unmarshal(resp.Body.Bytes(), &list)
+	assert.Len(t, list.Data, 1)
+
+	req, _ = http.NewRequest("DELETE", "/redirects/1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/redirects/1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAddRedirectRuleValidation(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	tests := []services.RedirectRuleChanges{
+		{Source: "old", Target: "/new"},
+		{Source: "/old", Target: "javascript:alert(1)"},
+		{Source: "/old", Target: "//evil.example/"},
+		{Source: "/old", Target: "/new", StatusCode: 307},
+		{Source: "/old", Target: "/new", MatchType: "glob"},
+		{Source: "([", Target: "/new", MatchType: models.RedirectMatchRegex},
+	}
+
+	for _, rule := range tests {
+		assert.Equal(t, http.StatusBadRequest, addRedirectRule(router, rule).Code, rule)
+	}
+}
+
+func TestResolveRedirectEndpoint(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/experiences", Target: "/food-experiences", MatchType: models.RedirectMatchPrefix})
+	lowPriority := -1
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/experiences/tokyo", Target: "/tokyo", Priority: &lowPriority})
+
+	req, _ := http.NewRequest("GET", "/redirects/resolve?url="+url.QueryEscape("https://www.byfood.com/experiences/tokyo?page=2"), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var resolution services.RedirectResolution
+	err := json.Unmarshal(resp.Body.Bytes(), &resolution)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/food-experiences/tokyo?page=2", resolution.Target)
+	assert.Equal(t, uint(1), resolution.Hops[0].RuleID)
+
+	req, _ = http.NewRequest("GET", "/redirects/resolve?url=/nowhere", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHandleRedirect(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/old", Target: "/new", StatusCode: 308})
+
+	req, _ := http.NewRequest("GET", "/old?x=1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusPermanentRedirect, resp.Code)
+	assert.Equal(t, "/new?x=1", resp.Header().Get("Location"))
+
+	req, _ = http.NewRequest("GET", "/missing", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHandleRedirectSeesSavedAndDeletedRules(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+	location := func() string {
+		req, _ := http.NewRequest("GET", "/old", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Header().Get("Location")
+	}
+
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/old", Target: "/new"})
+	assert.Equal(t, "/new", location())
+
+	requestJSON, _ := json.Marshal(services.RedirectRuleChanges{Target: "/newer"})
+	req, _ := http.NewRequest("PUT", "/redirects/1", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "/newer", location())
+
+	req, _ = http.NewRequest("DELETE", "/redirects/1", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "", location())
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var formatTestRules = []models.RedirectRule{
+	{Source: "/old.html", Target: "/new", StatusCode: 301, MatchType: models.RedirectMatchExact},
+	{Source: "/experiences", Target: "/food-experiences", StatusCode: 308, MatchType: models.RedirectMatchPrefix},
+	{Source: `^/blog/(\d+)$`, Target: "https://blog.byfood.com/posts/$1", StatusCode: 302, MatchType: models.RedirectMatchRegex},
+}
+
+func TestRedirectFormatsRoundTrip(t *testing.T) {
+	for _, format := range []string{services.RedirectFormatNginx, services.RedirectFormatApache, services.RedirectFormatVercel} {
+		exported, err := services.ExportRedirectRules(formatTestRules, format)
+		assert.NoError(t, err, format)
+		assert.Empty(t, exported.Warnings, format)
+
+		parsed, issues, err := services.ParseRedirectRules(exported.Config, format)
+		assert.NoError(t, err, format)
+		assert.Empty(t, issues, format)
+		if !assert.Len(t, parsed, len(formatTestRules), format) {
+			continue
+		}
+
+		byType := map[string]services.RedirectRuleChanges{}
+		for _, p := range parsed {
+			byType[p.Rule.MatchType] = p.Rule
+		}
+		for _, rule := range formatTestRules {
+			got := byType[rule.MatchType]
+			assert.Equal(t, rule.Source, got.Source, format)
+			assert.Equal(t, rule.Target, got.Target, format)
+			assert.Equal(t, rule.StatusCode, got.StatusCode, format)
+		}
+	}
+}
+
+func TestExportNginx(t *testing.T) {
+	rules := append(formatTestRules[:2:2], models.RedirectRule{Source: "/campaign", Target: "/offers?utm_source=campaign", StatusCode: 301, MatchType: models.RedirectMatchExact})
+	exported, err := services.ExportRedirectRules(rules, services.RedirectFormatNginx)
+	assert.NoError(t, err)
+	assert.Contains(t, exported.Config, "map $uri $redirect_301 {")
+	assert.Contains(t, exported.Config, `"/old.html" "/new$is_args$args";`)
+	assert.Contains(t, exported.Config, `"~^/experiences(/.*)?$" "/food-experiences$1$is_args$args";`)
+	assert.Contains(t, exported.Config, `"/campaign" "/offers?utm_source=campaign";`)
+	assert.Contains(t, exported.Config, "return 308 $redirect_308;")
+}
+
+func TestExportApache(t *testing.T) {
+	exported, err := services.ExportRedirectRules(formatTestRules[:2], services.RedirectFormatApache)
+	assert.NoError(t, err)
+	assert.Contains(t, exported.Config, `RewriteRule ^/?old\.html$ /new [R=301,L]`)
+	assert.Contains(t, exported.Config, `RewriteRule ^/?experiences(/.*)?$ /food-experiences$1 [R=308,L]`)
+}
+
+func TestExportVercelTranslatesPatterns(t *testing.T) {
+	rules := []models.RedirectRule{
+		{ID: 1, Source: `^/tags/[a-z]+/(\d+)$`, Target: "/topics/$1", StatusCode: 301, MatchType: models.RedirectMatchRegex},
+		{ID: 2, Source: `^/guide(\.html)?$`, Target: "/guides", StatusCode: 301, MatchType: models.RedirectMatchRegex},
+		{ID: 3, Source: `^/archive/(\d{4})/((\d+))$`, Target: "/posts/$2", StatusCode: 301, MatchType: models.RedirectMatchRegex},
+		{ID: 4, Source: `(?i)^/Shop$`, Target: "/store", StatusCode: 301, MatchType: models.RedirectMatchRegex},
+		{ID: 5, Source: "/a:b", Target: "/c", StatusCode: 301, MatchType: models.RedirectMatchExact},
+	}
+
+	exported, err := services.ExportRedirectRules(rules, services.RedirectFormatVercel)
+	assert.NoError(t, err)
+	assert.Contains(t, exported.Config, `"source": "/tags/([a-z]+)/(\\d+)"`)
+	assert.Contains(t, exported.Config, `"destination": "/topics/$2"`)
+	assert.Contains(t, exported.Config, `"source": "/guide(\\.html)?"`)
+	assert.Contains(t, exported.Config, `"source": "/a\\:b"`)
+	assert.Len(t, exported.Warnings, 2)
+	assert.Contains(t, exported.Warnings[0], "rule 3")
+	assert.Contains(t, exported.Warnings[1], "rule 4")
+}
+
+func TestParseApacheReportsUnparsedLines(t *testing.T) {
+	config := strings.Join([]string{
+		"RewriteEngine On",
+		"RewriteCond %{HTTP_HOST} ^byfood.com$",
+		"RewriteRule ^old$ /new [R=301,L]",
+		"RewriteRule ^internal$ /index.php [L]",
+		"Redirect permanent /shop /store",
+		"RedirectMatch 302 ^/tag/(.*)$ /tags/$1",
+		"Header set X-Frame-Options DENY",
+	}, "\n")
+
+	parsed, issues, err := services.ParseRedirectRules(config, services.RedirectFormatApache)
+	assert.NoError(t, err)
+	assert.Len(t, parsed, 3)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/old", Target: "/new", StatusCode: 301, MatchType: models.RedirectMatchExact}, parsed[0].Rule)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/shop", Target: "/store", StatusCode: 301, MatchType: models.RedirectMatchPrefix}, parsed[1].Rule)
+	assert.Equal(t, models.RedirectMatchRegex, parsed[2].Rule.MatchType)
+
+	assert.Len(t, issues, 3)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Equal(t, "RewriteCond is not supported", issues[0].Reason)
+	assert.Equal(t, 4, issues[1].Line)
+	assert.Equal(t, 7, issues[2].Line)
+}
+
+func TestParseNginxRewrites(t *testing.T) {
+	config := strings.Join([]string{
+		"rewrite ^/old$ /new permanent;",
+		"location = /about { return 301 /company; }",
+		"location /docs { return 302 /help; }",
+		"rewrite ^/app(.*)$ /index.html last;",
+		"gzip on;",
+	}, "\n")
+
+	parsed, issues, err := services.ParseRedirectRules(config, services.RedirectFormatNginx)
+	assert.NoError(t, err)
+	assert.Len(t, parsed, 3)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/old", Target: "/new", StatusCode: 301, MatchType: models.RedirectMatchExact}, parsed[0].Rule)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/about", Target: "/company", StatusCode: 301, MatchType: models.RedirectMatchExact}, parsed[1].Rule)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/docs", Target: "/help", StatusCode: 302, MatchType: models.RedirectMatchPrefix}, parsed[2].Rule)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, 4, issues[0].Line)
+	assert.Equal(t, 5, issues[1].Line)
+}
+
+func TestParseVercel(t *testing.T) {
+	config := `{
+  "trailingSlash": false,
+  "redirects": [
+    {"source": "/old", "destination": "/new", "permanent": true},
+    {"source": "/blog/:slug", "destination": "/posts/:slug"},
+    {"source": "/shop/:path*", "destination": "/store/:path*", "statusCode": 301},
+    {"source": "/beta", "destination": "/", "has": [{"type": "host", "value": "beta.byfood.com"}]}
+  ]
+}`
+
+	parsed, issues, err := services.ParseRedirectRules(config, services.RedirectFormatVercel)
+	assert.NoError(t, err)
+	assert.Len(t, parsed, 2)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/old", Target: "/new", StatusCode: 308, MatchType: models.RedirectMatchExact}, parsed[0].Rule)
+	assert.Equal(t, services.RedirectRuleChanges{Source: "/shop", Target: "/store", StatusCode: 301, MatchType: models.RedirectMatchPrefix}, parsed[1].Rule)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Equal(t, 4, issues[1].Line)
+
+	_, _, err = services.ParseRedirectRules("{", services.RedirectFormatVercel)
+	assert.True(t, errors.Is(err, services.ErrInvalidRedirectConfig))
+
+	_, _, err = services.ParseRedirectRules("", "caddy")
+	assert.True(t, errors.Is(err, services.ErrUnknownRedirectFormat))
+}
+
+func TestImportAndExportRedirectRules(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+
+	config := "RewriteEngine On\nRewriteRule ^/?old$ /new [R=301,L]\nRewriteRule ^/?loop$ /loop [R=301,L]\nRewriteCond %{HTTPS} off\n"
+	req, _ := http.NewRequest("POST", "/redirects/import?format=apache", strings.NewReader(config))
+	req.Header.Set("Content-Type", "text/plain")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody services.RedirectImportResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody.Data.Imported, 1)
+	assert.Len(t, responseBody.Data.Unparsed, 2)
+	assert.Equal(t, 3, responseBody.Data.Unparsed[0].Line)
+	assert.Equal(t, 4, responseBody.Data.Unparsed[1].Line)
+
+	req, _ = http.NewRequest("GET", "/redirects/export?format=vercel", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, resp.Body.String(), `"source": "/old"`)
+
+	req, _ = http.NewRequest("GET", "/redirects/export?format=caddy", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,255 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupShortLinkRouter() *gin.Engine {
+	router := gin.Default()
+	router.POST("/links", controllers.AddShortLink)
+	router.GET("/links", controllers.GetShortLinks)
+	router.GET("/links/:id", controllers.GetShortLinkByID)
+	router.PUT("/links/:id", controllers.UpdateShortLink)
+	router.DELETE("/links/:id", controllers.DeleteShortLink)
+	router.GET("/s/:code", controllers.FollowShortLink)
+	return router
+}
+
+func initializeShortLinkTestData() {
+	config.DB.Exec("DELETE FROM short_links")
+	config.DB.Exec("ALTER SEQUENCE short_links_id_seq RESTART WITH 1")
+}
+
+func sendShortLinkRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	requestJSON, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestGenerateShortCode(t *testing.T) {
+	code, err := services.GenerateShortCode(7)
+	assert.NoError(t, err)
+	assert.Len(t, code, 7)
+	assert.True(t, services.ValidShortCode(code))
+
+	other, _ := services.GenerateShortCode(7)
+	assert.NotEqual(t, code, other)
+}
+
+func TestValidShortCode(t *testing.T) {
+	for _, code := range []string{"abc", "spring-sale_2024", strings.Repeat("a", 64)} {
+		assert.True(t, services.ValidShortCode(code), code)
+	}
+	for _, code := range []string{"", "ab", "has space", "slash/code", "ラーメン", strings.Repeat("a", 65)} {
+		assert.False(t, services.ValidShortCode(code), code)
+	}
+}
+
+func TestShortLinkActive(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	assert.True(t, models.ShortLink{Enabled: true}.Active(now))
+	assert.True(t, models.ShortLink{Enabled: true, ExpiresAt: &later}.Active(now))
+	assert.False(t, models.ShortLink{Enabled: true, ExpiresAt: &earlier}.Active(now))
+	assert.False(t, models.ShortLink{Enabled: false}.Active(now))
+}
+
+func TestShortLinkAllowedHosts(t *testing.T) {
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "")
+	t.Setenv("URL_FORCE_HOST", "www.byfood.com")
+	t.Setenv("SHORTLINK_BASE_URL", "https://go.byfood.com/")
+	assert.Equal(t, []string{"go.byfood.com"}, config.ShortLinkAllowedHosts())
+
+	t.Setenv("SHORTLINK_BASE_URL", "")
+	assert.Equal(t, []string{"www.byfood.com"}, config.ShortLinkAllowedHosts())
+
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "www.byfood.com,blog.byfood.com")
+	assert.Equal(t, []string{"www.byfood.com", "blog.byfood.com"}, config.ShortLinkAllowedHosts())
+}
+
+func TestAddShortLinkReusesCanonicalURL(t *testing.T) {
+	initializeShortLinkTestData()
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "www.byfood.com")
+	t.Setenv("SHORTLINK_BASE_URL", "https://byfood.link/")
+	router := setupShortLinkRouter()
+
+	resp := sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/ramen-guide/?utm_source=mail"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var created services.ShortLinkResponse
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	assert.Equal(t, "https://www.byfood.com/books/ramen-guide", created.Data.TargetURL)
+	assert.Len(t, created.Data.Code, 7)
+	assert.Equal(t, "https://byfood.link/s/"+created.Data.Code, created.Data.ShortURL)
+
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "HTTPS://WWW.byfood.com/books/ramen-guide"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var reused services.ShortLinkResponse
+	json.Unmarshal(resp.Body.Bytes(), &reused)
+	assert.Equal(t, created.Data.Code, reused.Data.Code)
+
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "javascript:alert(1)"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestAddShortLinkCustomCode(t *testing.T) {
+	initializeShortLinkTestData()
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "www.byfood.com")
+	router := setupShortLinkRouter()
+
+	resp := sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/a", Code: "spring-sale"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/a/", Code: "spring-sale"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/b", Code: "spring-sale"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://evil.example/login", Code: "sign-in"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/b", Code: "no"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	past := time.Now().Add(-time.Hour)
+	resp = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/b", ExpiresAt: &past})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestConcurrentAddShortLinkSameCode(t *testing.T) {
+	initializeShortLinkTestData()
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "www.byfood.com")
+	router := setupShortLinkRouter()
+
+	var wg sync.WaitGroup
+	codes := make([]int, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := "https://www.byfood.com/books/a"
+			if i%2 == 1 {
+				target = "https://www.byfood.com/books/b"
+			}
+			codes[i] = sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: target, Code: "summer-sale"}).Code
+		}(i)
+	}
+	wg.Wait()
+
+	counts := map[int]int{}
+	for _, code := range codes {
+		counts[code]++
+	}
+	assert.Equal(t, 1, counts[http.StatusCreated])
+	assert.Equal(t, len(codes)/2-1, counts[http.StatusOK])
+	assert.Equal(t, len(codes)/2, counts[http.StatusConflict])
+
+	var links int64
+	config.DB.Model(&models.ShortLink{}).Count(&links)
+	assert.Equal(t, int64(1), links)
+}
+
+func TestFollowShortLink(t *testing.T) {
+	initializeShortLinkTestData()
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "www.byfood.com")
+	router := setupShortLinkRouter()
+
+	sendShortLinkRequest(router, "POST", "/links", services.ShortLinkRequest{URL: "https://www.byfood.com/books/a", Code: "book-a"})
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "/s/book-a", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Equal(t, "https://www.byfood.com/books/a", resp.Header().Get("Location"))
+	}
+
+	req, _ := http.NewRequest("GET", "/links/1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	var link models.ShortLink
+	json.Unmarshal(resp.Body.Bytes(), &link)
+	assert.Equal(t, int64(2), link.Clicks)
+	assert.NotNil(t, link.LastClickedAt)
+
+	disabled := false
+	resp = sendShortLinkRequest(router, "PUT", "/links/1", services.ShortLinkChanges{Enabled: &disabled})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/s/book-a", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/s/missing", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestFollowExpiredShortLink(t *testing.T) {
+	initializeShortLinkTestData()
+	router := setupShortLinkRouter()
+
+	expired := time.Now().Add(-time.Minute)
+	config.DB.Create(&models.ShortLink{Code: "old-sale", TargetURL: "https://www.byfood.com/", Enabled: true, ExpiresAt: &expired})
+
+	req, _ := http.NewRequest("GET", "/s/old-sale", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusGone, resp.Code)
+
+	resp = sendShortLinkRequest(router, "PUT", "/links/1", services.ShortLinkChanges{NoExpiry: true})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/s/old-sale", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusFound, resp.Code)
+}
+
+func TestShortURLIgnoresForwardedHeadersFromUntrustedClients(t *testing.T) {
+	initializeShortLinkTestData()
+	t.Setenv("SHORTLINK_ALLOWED_HOSTS", "www.byfood.com")
+	t.Setenv("SHORTLINK_BASE_URL", "")
+	router := setupShortLinkRouter()
+	create := func() string {
+		requestJSON, _ := json.Marshal(services.ShortLinkRequest{URL: "https://www.byfood.com/books/ramen-guide"})
+		req, _ := http.NewRequest("POST", "/links", bytes.NewBuffer(requestJSON))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "evil.example")
+		req.Host = "api.byfood.com"
+		req.RemoteAddr = "203.0.113.7:4321"
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var responseBody services.ShortLinkResponse
+		json.Unmarshal(resp.Body.Bytes(), &responseBody)
+		return responseBody.Data.ShortURL
+	}
+
+	assert.True(t, strings.HasPrefix(create(), "http://api.byfood.com/s/"))
+
+	t.Setenv("TRUSTED_PROXIES", "203.0.113.0/24")
+	assert.True(t, strings.HasPrefix(create(), "https://evil.example/s/"))
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const auditedSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://www.byfood.com/books/1</loc></url>
+  <url><loc> https://www.byfood.com/books/2/ </loc></url>
+  <url><loc>https://WWW.byfood.com/books/1?utm_source=x</loc></url>
+  <url><loc>https://www.byfood.com/old-books/3</loc></url>
+  <url><loc>javascript:alert(1)</loc></url>
+</urlset>`
+
+func TestAuditSitemap(t *testing.T) {
+	rules := []models.RedirectRule{
+		{ID: 1, Source: "/old-books", Target: "/books", StatusCode: 301, MatchType: models.RedirectMatchPrefix},
+	}
+
+	audit, err := services.AuditSitemap([]byte(auditedSitemap), []string{"normalize", "canonical"}, rules, 10, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "urlset", audit.Type)
+	assert.Equal(t, 5, audit.Total)
+	assert.Equal(t, 2, audit.NotCanonical)
+	assert.Equal(t, 1, audit.Duplicates)
+	assert.Equal(t, 1, audit.Redirected)
+	assert.Equal(t, 1, audit.Invalid)
+	assert.Len(t, audit.Entries, 4)
+
+	assert.Equal(t, "https://www.byfood.com/books/2/", audit.Entries[0].Loc)
+	assert.Equal(t, []string{services.SitemapIssueNotCanonical}, audit.Entries[0].Issues)
+	assert.Equal(t, "https://www.byfood.com/books/2", audit.Entries[0].CanonicalURL)
+
+	assert.Equal(t, []string{services.SitemapIssueNotCanonical, services.SitemapIssueDuplicate}, audit.Entries[1].Issues)
+	assert.Equal(t, 0, *audit.Entries[1].DuplicateOf)
+
+	assert.Equal(t, []string{services.SitemapIssueRedirect}, audit.Entries[2].Issues)
+	assert.Equal(t, "https://www.byfood.com/books/3", audit.Entries[2].Redirect.Target)
+
+	assert.Equal(t, []string{services.SitemapIssueInvalid}, audit.Entries[3].Issues)
+	assert.NotEmpty(t, audit.Entries[3].Error)
+}
+
+func TestParseSitemapIndexGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://www.byfood.com/sitemaps/1.xml</loc></sitemap>
+  <sitemap><loc>https://www.byfood.com/sitemaps/2.xml</loc></sitemap>
+</sitemapindex>`))
+	writer.Close()
+
+	kind, locs, err := services.ParseSitemap(compressed.Bytes(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "sitemapindex", kind)
+	assert.Equal(t, []string{"https://www.byfood.com/sitemaps/1.xml", "https://www.byfood.com/sitemaps/2.xml"}, locs)
+}
+
+func TestParseSitemapRejectsOtherDocuments(t *testing.T) {
+	_, _, err := services.ParseSitemap([]byte(`<html><body>not a sitemap</body></html>`), 0)
+	assert.ErrorIs(t, err, services.ErrInvalidSitemap)
+
+	_, _, err = services.ParseSitemap([]byte(`{"urls": []}`), 0)
+	assert.ErrorIs(t, err, services.ErrInvalidSitemap)
+}
+
+func TestAuditSitemapTooLarge(t *testing.T) {
+	_, err := services.AuditSitemap([]byte(auditedSitemap), []string{"canonical"}, nil, 10, 3, 0)
+	assert.ErrorIs(t, err, services.ErrSitemapTooLarge)
+}
+
+func TestParseSitemapFileTooLarge(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(auditedSitemap + strings.Repeat(" ", 4096)))
+	writer.Close()
+
+	_, _, err := services.ParseSitemap(compressed.Bytes(), 1024)
+	assert.ErrorIs(t, err, services.ErrSitemapFileTooLarge)
+	_, _, err = services.ParseSitemap([]byte(auditedSitemap), 100)
+	assert.ErrorIs(t, err, services.ErrSitemapFileTooLarge)
+	_, locs, err := services.ParseSitemap(compressed.Bytes(), 8192)
+	assert.NoError(t, err)
+	assert.Len(t, locs, 5)
+}
+
+func TestAuditSitemapEndpointRejectsLargeBodies(t *testing.T) {
+	t.Setenv("SITEMAP_AUDIT_MAX_BYTES", "100")
+	router := gin.Default()
+	router.POST("/process_url/sitemap-audit", controllers.AuditSitemap)
+
+	req, _ := http.NewRequest("POST", "/process_url/sitemap-audit", strings.NewReader(auditedSitemap))
+	req.Header.Set("Content-Type", "application/xml")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+}
+
+func TestAuditSitemapEndpoint(t *testing.T) {
+	initializeRedirectTestData()
+	router := setupRedirectRouter()
+	router.POST("/process_url/sitemap-audit", controllers.AuditSitemap)
+	addRedirectRule(router, services.RedirectRuleChanges{Source: "/old-books", Target: "/books", MatchType: models.RedirectMatchPrefix})
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, _ := form.CreateFormFile("file", "sitemap.xml")
+	part.Write([]byte(auditedSitemap))
+	form.Close()
+
+	req, _ := http.NewRequest("POST", "/process_url/sitemap-audit", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var response services.SitemapAuditResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, response.Data.Total)
+	assert.Equal(t, 1, response.Data.Redirected)
+
+	req, _ = http.NewRequest("POST", "/process_url/sitemap-audit", bytes.NewBufferString("not xml"))
+	req.Header.Set("Content-Type", "application/xml")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSitemapRouter() *gin.Engine {
+	router := gin.Default()
+	router.GET("/sitemap.xml", controllers.GetSitemap)
+	router.GET("/sitemap.xml.gz", controllers.GetSitemap)
+	router.GET("/sitemaps/:file", controllers.GetSitemapPage)
+	return router
+}
+
+func TestBuildSitemapURLSet(t *testing.T) {
+	sitemap := services.BookSitemap{
+		Template:   "https://WWW.byfood.com/books/{id}/?utm_source=sitemap",
+		Operations: []string{"normalize", "canonical"},
+		MaxURLs:    10,
+	}
+	updated := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	urlSet, err := sitemap.BuildURLSet([]models.Book{{ID: 7, UpdatedAt: updated}, {ID: 8}})
+	assert.NoError(t, err)
+	assert.Equal(t, services.SitemapNamespace, urlSet.Xmlns)
+	assert.Equal(t, []services.SitemapURL{
+		{Loc: "https://www.byfood.com/books/7", LastMod: "2024-05-01T03:30:00Z"},
+		{Loc: "https://www.byfood.com/books/8"},
+	}, urlSet.URLs)
+}
+
+func TestBuildSitemapURLSetWithSlug(t *testing.T) {
+	sitemap := services.BookSitemap{Template: "https://www.byfood.com/books/{slug}", Operations: []string{"canonical"}, MaxURLs: 10}
+
+	urlSet, err := sitemap.BuildURLSet([]models.Book{{ID: 7, Slug: "ramen-guide-yamada"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/books/ramen-guide-yamada", urlSet.URLs[0].Loc)
+
+	_, err = sitemap.BuildURLSet([]models.Book{{ID: 8}})
+	assert.ErrorIs(t, err, services.ErrBookWithoutSlug)
+}
+
+func TestBuildSitemapRejectsTemplateWithoutID(t *testing.T) {
+	sitemap := services.BookSitemap{Template: "https://www.byfood.com/books", Operations: []string{"canonical"}, MaxURLs: 10}
+
+	_, err := sitemap.BuildURLSet([]models.Book{{ID: 1}})
+	assert.ErrorIs(t, err, services.ErrInvalidSitemapTemplate)
+}
+
+func TestGetSitemap(t *testing.T) {
+	initializeTestData()
+	t.Setenv("SITEMAP_BOOK_URL_TEMPLATE", "https://www.byfood.com/books/{id}/")
+	router := setupSitemapRouter()
+
+	req, _ := http.NewRequest("GET", "/sitemap.xml", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/xml")
+	var urlSet services.SitemapURLSet
+	err := xml.Unmarshal(resp.Body.Bytes(), &urlSet)
+	assert.NoError(t, err)
+	assert.Len(t, urlSet.URLs, 3)
+	assert.Equal(t, "https://www.byfood.com/books/1", urlSet.URLs[0].Loc)
+	assert.NotEmpty(t, urlSet.URLs[0].LastMod)
+}
+
+func TestGetSitemapIndex(t *testing.T) {
+	initializeTestData()
+	t.Setenv("SITEMAP_MAX_URLS", "2")
+	t.Setenv("SITEMAP_BASE_URL", "https://api.byfood.com/")
+	router := setupSitemapRouter()
+
+	req, _ := http.NewRequest("GET", "/sitemap.xml", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var index services.SitemapIndex
+	err := xml.Unmarshal(resp.Body.Bytes(), &index)
+	assert.NoError(t, err)
+	assert.Len(t, index.Sitemaps, 2)
+	assert.Equal(t, "https://api.byfood.com/sitemaps/1.xml", index.Sitemaps[0].Loc)
+	assert.Equal(t, "https://api.byfood.com/sitemaps/2.xml", index.Sitemaps[1].Loc)
+	assert.NotEmpty(t, index.Sitemaps[0].LastMod)
+	assert.NotEmpty(t, index.Sitemaps[1].LastMod)
+
+	req, _ = http.NewRequest("GET", "/sitemaps/2.xml", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var urlSet services.SitemapURLSet
+	err = xml.Unmarshal(resp.Body.Bytes(), &urlSet)
+	assert.NoError(t, err)
+	assert.Len(t, urlSet.URLs, 1)
+	assert.Equal(t, "https://www.byfood.com/books/book-three-author-three", urlSet.URLs[0].Loc)
+
+	req, _ = http.NewRequest("GET", "/sitemaps/3.xml", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestGetSitemapGzip(t *testing.T) {
+	initializeTestData()
+	router := setupSitemapRouter()
+
+	req, _ := http.NewRequest("GET", "/sitemap.xml.gz", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/gzip", resp.Header().Get("Content-Type"))
+	reader, err := gzip.NewReader(resp.Body)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	var urlSet services.SitemapURLSet
+	err = xml.Unmarshal(body, &urlSet)
+	assert.NoError(t, err)
+	assert.Len(t, urlSet.URLs, 3)
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"The Great Gatsby F. Scott Fitzgerald", "the-great-gatsby-f-scott-fitzgerald"},
+		{"  Crème Brûlée & Café  ", "creme-brulee-and-cafe"},
+		{"Straße Øresund Łódź", "strasse-oresund-lodz"},
+		{"Война и мир Лев Толстой", "voyna-i-mir-lev-tolstoy"},
+		{"Οδύσσεια Όμηρος", "odysseia-omiros"},
+		{"とっきょきょかきょく", "tokkyokyokakyoku"},
+		{"ラーメン ちゃんぽん", "ramen-chanpon"},
+		{"まっちゃ", "matcha"},
+		{"김치 한국", "gimchi-hanguk"},
+		{"ＡＢＣ１２３", "abc123"},
+		{"こころ 夏目漱石", "kokoro-夏目漱石"},
+		{"源氏物語", "源氏物語"},
+		{"東京ラーメン2024", "東京-ramen2024"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, services.Slugify(tt.input), tt.input)
+	}
+}
+
+func TestSlugifyTruncatesHanWithoutSplittingCharacters(t *testing.T) {
+	slug := services.Slugify(strings.Repeat("漢", 40))
+
+	assert.LessOrEqual(t, len(slug), 80)
+	assert.True(t, utf8.ValidString(slug))
+}
+
+func TestBookSlugBaseKanjiTitle(t *testing.T) {
+	book := models.Book{ID: 1, Title: "吾輩は猫である", Author: "夏目漱石"}
+	book.Slug = services.BookSlugBase(book)
+	assert.Equal(t, "吾輩-ha-猫-dearu-夏目漱石", book.Slug)
+
+	sitemap := services.BookSitemap{Template: "https://www.byfood.com/books/{slug}", Operations: []string{"normalize", "canonical"}}
+	bookURL, err := sitemap.CanonicalURL(book)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/books/%E5%90%BE%E8%BC%A9-ha-%E7%8C%AB-dearu-%E5%A4%8F%E7%9B%AE%E6%BC%B1%E7%9F%B3", bookURL)
+}
+
+func TestSlugifyTruncatesAtWordBoundary(t *testing.T) {
+	slug := services.Slugify(strings.Repeat("ramen ", 30))
+
+	assert.LessOrEqual(t, len(slug), 80)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "ramen"))
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTransferRouter() *gin.Engine {
+	router := gin.Default()
+	router.GET("/books", controllers.GetBooks)
+	router.POST("/books/:id/checkout", controllers.CheckoutBook)
+	router.POST("/transfers", controllers.RequestTransfer)
+	router.GET("/transfers", controllers.GetTransfers)
+	router.POST("/transfers/:id/ship", controllers.ShipTransfer)
+	router.POST("/transfers/:id/receive", controllers.ReceiveTransfer)
+	router.POST("/transfers/:id/cancel", controllers.CancelTransfer)
+	return router
+}
+
+func initializeBranchTestData() (models.Branch, models.Branch) {
+	initializeLoanTestData()
+	config.DB.Exec("ALTER SEQUENCE transfers_id_seq RESTART WITH 1")
+
+	tokyo := models.Branch{Code: "TYO", Name: "Tokyo Office"}
+	osaka := models.Branch{Code: "OSA", Name: "Osaka Office"}
+	config.DB.Create(&tokyo)
+	config.DB.Create(&osaka)
+
+	config.DB.Model(&models.Copy{}).Where("id = ?", 1).Update("branch_id", tokyo.ID)
+	config.DB.Model(&models.Copy{}).Where("id = ?", 2).Update("branch_id", osaka.ID)
+	return tokyo, osaka
+}
+
+func postTransferAction(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestGetBooksAvailableAtBranch(t *testing.T) {
+	_, osaka := initializeBranchTestData()
+	router := setupTransferRouter()
+
+	req, _ := http.NewRequest("GET", "/books?available=true&branch="+strconv.Itoa(int(osaka.ID)), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody struct {
+		Data []models.Book `json:"data"`
+	}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody.Data, 1)
+	assert.Equal(t, "Book Two", responseBody.Data[0].Title)
+}
+
+func TestTransferLifecycle(t *testing.T) {
+	_, osaka := initializeBranchTestData()
+	router := setupTransferRouter()
+
+	requestJSON, _ := json.Marshal(controllers.TransferRequest{CopyID: 1, ToBranchID: osaka.ID})
+	req, _ := http.NewRequest("POST", "/transfers", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	assert.Equal(t, http.StatusConflict, postTransferAction(router, "/transfers/1/receive").Code)
+	assert.Equal(t, http.StatusOK, postTransferAction(router, "/transfers/1/ship").Code)
+	assert.Equal(t, http.StatusConflict, checkout(router, "1", 1).Code)
+
+	requestJSON, _ = json.Marshal(controllers.ReceiveTransferRequest{Location: "Shelf C2"})
+	req, _ = http.NewRequest("POST", "/transfers/1/receive", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var bookCopy models.Copy
+	config.DB.First(&bookCopy, 1)
+	assert.Equal(t, osaka.ID, *bookCopy.BranchID)
+	assert.Equal(t, "Shelf C2", bookCopy.Location)
+	assert.Equal(t, models.CopyStatusAvailable, bookCopy.Status)
+}
+
+func TestTransferToSameBranch(t *testing.T) {
+	tokyo, _ := initializeBranchTestData()
+	router := setupTransferRouter()
+
+	requestJSON, _ := json.Marshal(controllers.TransferRequest{CopyID: 1, ToBranchID: tokyo.ID})
+	req, _ := http.NewRequest("POST", "/transfers", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestShipTransferOfLentCopy(t *testing.T) {
+	_, osaka := initializeBranchTestData()
+	router := setupTransferRouter()
+	checkout(router, "1", 1)
+
+	requestJSON, _ := json.Marshal(controllers.TransferRequest{CopyID: 1, ToBranchID: osaka.ID})
+	req, _ := http.NewRequest("POST", "/transfers", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, http.StatusConflict, postTransferAction(router, "/transfers/1/ship").Code)
+	assert.Equal(t, http.StatusOK, postTransferAction(router, "/transfers/1/cancel").Code)
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessURLBatchKeepsOrder(t *testing.T) {
+	urls := make([]string, 200)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://BYFOOD.com/Item-%d/?utm_source=x", i)
+	}
+	urls[50] = "https://byfood.com/%zz"
+
+	results, err := services.ProcessURLBatch(urls, []string{"all"}, 0, 8)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(urls))
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, urls[i], result.URL)
+		if i == 50 {
+			assert.NotEmpty(t, result.Error)
+			assert.Empty(t, result.ProcessedURL)
+			continue
+		}
+		assert.Empty(t, result.Error)
+		assert.Equal(t, fmt.Sprintf("https://www.byfood.com/item-%d", i), result.ProcessedURL)
+	}
+}
+
+func TestProcessURLBatchLimits(t *testing.T) {
+	_, err := services.ProcessURLBatch(nil, []string{"all"}, 10, 2)
+	assert.True(t, errors.Is(err, services.ErrEmptyURLBatch))
+
+	_, err = services.ProcessURLBatch([]string{"a", "b", "c"}, []string{"all"}, 2, 2)
+	assert.True(t, errors.Is(err, services.ErrURLBatchTooLarge))
+
+	_, err = services.ProcessURLBatch([]string{"a"}, []string{"shorten"}, 2, 2)
+	assert.True(t, errors.Is(err, services.ErrUnknownURLOperation))
+}
+
+func TestReadURLList(t *testing.T) {
+	urls, err := services.ReadURLList(strings.NewReader("https://a.example/\n\n  https://b.example/  \n"), false, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://a.example/", "https://b.example/"}, urls)
+
+	urls, err = services.ReadURLList(strings.NewReader("title,url\nA,https://a.example/\nB,\"https://b.example/?x=1,2\"\nC\n"), true, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://a.example/", "https://b.example/?x=1,2"}, urls)
+
+	urls, err = services.ReadURLList(strings.NewReader("https://a.example/,x\nhttps://b.example/,y\n"), true, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://a.example/", "https://b.example/"}, urls)
+}
+
+// endlessURLs is an upload that never ends.
+type endlessURLs struct{}
+
+func (endlessURLs) Read(p []byte) (int, error) {
+	line := "https://www.byfood.com/\n"
+	n := 0
+	for n+len(line) <= len(p) {
+		n += copy(p[n:], line)
+	}
+	return n, nil
+}
+
+func TestReadURLListStopsAtLimit(t *testing.T) {
+	_, err := services.ReadURLList(endlessURLs{}, false, 10)
+	assert.True(t, errors.Is(err, services.ErrURLBatchTooLarge))
+
+	_, err = services.ReadURLList(io.LimitReader(endlessURLs{}, 1<<20), true, 10)
+	assert.True(t, errors.Is(err, services.ErrURLBatchTooLarge))
+}
+
+func TestNewURLSourceColumn(t *testing.T) {
+	source, err := services.NewURLSource(strings.NewReader("id,Link\n1,https://a.example/\n2,\n3, https://b.example/\n"), true, "link")
+	assert.NoError(t, err)
+
+	var urls []string
+	for {
+		rawURL, err := source()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		urls = append(urls, rawURL)
+	}
+	assert.Equal(t, []string{"https://a.example/", "https://b.example/"}, urls)
+
+	_, err = services.NewURLSource(strings.NewReader("id,href\n1,https://a.example/\n"), true, "link")
+	assert.Error(t, err)
+}
+
+func TestProcessURLStream(t *testing.T) {
+	count := 0
+	source := func() (string, error) {
+		if count == 5000 {
+			return "", io.EOF
+		}
+		count++
+		return fmt.Sprintf("https://BYFOOD.com/Item-%d/", count-1), nil
+	}
+
+	emitted := 0
+	err := services.ProcessURLStream(source, []string{"all"}, 16, func(result services.URLBatchResult) error {
+		assert.Equal(t, emitted, result.Index)
+		assert.Equal(t, fmt.Sprintf("https://www.byfood.com/item-%d", emitted), result.ProcessedURL)
+		emitted++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5000, emitted)
+}
+
+func TestProcessURLStreamStops(t *testing.T) {
+	infinite := func() (string, error) { return "https://www.byfood.com/", nil }
+	stop := errors.New("stop")
+
+	emitted := 0
+	err := services.ProcessURLStream(infinite, []string{"all"}, 4, func(services.URLBatchResult) error {
+		if emitted++; emitted == 10 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 10, emitted)
+
+	broken := errors.New("broken")
+	err = services.ProcessURLStream(func() (string, error) { return "", broken }, []string{"all"}, 4, func(services.URLBatchResult) error { return nil })
+	assert.ErrorIs(t, err, broken)
+}
+
+func TestProcessURLBatchEndpoint(t *testing.T) {
+	router := setupRouter()
+
+	requestBody := map[string]interface{}{
+		"urls":      []string{"https://BYFOOD.com/A/?q=1", "http://[::1"},
+		"operation": "all",
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url/batch", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody services.URLBatchResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody.Data, 2)
+	assert.Equal(t, "https://www.byfood.com/a", responseBody.Data[0].ProcessedURL)
+	assert.NotEmpty(t, responseBody.Data[1].Error)
+}
+
+func TestProcessURLBatchTooLarge(t *testing.T) {
+	t.Setenv("URL_BATCH_MAX_URLS", "2")
+	router := setupRouter()
+
+	requestBody := map[string]interface{}{
+		"urls":      []string{"https://a.example/", "https://b.example/", "https://c.example/"},
+		"operation": "all",
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url/batch", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "at most 2 URLs")
+}
+
+func TestProcessURLBatchUpload(t *testing.T) {
+	router := setupRouter()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "urls.csv")
+	part.Write([]byte("url,note\nhttps://byfood.com/A?utm_source=x&page=2,first\nhttps://byfood.com/B,second\n"))
+	writer.WriteField("operations", "strip_tracking, lowercase_path")
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/process_url/batch", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody services.URLBatchResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Len(t, responseBody.Data, 2)
+	assert.Equal(t, "https://byfood.com/a?page=2", responseBody.Data[0].ProcessedURL)
+	assert.Equal(t, "https://byfood.com/b", responseBody.Data[1].ProcessedURL)
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareURLs(t *testing.T) {
+	urls := []string{
+		"https://byfood.com/food-experiences/",
+		"https://www.byfood.com/blog",
+		"https://www.byfood.com/Food-Experiences?utm_source=x",
+		"://broken",
+		"https://BYFOOD.com/blog/",
+	}
+
+	comparison, err := services.CompareURLs(urls, []string{"all"}, 0, 2)
+	assert.NoError(t, err)
+	assert.False(t, comparison.Equivalent)
+	assert.Len(t, comparison.Results, 5)
+	assert.NotEmpty(t, comparison.Results[3].Error)
+	assert.Equal(t, []services.URLCluster{
+		{ProcessedURL: "https://www.byfood.com/food-experiences", Indexes: []int{0, 2}, URLs: []string{urls[0], urls[2]}},
+		{ProcessedURL: "https://www.byfood.com/blog", Indexes: []int{1, 4}, URLs: []string{urls[1], urls[4]}},
+	}, comparison.Clusters)
+}
+
+func TestCompareURLsEquivalent(t *testing.T) {
+	comparison, err := services.CompareURLs([]string{"HTTPS://www.byfood.com:443/a/./b", "https://www.byfood.com/a/b"}, []string{"normalize"}, 0, 1)
+	assert.NoError(t, err)
+	assert.True(t, comparison.Equivalent)
+	assert.Len(t, comparison.Clusters, 1)
+}
+
+func TestCompareURLsNeedsTwoURLs(t *testing.T) {
+	_, err := services.CompareURLs([]string{"https://www.byfood.com/"}, []string{"all"}, 0, 1)
+	assert.ErrorIs(t, err, services.ErrTooFewURLsToCompare)
+}
+
+func TestCompareURLsEndpoint(t *testing.T) {
+	router := setupRouter()
+
+	requestJSON, _ := json.Marshal(map[string]interface{}{
+		"urls":      []string{"https://byfood.com/food-experiences/", "https://www.byfood.com/food-experiences?utm_source=x"},
+		"operation": "all",
+	})
+	req, _ := http.NewRequest("POST", "/process_url/compare", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var response services.URLCompareResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Data.Equivalent)
+	assert.Equal(t, []int{0, 1}, response.Data.Clusters[0].Indexes)
+}
+
+func TestCompareURLsEndpointSingleURL(t *testing.T) {
+	router := setupRouter()
+
+	requestJSON, _ := json.Marshal(map[string]interface{}{
+		"urls":      []string{"https://www.byfood.com/"},
+		"operation": "all",
+	})
+	req, _ := http.NewRequest("POST", "/process_url/compare", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
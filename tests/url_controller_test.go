@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +16,11 @@ import (
 func setupRouter() *gin.Engine {
 	router := gin.Default()
 	router.POST("/process_url", controllers.ProcessURL)
+	router.POST("/process_url/batch", controllers.ProcessURLBatch)
+	router.POST("/process_url/compare", controllers.CompareURLs)
+	router.POST("/process_url/canonical-check", controllers.CheckCanonicalTags)
+	router.GET("/process_url/stats", controllers.GetURLStats)
+	router.GET("/process_url/operations", controllers.GetURLOperations)
 	return router
 }
 
@@ -77,3 +83,103 @@ func TestProcessURLCanonical(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "https://BYFOOD.com/food-EXPeriences", responseBody["processed_url"])
 }
+
+func TestProcessURLClean(t *testing.T) {
+	router := setupRouter()
+
+	requestBody := map[string]string{
+		"url":       "https://www.byfood.com/food-experiences?utm_source=newsletter&page=2&fbclid=xyz&lang=ja",
+		"operation": "clean",
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody map[string]string
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/food-experiences?lang=ja&page=2", responseBody["processed_url"])
+}
+
+func TestProcessURLUnknownOperation(t *testing.T) {
+	router := setupRouter()
+
+	requestBody := map[string]string{
+		"url":       "https://www.byfood.com/food-experiences",
+		"operation": "shorten",
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var responseBody map[string]string
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(responseBody["error"], "Unknown operation"))
+	assert.Contains(t, responseBody["error"], "canonical")
+	assert.Contains(t, responseBody["error"], "strip_tracking")
+}
+
+func TestProcessURLWithoutOperation(t *testing.T) {
+	router := setupRouter()
+
+	requestJSON, _ := json.Marshal(map[string]string{"url": "https://www.byfood.com/"})
+	req, _ := http.NewRequest("POST", "/process_url", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestProcessURLChainedOperations(t *testing.T) {
+	router := setupRouter()
+
+	requestBody := map[string]interface{}{
+		"url":        "https://blog.example.com/Food-Experiences?utm_source=x&page=2",
+		"operations": []string{"strip_tracking", "lowercase_path", "force_host"},
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody map[string]string
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/food-experiences?page=2", responseBody["processed_url"])
+}
+
+func TestGetURLOperations(t *testing.T) {
+	router := setupRouter()
+
+	req, _ := http.NewRequest("GET", "/process_url/operations", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var responseBody struct {
+		Data []struct {
+			Name string `json:"name"`
+			Kind string `json:"kind"`
+		} `json:"data"`
+	}
+	err := json.Unmarshal(resp.Body.Bytes(), &responseBody)
+	assert.NoError(t, err)
+
+	kinds := map[string]string{}
+	for _, operation := range responseBody.Data {
+		kinds[operation.Name] = operation.Kind
+	}
+	assert.Equal(t, "rule_set", kinds["canonical"])
+	assert.Equal(t, "builtin", kinds["force_host"])
+	assert.Equal(t, "builtin", kinds["strip_tracking"])
+}
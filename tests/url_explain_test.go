@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainURL(t *testing.T) {
+	explanation, err := services.ExplainURL("https://BYFOOD.com/Food-Experiences/?utm_source=x#top", "all", "strip_fragment")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://www.byfood.com/food-experiences", explanation.ProcessedURL)
+	assert.False(t, explanation.AlreadyCanonical)
+	assert.Equal(t, []string{"host", "path", "query", "fragment"}, explanation.ChangedParts)
+
+	rules := []string{}
+	for _, step := range explanation.Steps {
+		rules = append(rules, step.Operation+"/"+step.Rule)
+	}
+	assert.Equal(t, []string{"all/host_mappings", "all/path_case", "all/trailing_slash", "all/query", "strip_fragment/strip_fragment"}, rules)
+
+	assert.Equal(t, "https://BYFOOD.com/Food-Experiences/?utm_source=x#top", explanation.Steps[0].Before)
+	assert.Equal(t, "https://www.byfood.com/Food-Experiences/?utm_source=x#top", explanation.Steps[0].After)
+	for i := 1; i < len(explanation.Steps); i++ {
+		assert.Equal(t, explanation.Steps[i-1].After, explanation.Steps[i].Before)
+	}
+}
+
+func TestExplainURLAlreadyCanonical(t *testing.T) {
+	explanation, err := services.ExplainURL("https://www.byfood.com/food-experiences", "all")
+	assert.NoError(t, err)
+
+	assert.True(t, explanation.AlreadyCanonical)
+	assert.Empty(t, explanation.ChangedParts)
+	assert.Empty(t, explanation.Steps)
+}
+
+func TestProcessURLExplain(t *testing.T) {
+	router := setupRouter()
+
+	requestBody := map[string]string{
+		"url":       "https://www.byfood.com/food-experiences?page=2&utm_source=x",
+		"operation": "clean",
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url?explain=true", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var explanation services.URLExplanation
+	err := json.Unmarshal(resp.Body.Bytes(), &explanation)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/food-experiences?page=2", explanation.ProcessedURL)
+	assert.Equal(t, []string{"query"}, explanation.ChangedParts)
+	assert.Len(t, explanation.Steps, 1)
+	assert.Equal(t, "query", explanation.Steps[0].Rule)
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func localeRuleSet() services.RuleSet {
+	return services.RuleSet{Locale: &services.LocaleRules{
+		Locales:        []string{"en", "ja", "zh-tw"},
+		Default:        "en",
+		QueryParameter: "lang",
+	}}
+}
+
+func TestLocalePrefix(t *testing.T) {
+	ruleSet := localeRuleSet()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"canonical prefix kept", "https://www.byfood.com/ja/tokyo", "https://www.byfood.com/ja/tokyo"},
+		{"uppercase prefix", "https://www.byfood.com/JA/tokyo", "https://www.byfood.com/ja/tokyo"},
+		{"region prefix", "https://www.byfood.com/ZH_TW/tokyo", "https://www.byfood.com/zh-tw/tokyo"},
+		{"locale root", "https://www.byfood.com/ja", "https://www.byfood.com/ja/"},
+		{"default prefix removed", "https://www.byfood.com/en/tokyo", "https://www.byfood.com/tokyo"},
+		{"query parameter moved to prefix", "https://www.byfood.com/tokyo?lang=ja&page=2", "https://www.byfood.com/ja/tokyo?page=2"},
+		{"query parameter case", "https://www.byfood.com/tokyo?lang=zh-TW", "https://www.byfood.com/zh-tw/tokyo"},
+		{"prefix wins over parameter", "https://www.byfood.com/ja/tokyo?lang=zh-tw", "https://www.byfood.com/ja/tokyo"},
+		{"escaped slash kept", "https://www.byfood.com/JA/a%2Fb", "https://www.byfood.com/ja/a%2Fb"},
+		{"escaped slash kept with parameter", "https://www.byfood.com/a%2Fb?lang=ja", "https://www.byfood.com/ja/a%2Fb"},
+		{"unknown parameter kept", "https://www.byfood.com/tokyo?lang=fr", "https://www.byfood.com/tokyo?lang=fr"},
+		{"unknown prefix kept", "https://www.byfood.com/fr/tokyo", "https://www.byfood.com/fr/tokyo"},
+		{"similar segment kept", "https://www.byfood.com/japan/tokyo", "https://www.byfood.com/japan/tokyo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.NoError(t, ruleSet.Apply(u))
+			assert.Equal(t, tt.expected, u.String())
+		})
+	}
+}
+
+func TestLocalePrefixDefault(t *testing.T) {
+	ruleSet := localeRuleSet()
+	ruleSet.Locale.PrefixDefault = true
+
+	u, _ := url.Parse("https://www.byfood.com/tokyo")
+	assert.NoError(t, ruleSet.Apply(u))
+	assert.Equal(t, "https://www.byfood.com/en/tokyo", u.String())
+}
+
+func TestLocaleConfiguredWithUnderscore(t *testing.T) {
+	ruleSet := services.RuleSet{Locale: &services.LocaleRules{Locales: []string{"EN", "zh_TW"}, Default: "zh_TW"}}
+
+	for input, expected := range map[string]string{
+		"https://www.byfood.com/zh-tw/tokyo": "https://www.byfood.com/tokyo",
+		"https://www.byfood.com/ZH_TW/tokyo": "https://www.byfood.com/tokyo",
+		"https://www.byfood.com/En/tokyo":    "https://www.byfood.com/en/tokyo",
+	} {
+		u, _ := url.Parse(input)
+		assert.NoError(t, ruleSet.Apply(u))
+		assert.Equal(t, expected, u.String(), input)
+	}
+
+	u, _ := url.Parse("https://www.byfood.com/en/tokyo")
+	assert.Equal(t, []services.HreflangAlternate{
+		{Hreflang: "en", URL: "https://www.byfood.com/en/tokyo"},
+		{Hreflang: "zh-TW", URL: "https://www.byfood.com/tokyo"},
+		{Hreflang: "x-default", URL: "https://www.byfood.com/tokyo"},
+	}, ruleSet.Locale.Alternates(u))
+}
+
+func TestLocaleAlternates(t *testing.T) {
+	rules := *localeRuleSet().Locale
+
+	u, _ := url.Parse("https://www.byfood.com/ja/tokyo?page=2")
+	assert.Equal(t, []services.HreflangAlternate{
+		{Hreflang: "en", URL: "https://www.byfood.com/tokyo?page=2"},
+		{Hreflang: "ja", URL: "https://www.byfood.com/ja/tokyo?page=2"},
+		{Hreflang: "zh-TW", URL: "https://www.byfood.com/zh-tw/tokyo?page=2"},
+		{Hreflang: "x-default", URL: "https://www.byfood.com/tokyo?page=2"},
+	}, rules.Alternates(u))
+
+	u, _ = url.Parse("https://www.byfood.com/ja/a%2Fb")
+	assert.Equal(t, "https://www.byfood.com/zh-tw/a%2Fb", rules.Alternates(u)[2].URL)
+}
+
+func TestLocaleRulesRejectUnknownDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"rule_sets": {"broken": {"locale": {"locales": ["ja"], "default": "en"}}}}`), 0o644)
+
+	_, err := services.LoadRuleSets(path)
+	assert.Error(t, err)
+}
+
+func TestProcessURLHreflang(t *testing.T) {
+	router := setupRouter()
+
+	requestBody := map[string]string{
+		"url":       "https://www.byfood.com/food-experiences?lang=ZH_TW",
+		"operation": "locale",
+	}
+	requestJSON, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/process_url?hreflang=true", bytes.NewBuffer(requestJSON))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var response services.SuccessProcessURL
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.byfood.com/zh-tw/food-experiences", response.ProcessedUrl)
+	assert.Contains(t, response.Alternates, services.HreflangAlternate{Hreflang: "ja", URL: "https://www.byfood.com/ja/food-experiences"})
+	assert.Contains(t, response.Alternates, services.HreflangAlternate{Hreflang: "zh-TW", URL: "https://www.byfood.com/zh-tw/food-experiences"})
+}
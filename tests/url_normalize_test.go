@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeConformance(t *testing.T) {
+	ruleSet := services.RuleSet{Normalize: true}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"lowercase scheme and host", "HTTPS://WWW.ByFood.COM/Tokyo", "https://www.byfood.com/Tokyo"},
+		{"path case is preserved", "https://www.byfood.com/Food-Experiences/Tokyo", "https://www.byfood.com/Food-Experiences/Tokyo"},
+		{"default http port", "http://www.byfood.com:80/a", "http://www.byfood.com/a"},
+		{"default https port", "https://www.byfood.com:443/a", "https://www.byfood.com/a"},
+		{"non-default port kept", "https://www.byfood.com:8443/a", "https://www.byfood.com:8443/a"},
+		{"http port on https kept", "https://www.byfood.com:80/a", "https://www.byfood.com:80/a"},
+		{"empty path becomes slash", "https://www.byfood.com", "https://www.byfood.com/"},
+		{"percent-encoding uppercased", "https://www.byfood.com/a%2fb%c3%a9", "https://www.byfood.com/a%2Fb%C3%A9"},
+		{"unreserved characters decoded", "https://www.byfood.com/%7Euser/%41%62c%2D%5F%2E", "https://www.byfood.com/~user/Abc-_."},
+		{"reserved characters stay encoded", "https://www.byfood.com/a%2Fb%3Fc", "https://www.byfood.com/a%2Fb%3Fc"},
+		{"query encoding", "https://www.byfood.com/?q=%7e%2f%61", "https://www.byfood.com/?q=~%2Fa"},
+		{"single dot segments", "https://www.byfood.com/a/./b/.", "https://www.byfood.com/a/b/"},
+		{"double dot segments", "https://www.byfood.com/a/b/c/./../../g", "https://www.byfood.com/a/g"},
+		{"double dot above root", "https://www.byfood.com/../../a", "https://www.byfood.com/a"},
+		{"trailing double dot", "https://www.byfood.com/a/b/..", "https://www.byfood.com/a/"},
+		{"encoded dot segments", "https://www.byfood.com/a/%2E%2E/b", "https://www.byfood.com/b"},
+		{"dots inside segments kept", "https://www.byfood.com/a/..b/c.", "https://www.byfood.com/a/..b/c."},
+		{"idn host to punycode", "https://Bücher.example/katalog", "https://xn--bcher-kva.example/katalog"},
+		{"japanese idn host", "https://例え.テスト/", "https://xn--r8jz45g.xn--zckzah/"},
+		{"ipv4 host", "http://127.0.0.1:80/", "http://127.0.0.1/"},
+		{"ipv6 host", "http://[::1]:80/a", "http://[::1]/a"},
+		{"ipv6 host with port", "http://[2001:DB8::1]:8080/", "http://[2001:db8::1]:8080/"},
+		{"fragment kept", "https://www.byfood.com/a#Top%7e", "https://www.byfood.com/a#Top~"},
+		{"userinfo kept", "https://user@WWW.byfood.com/", "https://user@www.byfood.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.NoError(t, ruleSet.Apply(u))
+			assert.Equal(t, tt.expected, u.String())
+		})
+	}
+}
+
+func TestNormalizeIsIdempotent(t *testing.T) {
+	ruleSet := services.RuleSet{Normalize: true}
+	inputs := []string{
+		"HTTP://Bücher.example:80/a/./b/../%7ec%2f?x=%61#f",
+		"https://www.byfood.com/food-experiences?page=2",
+	}
+
+	for _, input := range inputs {
+		u, _ := url.Parse(input)
+		assert.NoError(t, ruleSet.Apply(u))
+		once := u.String()
+
+		u, _ = url.Parse(once)
+		assert.NoError(t, ruleSet.Apply(u))
+		assert.Equal(t, once, u.String(), input)
+	}
+}
+
+func TestNormalizeStripsFragment(t *testing.T) {
+	ruleSet := services.RuleSet{Normalize: true, Fragment: services.FragmentStrip}
+
+	u, _ := url.Parse("https://WWW.byfood.com/a#section")
+	assert.NoError(t, ruleSet.Apply(u))
+	assert.Equal(t, "https://www.byfood.com/a", u.String())
+}
+
+func TestNormalizeRejectsInvalidHost(t *testing.T) {
+	ruleSet := services.RuleSet{Normalize: true}
+
+	u, _ := url.Parse("https://a‍b.example/")
+	assert.Error(t, ruleSet.Apply(u))
+}
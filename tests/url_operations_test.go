@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"byfood-test-backend/services"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLOperationRegistryResolve(t *testing.T) {
+	registry := services.NewURLOperationRegistry(services.NewURLRuleEngine(""), services.BuiltinURLOperations()...)
+
+	operations, err := registry.Resolve([]string{"canonical", "lowercase_host"})
+	assert.NoError(t, err)
+	assert.Len(t, operations, 2)
+	assert.Equal(t, "canonical", operations[0].Name())
+
+	_, err = registry.Resolve([]string{"lowercase_host", "shorten"})
+	assert.True(t, errors.Is(err, services.ErrUnknownURLOperation))
+}
+
+func TestURLOperationRegistryRuleSetOverridesBuiltin(t *testing.T) {
+	registry := services.NewURLOperationRegistry(services.NewURLRuleEngine(""), services.BuiltinURLOperations()...)
+
+	operation, ok := registry.Lookup("normalize")
+	assert.True(t, ok)
+
+	for _, info := range registry.Operations() {
+		if info.Name == "normalize" {
+			assert.Equal(t, services.URLOperationKindRuleSet, info.Kind)
+		}
+	}
+	assert.Equal(t, "normalize", operation.Name())
+}
+
+func TestProcessURLChain(t *testing.T) {
+	tests := []struct {
+		input      string
+		operations []string
+		expected   string
+	}{
+		{"https://BYFOOD.com/A/?utm_source=x&b=2&a=1", []string{"strip_tracking", "sort_query"}, "https://BYFOOD.com/A/?a=1&b=2"},
+		{"https://BYFOOD.com/A/?q=1#top", []string{"lowercase_host", "drop_query", "strip_fragment", "strip_trailing_slash"}, "https://byfood.com/A"},
+		{"https://byfood.com/a", []string{"add_trailing_slash", "force_host"}, "https://www.byfood.com/a/"},
+		{"https://byfood.com/A/", []string{"lowercase_path", "canonical"}, "https://byfood.com/a"},
+	}
+
+	for _, tt := range tests {
+		processed, err := services.ProcessURL(tt.input, tt.operations...)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, processed, tt.input)
+	}
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"byfood-test-backend/controllers"
+	"byfood-test-backend/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleSetApply(t *testing.T) {
+	ruleSet := services.RuleSet{
+		HostMappings:   map[string]string{"byfood.com": "www.byfood.com"},
+		PrefixRewrites: []services.PrefixRewrite{{From: "/experiences", To: "/food-experiences"}},
+		PathCase:       services.PathCaseLower,
+		TrailingSlash:  services.TrailingSlashStrip,
+		Query:          services.QueryRules{Mode: services.QueryModeAllow, Allow: []string{"page"}},
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://BYFOOD.com/Experiences/Tokyo/?page=2&ref=x", "https://www.byfood.com/food-experiences/tokyo?page=2"},
+		{"https://byfood.com/experiencesX", "https://www.byfood.com/experiencesx"},
+		{"https://blog.byfood.com/a/", "https://blog.byfood.com/a"},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.input)
+		assert.NoError(t, err)
+		ruleSet.Apply(u)
+		assert.Equal(t, tt.expected, u.String(), tt.input)
+	}
+}
+
+func TestLoadRuleSetsFromYAML(t *testing.T) {
+	ruleSets, err := services.LoadRuleSets("../config/url_rules.yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, ruleSets, "canonical")
+	assert.Contains(t, ruleSets, "seo")
+	assert.Equal(t, "seo", ruleSets["seo"].Name)
+}
+
+func TestDefaultRuleSetsMatchRulesFile(t *testing.T) {
+	ruleSets, err := services.LoadRuleSets("../config/url_rules.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, ruleSets, services.DefaultRuleSets())
+}
+
+func TestLoadRuleSetsRejectsUnknownValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"rule_sets": {"broken": {"trailing_slash": "sometimes"}}}`), 0o644)
+
+	_, err := services.LoadRuleSets(path)
+	assert.Error(t, err)
+}
+
+func TestLoadRuleSetsRejectsUnknownKeys(t *testing.T) {
+	for name, content := range map[string]string{
+		"rules.yaml": "rule_sets:\n  typo:\n    trailing_slsh: strip\n",
+		"rules.yml":  "rule_sets:\n  typo:\n    query:\n      mdoe: drop\n",
+		"rules.json": `{"rule_sets": {"typo": {"host_mapping": {"*": "www.byfood.com"}}}}`,
+	} {
+		path := filepath.Join(t.TempDir(), name)
+		os.WriteFile(path, []byte(content), 0o644)
+
+		_, err := services.LoadRuleSets(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoadRuleSetsLowercasesHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	os.WriteFile(path, []byte("rule_sets:\n  hosts:\n    host_mappings:\n      M.ByFood.com: www.byfood.com\n    host_query:\n      WWW.byfood.com:\n        mode: drop\n"), 0o644)
+
+	ruleSets, err := services.LoadRuleSets(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"m.byfood.com": "www.byfood.com"}, ruleSets["hosts"].HostMappings)
+	assert.Contains(t, ruleSets["hosts"].HostQuery, "www.byfood.com")
+
+	u, _ := url.Parse("https://M.BYFOOD.COM/food-experiences?page=2")
+	assert.NoError(t, ruleSets["hosts"].Apply(u))
+	assert.Equal(t, "https://www.byfood.com/food-experiences", u.String())
+}
+
+func TestURLRuleEngineReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"rule_sets": {"upper_host": {"host_mappings": {"*": "example.com"}}}}`), 0o644)
+
+	engine := services.NewURLRuleEngine(path)
+	_, ok := engine.RuleSet("upper_host")
+	assert.False(t, ok)
+
+	assert.NoError(t, engine.Reload())
+	_, ok = engine.RuleSet("upper_host")
+	assert.True(t, ok)
+	_, ok = engine.RuleSet("canonical")
+	assert.False(t, ok)
+
+	os.WriteFile(path, []byte(`not json`), 0o644)
+	assert.Error(t, engine.Reload())
+	_, ok = engine.RuleSet("upper_host")
+	assert.True(t, ok)
+}
+
+func TestReloadURLRulesRequiresToken(t *testing.T) {
+	router := gin.Default()
+	router.POST("/process_url/rules/reload", controllers.ReloadURLRules)
+	reload := func(authorization string) int {
+		req, _ := http.NewRequest("POST", "/process_url/rules/reload", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	os.Unsetenv("URL_RULES_RELOAD_TOKEN")
+	assert.Equal(t, http.StatusForbidden, reload("Bearer secret"))
+
+	os.Setenv("URL_RULES_RELOAD_TOKEN", "secret")
+	defer os.Unsetenv("URL_RULES_RELOAD_TOKEN")
+	assert.Equal(t, http.StatusUnauthorized, reload(""))
+	assert.Equal(t, http.StatusUnauthorized, reload("Bearer wrong"))
+	assert.Equal(t, http.StatusOK, reload("Bearer secret"))
+}
+
+func TestQueryRulesClean(t *testing.T) {
+	rules := services.QueryRules{Mode: services.QueryModeClean, Deny: []string{"ref"}, DropEmpty: true, Sort: true}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"utm_source=news&page=2&gclid=abc&lang=ja", "lang=ja&page=2"},
+		{"fbclid=1&UTM_Medium=email", ""},
+		{"q=tokyo%20ramen&empty=&ref=home&page=1", "page=1&q=tokyo%20ramen"},
+		{"tag=b&tag=a", "tag=a&tag=b"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, rules.Apply(tt.input), tt.input)
+	}
+}
+
+func TestQueryRulesCleanKeepsAllowedTrackingParameter(t *testing.T) {
+	rules := services.QueryRules{Mode: services.QueryModeClean, Allow: []string{"utm_campaign"}}
+
+	assert.Equal(t, "b=2&utm_campaign=spring&a=1", rules.Apply("b=2&utm_source=x&utm_campaign=spring&a=1"))
+}
+
+func TestQueryRulesPerHost(t *testing.T) {
+	ruleSet := services.DefaultRuleSets()["clean"]
+	ruleSet.HostQuery = map[string]services.QueryRules{
+		"blog.byfood.com": {Mode: services.QueryModeAllow, Allow: []string{"p"}},
+	}
+
+	u, _ := url.Parse("https://blog.byfood.com/post?p=12&page=2&utm_source=x")
+	ruleSet.Apply(u)
+	assert.Equal(t, "https://blog.byfood.com/post?p=12", u.String())
+
+	u, _ = url.Parse("https://www.byfood.com/list?utm_source=x&page=2&lang=")
+	ruleSet.Apply(u)
+	assert.Equal(t, "https://www.byfood.com/list?page=2", u.String())
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"byfood-test-backend/config"
+	"byfood-test-backend/models"
+	"byfood-test-backend/services"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func initializeURLStatsTestData() {
+	config.DB.Exec("DELETE FROM url_records")
+}
+
+func TestNewURLRecord(t *testing.T) {
+	explanation, err := services.ExplainURL("https://BYFOOD.com/Food-Experiences/?utm_source=x#top", "all", "strip_fragment")
+	assert.NoError(t, err)
+
+	record := services.NewURLRecord("https://BYFOOD.com/Food-Experiences/?utm_source=x#top", []string{"all", "strip_fragment"}, explanation)
+	assert.Equal(t, "https://www.byfood.com/food-experiences", record.OutputURL)
+	assert.Equal(t, "all,strip_fragment", record.Operation)
+	assert.Equal(t, "www.byfood.com", record.Host)
+	assert.True(t, record.Changed)
+	assert.Equal(t, "all.host_mappings,all.path_case,all.trailing_slash,all.query,strip_fragment", record.Transformation)
+
+	explanation, _ = services.ExplainURL("https://www.byfood.com/food-experiences", "all")
+	record = services.NewURLRecord("https://www.byfood.com/food-experiences", []string{"all"}, explanation)
+	assert.False(t, record.Changed)
+	assert.Empty(t, record.Transformation)
+}
+
+func TestGetURLStats(t *testing.T) {
+	initializeURLStatsTestData()
+	router := setupRouter()
+	services.URLRecords = services.StartURLRecorder(config.DB)
+	defer func() { services.URLRecords = nil }()
+
+	for _, input := range []string{
+		"https://BYFOOD.com/food-experiences?utm_source=x",
+		"https://byfood.com/food-experiences/",
+		"https://www.byfood.com/food-experiences",
+		"https://blog.byfood.com/post",
+	} {
+		requestJSON, _ := json.Marshal(map[string]string{"url": input, "operation": "canonical"})
+		req, _ := http.NewRequest("POST", "/process_url", bytes.NewBuffer(requestJSON))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	}
+	services.URLRecords.Stop()
+
+	req, _ := http.NewRequest("GET", "/process_url/stats?bucket=hour&limit=1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var stats services.URLStats
+	err := json.Unmarshal(resp.Body.Bytes(), &stats)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), stats.Total)
+	assert.Equal(t, int64(2), stats.Changed)
+	assert.Equal(t, 50.0, stats.AlreadyCanonicalPercent)
+	assert.Equal(t, []services.URLStatsCount{{Value: "byfood.com", Count: 2}}, stats.TopHosts)
+	assert.Equal(t, []services.URLStatsCount{{Value: "canonical", Count: 4}}, stats.TopOperations)
+	assert.Equal(t, []services.URLStatsCount{{Value: "canonical.query", Count: 1}}, stats.TopTransformations)
+	assert.Len(t, stats.Buckets, 1)
+	assert.Equal(t, int64(4), stats.Buckets[0].Count)
+
+	for _, query := range []string{"?bucket=month", "?from=yesterday", "?from=2024-05-02&to=2024-05-01", "?limit=0"} {
+		req, _ = http.NewRequest("GET", "/process_url/stats"+query, nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+}
+
+func TestURLRecorderDropsRecordsAfterStop(t *testing.T) {
+	// Nothing is queued, so the recorder never touches the database.
+	recorder := services.StartURLRecorder(nil)
+	recorder.Stop()
+
+	assert.NotPanics(t, func() {
+		recorder.Record(models.URLRecord{InputURL: "https://www.byfood.com/late"})
+		recorder.Stop()
+	})
+}
+
+func TestPruneURLRecords(t *testing.T) {
+	initializeURLStatsTestData()
+
+	now := time.Now()
+	config.DB.Create(&models.URLRecord{CreatedAt: now.AddDate(0, 0, -40), InputURL: "https://www.byfood.com/old"})
+	config.DB.Create(&models.URLRecord{CreatedAt: now, InputURL: "https://www.byfood.com/new"})
+
+	pruned, err := services.PruneURLRecords(config.DB, now.AddDate(0, 0, -30))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	var remaining []models.URLRecord
+	config.DB.Find(&remaining)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, "https://www.byfood.com/new", remaining[0].InputURL)
+}